@@ -1,19 +1,50 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/routes"
 	"github.com/vithsutra/ca_project_http_server/pkg/aws_s3"
 	"github.com/vithsutra/ca_project_http_server/pkg/database"
 	"github.com/vithsutra/ca_project_http_server/pkg/rabbitmq"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
 	"github.com/vithsutra/ca_project_http_server/repository"
 )
 
-func Start(dbConnPool *connection, awsS3Connection *s3Connection, rabbitmqConn *rabbitmqConnection) {
+func Start(dbConnPool *connection, awsS3Connection *s3Connection, rabbitmqConn *rabbitmqConnection, organizationLocation *time.Location) {
 	e := echo.New()
 
+	//the client ip of the login throttling is the peer address, forwarded headers are only read
+	//when they come from one of the trusted proxies
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	if trustedProxies := os.Getenv("TRUSTED_PROXY_CIDRS"); trustedProxies != "" {
+		trustOptions := []echo.TrustOption{
+			echo.TrustLoopback(false),
+			echo.TrustLinkLocal(false),
+			echo.TrustPrivateNet(false),
+		}
+
+		for _, trustedProxy := range strings.Split(trustedProxies, ",") {
+			_, ipRange, err := net.ParseCIDR(strings.TrimSpace(trustedProxy))
+
+			if err != nil {
+				log.Fatalln("please set TRUSTED_PROXY_CIDRS to a comma separated list of CIDR ranges")
+			}
+
+			trustOptions = append(trustOptions, echo.TrustIPRange(ipRange))
+		}
+
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(trustOptions...)
+	}
+
 	postgresRepo := database.NewPostgresRepo(dbConnPool.pool)
 
 	awsS3Repo := aws_s3.NewAwsS3Repo(awsS3Connection.s3Client)
@@ -22,25 +53,235 @@ func Start(dbConnPool *connection, awsS3Connection *s3Connection, rabbitmqConn *
 
 	rootRepo := repository.NewRootRepo(postgresRepo)
 
-	adminRepo := repository.NewAdminRepo(postgresRepo, awsS3Repo, rabbitmqRepo)
+	accessTokenTtlMinutes := 15
+
+	if accessTokenTtl := os.Getenv("ACCESS_TOKEN_TTL_MINUTES"); accessTokenTtl != "" {
+		ttl, err := strconv.Atoi(accessTokenTtl)
+
+		if err != nil || ttl <= 0 {
+			log.Fatalln("please set a positive ACCESS_TOKEN_TTL_MINUTES env variable")
+		}
+
+		accessTokenTtlMinutes = ttl
+	}
+
+	refreshTokenTtlDays := 30
+
+	if refreshTokenTtl := os.Getenv("REFRESH_TOKEN_TTL_DAYS"); refreshTokenTtl != "" {
+		ttl, err := strconv.Atoi(refreshTokenTtl)
+
+		if err != nil || ttl <= 0 {
+			log.Fatalln("please set a positive REFRESH_TOKEN_TTL_DAYS env variable")
+		}
+
+		refreshTokenTtlDays = ttl
+	}
+
+	authTokenRepo := repository.NewAuthTokenRepo(postgresRepo, time.Duration(accessTokenTtlMinutes)*time.Minute, time.Duration(refreshTokenTtlDays)*24*time.Hour)
+
+	loginThrottleRepo := repository.NewLoginThrottleRepo(postgresRepo, postgresRepo, rabbitmqRepo)
+
+	adminRepo := repository.NewAdminRepo(postgresRepo, awsS3Repo, rabbitmqRepo, authTokenRepo, loginThrottleRepo)
 
 	employeeCategoryRepo := repository.NewEmployeeCategoryRepo(postgresRepo)
 
-	userRepo := repository.NewUserRepo(postgresRepo, awsS3Repo, rabbitmqRepo)
+	punchClock := &repository.PunchClock{
+		Location:     organizationLocation,
+		MaxClockSkew: 5 * time.Minute,
+	}
+
+	if maxClockSkew := os.Getenv("PUNCH_MAX_CLOCK_SKEW_MINUTES"); maxClockSkew != "" {
+		maxClockSkewMinutes, err := strconv.Atoi(maxClockSkew)
+
+		if err != nil || maxClockSkewMinutes <= 0 {
+			log.Fatalln("please set a positive PUNCH_MAX_CLOCK_SKEW_MINUTES env variable")
+		}
+
+		punchClock.MaxClockSkew = time.Duration(maxClockSkewMinutes) * time.Minute
+	}
+
+	switch os.Getenv("PUNCH_CLOCK_SKEW_POLICY") {
+	case "", "flag":
+	case "reject":
+		punchClock.RejectClockSkew = true
+	default:
+		log.Fatalln("please set PUNCH_CLOCK_SKEW_POLICY to flag or reject")
+	}
+
+	attendanceLedgerRepo := repository.NewAttendanceLedgerRepo(postgresRepo, organizationLocation)
+
+	reportBrandingRepo := repository.NewReportBrandingRepo(postgresRepo, awsS3Repo)
+
+	//REPORT_SIGNING_KEY is a base64 encoded 32 byte ed25519 seed, e.g. from openssl rand -base64 32,
+	//and REPORT_VERIFY_BASE_URL the public url of this server the verify qr code points to
+	var reportSigningKey ed25519.PrivateKey
+
+	reportVerifyBaseUrl := os.Getenv("REPORT_VERIFY_BASE_URL")
+
+	if encodedSeed := os.Getenv("REPORT_SIGNING_KEY"); encodedSeed != "" {
+		signingKey, err := utils.ParseReportSigningKey(encodedSeed)
+
+		if err != nil {
+			log.Fatalln("please set the REPORT_SIGNING_KEY env variable to a base64 encoded 32 byte seed, Error: ", err.Error())
+		}
+
+		reportSigningKey = signingKey
+
+		if reportVerifyBaseUrl == "" {
+			log.Fatalln("please set the REPORT_VERIFY_BASE_URL env variable together with REPORT_SIGNING_KEY")
+		}
+	} else {
+		log.Println("warning: REPORT_SIGNING_KEY is not set, reports are generated without signature and verify qr code")
+	}
+
+	issuedReportRepo := repository.NewIssuedReportRepo(postgresRepo, reportSigningKey, reportVerifyBaseUrl)
+
+	authorizationRepo := repository.NewAuthorizationRepo(postgresRepo)
+
+	presenceRepo := repository.NewPresenceRepo(postgresRepo, authorizationRepo)
+
+	webhookRepo := repository.NewWebhookRepo(postgresRepo)
+
+	roleRepo := repository.NewRoleRepo(postgresRepo)
+
+	userRepo := repository.NewUserRepo(postgresRepo, awsS3Repo, rabbitmqRepo, punchClock, attendanceLedgerRepo, reportBrandingRepo, issuedReportRepo, presenceRepo, webhookRepo, authTokenRepo, loginThrottleRepo)
+
+	workSiteRepo := repository.NewWorkSiteRepo(postgresRepo)
+
+	workSessionRepo := repository.NewWorkSessionRepo(postgresRepo, rabbitmqRepo, organizationLocation)
+
+	attendanceCorrectionRepo := repository.NewAttendanceCorrectionRepo(postgresRepo, punchClock)
 
-	InitHttpRoutes(
+	leaveTypeRepo := repository.NewLeaveTypeRepo(postgresRepo, organizationLocation)
+
+	holidayRepo := repository.NewHolidayRepo(postgresRepo)
+
+	musterRollRepo := repository.NewMusterRollRepo(postgresRepo, attendanceLedgerRepo, organizationLocation)
+
+	analyticsRepo := repository.NewAnalyticsRepo(postgresRepo)
+
+	reportJobRepo := repository.NewReportJobRepo(postgresRepo, awsS3Repo, userRepo)
+
+	leaveApprovalRepo := repository.NewLeaveApprovalRepo(postgresRepo, attendanceLedgerRepo, presenceRepo, webhookRepo)
+
+	routes.InitHttpRoutes(
 		e,
 		rootRepo,
 		adminRepo,
 		employeeCategoryRepo,
 		userRepo,
+		workSiteRepo,
+		workSessionRepo,
+		attendanceCorrectionRepo,
+		leaveTypeRepo,
+		holidayRepo,
+		attendanceLedgerRepo,
+		musterRollRepo,
+		reportJobRepo,
+		reportBrandingRepo,
+		issuedReportRepo,
+		analyticsRepo,
+		presenceRepo,
+		webhookRepo,
+		authorizationRepo,
+		authTokenRepo,
+		roleRepo,
+		leaveApprovalRepo,
 	)
 
-	if err := postgresRepo.Init(); err != nil {
-		log.Fatalln("error occurred with database while initializing the database, Error: ", err.Error())
+	appliedMigrations, err := postgresRepo.MigrateUp()
+
+	if err != nil {
+		log.Fatalln("error occurred with database while applying the migrations, Error: ", err.Error())
+	}
+
+	log.Printf("database migrated successfully, %d new migrations applied\n", len(appliedMigrations))
+
+	sweepIntervalMinutes := 15
+
+	if sweepInterval := os.Getenv("WORK_SESSION_SWEEP_INTERVAL_MINUTES"); sweepInterval != "" {
+		sweepIntervalMinutes, err = strconv.Atoi(sweepInterval)
+
+		if err != nil || sweepIntervalMinutes <= 0 {
+			log.Fatalln("please set a positive WORK_SESSION_SWEEP_INTERVAL_MINUTES env variable")
+		}
+	}
+
+	go workSessionRepo.StartSweeper(time.Duration(sweepIntervalMinutes) * time.Minute)
+
+	accrualIntervalMinutes := 60
+
+	if accrualInterval := os.Getenv("LEAVE_ACCRUAL_INTERVAL_MINUTES"); accrualInterval != "" {
+		accrualIntervalMinutes, err = strconv.Atoi(accrualInterval)
+
+		if err != nil || accrualIntervalMinutes <= 0 {
+			log.Fatalln("please set a positive LEAVE_ACCRUAL_INTERVAL_MINUTES env variable")
+		}
+	}
+
+	go leaveTypeRepo.StartAccrual(time.Duration(accrualIntervalMinutes) * time.Minute)
+
+	ledgerIntervalMinutes := 60
+
+	if ledgerInterval := os.Getenv("ATTENDANCE_LEDGER_INTERVAL_MINUTES"); ledgerInterval != "" {
+		ledgerIntervalMinutes, err = strconv.Atoi(ledgerInterval)
+
+		if err != nil || ledgerIntervalMinutes <= 0 {
+			log.Fatalln("please set a positive ATTENDANCE_LEDGER_INTERVAL_MINUTES env variable")
+		}
+	}
+
+	go attendanceLedgerRepo.StartLedger(time.Duration(ledgerIntervalMinutes) * time.Minute)
+
+	reportJobWorkers := 2
+
+	if workers := os.Getenv("REPORT_JOB_WORKERS"); workers != "" {
+		reportJobWorkers, err = strconv.Atoi(workers)
+
+		if err != nil || reportJobWorkers <= 0 {
+			log.Fatalln("please set a positive REPORT_JOB_WORKERS env variable")
+		}
+	}
+
+	reportJobPollSeconds := 10
+
+	if pollInterval := os.Getenv("REPORT_JOB_POLL_INTERVAL_SECONDS"); pollInterval != "" {
+		reportJobPollSeconds, err = strconv.Atoi(pollInterval)
+
+		if err != nil || reportJobPollSeconds <= 0 {
+			log.Fatalln("please set a positive REPORT_JOB_POLL_INTERVAL_SECONDS env variable")
+		}
+	}
+
+	reportJobRepo.StartWorkers(reportJobWorkers, time.Duration(reportJobPollSeconds)*time.Second)
+
+	go presenceRepo.StartListener()
+
+	go loginThrottleRepo.StartCleanup(time.Hour)
+
+	go authTokenRepo.StartCleanup(time.Hour)
+
+	webhookWorkers := 2
+
+	if workers := os.Getenv("WEBHOOK_WORKERS"); workers != "" {
+		webhookWorkers, err = strconv.Atoi(workers)
+
+		if err != nil || webhookWorkers <= 0 {
+			log.Fatalln("please set a positive WEBHOOK_WORKERS env variable")
+		}
+	}
+
+	webhookPollSeconds := 5
+
+	if pollInterval := os.Getenv("WEBHOOK_POLL_INTERVAL_SECONDS"); pollInterval != "" {
+		webhookPollSeconds, err = strconv.Atoi(pollInterval)
+
+		if err != nil || webhookPollSeconds <= 0 {
+			log.Fatalln("please set a positive WEBHOOK_POLL_INTERVAL_SECONDS env variable")
+		}
 	}
 
-	log.Println("database initialized successfully")
+	webhookRepo.StartWorkers(webhookWorkers, time.Duration(webhookPollSeconds)*time.Second)
 
 	serverListenAddres := os.Getenv("SERVER_LISTEN_ADDRESS")
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -12,13 +13,21 @@ type connection struct {
 	pool *pgxpool.Pool
 }
 
-func NewDatabase() *connection {
+func NewDatabase(location *time.Location) *connection {
 	dbUrl := os.Getenv("DB_URL")
 
 	if dbUrl == "" {
 		log.Fatalln("DB_URL env variable is missing")
 	}
-	pool, err := pgxpool.New(context.Background(), dbUrl)
+
+	config, err := pgxpool.ParseConfig(dbUrl)
+	if err != nil {
+		log.Fatalln("invalid DB_URL env variable, Error: ", err.Error())
+	}
+
+	config.ConnConfig.RuntimeParams["timezone"] = location.String()
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		log.Fatalln("error occurred while connecting to database, Error: ", err.Error())
 	}
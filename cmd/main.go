@@ -31,10 +31,17 @@ func init() {
 }
 
 func main() {
-	dbConnPool := NewDatabase()
+	organizationLocation := LoadOrganizationLocation()
+
+	dbConnPool := NewDatabase(organizationLocation)
 	defer dbConnPool.CloseConnection()
 	dbConnPool.CheckDatabase()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		RunMigrateCommand(dbConnPool, os.Args[2:])
+		return
+	}
+
 	awsS3Connection := NewS3Connection()
 
 	rabbitmqConn := NewRabbitmqConnection()
@@ -42,5 +49,5 @@ func main() {
 	defer rabbitmqConn.conn.Close()
 	defer rabbitmqConn.chann.Close()
 
-	Start(dbConnPool, awsS3Connection, rabbitmqConn)
+	Start(dbConnPool, awsS3Connection, rabbitmqConn, organizationLocation)
 }
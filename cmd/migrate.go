@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/vithsutra/ca_project_http_server/pkg/database"
+)
+
+const migrateUsage = "usage: main migrate up | down [steps] | status"
+
+func RunMigrateCommand(dbConnPool *connection, args []string) {
+	if len(args) == 0 {
+		log.Fatalln(migrateUsage)
+	}
+
+	postgresRepo := database.NewPostgresRepo(dbConnPool.pool)
+
+	switch args[0] {
+	case "up":
+		applied, err := postgresRepo.MigrateUp()
+		for _, migration := range applied {
+			log.Printf("applied migration %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalln("error occurred while applying migrations, Error: ", err.Error())
+		}
+		if len(applied) == 0 {
+			log.Println("database schema is already up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsedSteps, err := strconv.Atoi(args[1])
+			if err != nil || parsedSteps <= 0 {
+				log.Fatalln("steps must be a valid positive number")
+			}
+			steps = parsedSteps
+		}
+		rolledBack, err := postgresRepo.MigrateDown(steps)
+		for _, migration := range rolledBack {
+			log.Printf("rolled back migration %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalln("error occurred while rolling back migrations, Error: ", err.Error())
+		}
+		if len(rolledBack) == 0 {
+			log.Println("no applied migrations to roll back")
+		}
+	case "status":
+		statuses, err := postgresRepo.GetMigrationStatus()
+		if err != nil {
+			log.Fatalln("error occurred while fetching migration status, Error: ", err.Error())
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "-"
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(writer, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		writer.Flush()
+	default:
+		log.Fatalln(migrateUsage)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	//the runtime image ships without a zoneinfo database, the binary carries its own
+	_ "time/tzdata"
+)
+
+const defaultOrganizationTimeZone = "Asia/Kolkata"
+
+// LoadOrganizationLocation returns the timezone attendance is recorded in, the database
+// sessions use it too so dates and wall clock times resolve the same way on both sides
+func LoadOrganizationLocation() *time.Location {
+	organizationTimeZone := os.Getenv("ORGANIZATION_TIMEZONE")
+
+	if organizationTimeZone == "" {
+		organizationTimeZone = defaultOrganizationTimeZone
+	}
+
+	if organizationTimeZone == "Local" {
+		log.Fatalln("please set ORGANIZATION_TIMEZONE to an IANA timezone name")
+	}
+
+	location, err := time.LoadLocation(organizationTimeZone)
+
+	if err != nil {
+		log.Fatalln("invalid ORGANIZATION_TIMEZONE env variable, Error: ", err.Error())
+	}
+
+	return location
+}
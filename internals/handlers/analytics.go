@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type analyticsHandler struct {
+	repo *repository.AnalyticsRepo
+}
+
+func NewAnalyticsHandler(repo *repository.AnalyticsRepo) *analyticsHandler {
+	return &analyticsHandler{
+		repo,
+	}
+}
+
+func (h *analyticsHandler) GetAttendanceTrendHandler(ctx echo.Context) error {
+	trend, statusCode, err := h.repo.GetAttendanceTrend(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance trend fetched successfully",
+		Data:    trend,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *analyticsHandler) GetLeaveUtilizationHandler(ctx echo.Context) error {
+	utilization, statusCode, err := h.repo.GetLeaveUtilization(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave utilization fetched successfully",
+		Data:    utilization,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *analyticsHandler) GetTopAbsenteesHandler(ctx echo.Context) error {
+	absentees, statusCode, err := h.repo.GetTopAbsentees(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "top absentees fetched successfully",
+		Data:    absentees,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type attendanceCorrectionHandler struct {
+	repo *repository.AttendanceCorrectionRepo
+}
+
+func NewAttendanceCorrectionHandler(repo *repository.AttendanceCorrectionRepo) *attendanceCorrectionHandler {
+	return &attendanceCorrectionHandler{
+		repo,
+	}
+}
+
+func (h *attendanceCorrectionHandler) ApplyAttendanceCorrectionHandler(ctx echo.Context) error {
+	correctionId, statusCode, err := h.repo.ApplyAttendanceCorrection(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance correction applied successfully",
+		Data: map[string]string{
+			"correction_id": correctionId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *attendanceCorrectionHandler) GetUserAttendanceCorrectionsHandler(ctx echo.Context) error {
+	correctionsCount, corrections, statusCode, err := h.repo.GetUserAttendanceCorrections(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance corrections fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": correctionsCount,
+			"corrections": corrections,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *attendanceCorrectionHandler) GetPendingAttendanceCorrectionsHandler(ctx echo.Context) error {
+	pendingCorrectionsCount, pendingCorrections, statusCode, err := h.repo.GetPendingAttendanceCorrections(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "pending attendance corrections fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": pendingCorrectionsCount,
+			"corrections": pendingCorrections,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *attendanceCorrectionHandler) ApproveAttendanceCorrectionHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.ApproveAttendanceCorrection(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance correction approved successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *attendanceCorrectionHandler) RejectAttendanceCorrectionHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RejectAttendanceCorrection(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance correction rejected successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *attendanceCorrectionHandler) GetWorkSessionAuditTrailHandler(ctx echo.Context) error {
+	auditTrail, statusCode, err := h.repo.GetWorkSessionAuditTrail(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work session audit trail fetched successfully",
+		Data:    auditTrail,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
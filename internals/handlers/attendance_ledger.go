@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type attendanceLedgerHandler struct {
+	repo *repository.AttendanceLedgerRepo
+}
+
+func NewAttendanceLedgerHandler(repo *repository.AttendanceLedgerRepo) *attendanceLedgerHandler {
+	return &attendanceLedgerHandler{
+		repo,
+	}
+}
+
+func (h *attendanceLedgerHandler) GetAttendanceMonthHandler(ctx echo.Context) error {
+	attendanceMonth, statusCode, err := h.repo.GetAttendanceMonth(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "attendance month fetched successfully",
+		Data:    attendanceMonth,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
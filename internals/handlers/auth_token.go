@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type authTokenHandler struct {
+	repo *repository.AuthTokenRepo
+}
+
+func NewAuthTokenHandler(repo *repository.AuthTokenRepo) *authTokenHandler {
+	return &authTokenHandler{
+		repo,
+	}
+}
+
+func (h *authTokenHandler) RefreshTokensHandler(ctx echo.Context) error {
+	tokens, statusCode, err := h.repo.RefreshTokens(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "token refreshed successfully",
+		Data:    tokens,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *authTokenHandler) LogoutHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.Logout(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "logged out successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *authTokenHandler) RevokeUserSessionsHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RevokeUserSessions(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "user sessions revoked successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
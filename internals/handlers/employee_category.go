@@ -90,3 +90,44 @@ func (h *employeeCategoryHandler) DeleteEmployeeCategory(ctx echo.Context) error
 
 	return nil
 }
+
+func (h *employeeCategoryHandler) UpdateCategoryAttendanceSettingsHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpdateCategoryAttendanceSettings(ctx)
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "category attendance settings updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *employeeCategoryHandler) GetCategoryAttendanceSettingsHandler(ctx echo.Context) error {
+	settings, statusCode, err := h.repo.GetCategoryAttendanceSettings(ctx)
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "category attendance settings fetched successfully",
+		Data:    settings,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type holidayHandler struct {
+	repo *repository.HolidayRepo
+}
+
+func NewHolidayHandler(repo *repository.HolidayRepo) *holidayHandler {
+	return &holidayHandler{
+		repo,
+	}
+}
+
+func (h *holidayHandler) CreateHolidayHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.CreateHoliday(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "holiday created successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) ImportHolidaysHandler(ctx echo.Context) error {
+	importResult, statusCode, err := h.repo.ImportHolidays(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "holidays imported successfully",
+		Data:    importResult,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) GetHolidaysHandler(ctx echo.Context) error {
+	holidays, statusCode, err := h.repo.GetHolidays(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "holidays fetched successfully",
+		Data:    holidays,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) DeleteHolidayHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteHoliday(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "holiday deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) UpsertWeeklyOffRuleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpsertWeeklyOffRule(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "weekly off rule updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) GetWeeklyOffRulesHandler(ctx echo.Context) error {
+	rules, statusCode, err := h.repo.GetWeeklyOffRules(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "weekly off rules fetched successfully",
+		Data:    rules,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) DeleteWeeklyOffRuleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteWeeklyOffRule(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "weekly off rule deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *holidayHandler) GetUserHolidaysHandler(ctx echo.Context) error {
+	holidays, statusCode, err := h.repo.GetUserHolidays(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "user holidays fetched successfully",
+		Data:    holidays,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
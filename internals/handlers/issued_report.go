@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type issuedReportHandler struct {
+	repo *repository.IssuedReportRepo
+}
+
+func NewIssuedReportHandler(repo *repository.IssuedReportRepo) *issuedReportHandler {
+	return &issuedReportHandler{
+		repo,
+	}
+}
+
+func (h *issuedReportHandler) VerifyReportHandler(ctx echo.Context) error {
+	verification, statusCode, err := h.repo.VerifyReport(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report verified successfully",
+		Data:    verification,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
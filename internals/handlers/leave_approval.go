@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type leaveApprovalHandler struct {
+	repo *repository.LeaveApprovalRepo
+}
+
+func NewLeaveApprovalHandler(repo *repository.LeaveApprovalRepo) *leaveApprovalHandler {
+	return &leaveApprovalHandler{
+		repo,
+	}
+}
+
+func leaveDecisionMessage(result string) string {
+	if result == models.LeaveDecisionGranted {
+		return "leave granted successfully"
+	}
+
+	return "leave approved successfully, waiting on the next approver"
+}
+
+func (h *leaveApprovalHandler) UpdateReportingManagerHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpdateReportingManager(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "reporting manager updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) UpsertLeaveApprovalChainHandler(ctx echo.Context) error {
+	chainId, statusCode, err := h.repo.UpsertLeaveApprovalChain(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave approval chain updated successfully",
+		Data: map[string]interface{}{
+			"chain_id": chainId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) GetLeaveApprovalChainsHandler(ctx echo.Context) error {
+	chains, statusCode, err := h.repo.GetLeaveApprovalChains(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave approval chains fetched successfully",
+		Data:    chains,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) DeleteLeaveApprovalChainHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteLeaveApprovalChain(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave approval chain deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) GetLeaveApprovalStepsHandler(ctx echo.Context) error {
+	steps, statusCode, err := h.repo.GetLeaveApprovalSteps(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave approval steps fetched successfully",
+		Data:    steps,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) GetManagerPendingLeavesHandler(ctx echo.Context) error {
+	pendingLeavesCount, pendingLeaves, statusCode, err := h.repo.GetManagerPendingLeaves(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "pending approvals fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": pendingLeavesCount,
+			"leaves":      pendingLeaves,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) GrantUserLeaveHandler(ctx echo.Context) error {
+	result, statusCode, err := h.repo.GrantUserLeave(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: leaveDecisionMessage(result),
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) RejectUserLeaveHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RejectUserLeave(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave rejected successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) ApproveManagedLeaveHandler(ctx echo.Context) error {
+	result, statusCode, err := h.repo.ApproveManagedLeave(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: leaveDecisionMessage(result),
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveApprovalHandler) RejectManagedLeaveHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RejectManagedLeave(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave rejected successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type leaveTypeHandler struct {
+	repo *repository.LeaveTypeRepo
+}
+
+func NewLeaveTypeHandler(repo *repository.LeaveTypeRepo) *leaveTypeHandler {
+	return &leaveTypeHandler{
+		repo,
+	}
+}
+
+func (h *leaveTypeHandler) CreateLeaveTypeHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.CreateLeaveType(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave type created successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveTypeHandler) GetLeaveTypesHandler(ctx echo.Context) error {
+	leaveTypes, statusCode, err := h.repo.GetLeaveTypes(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave types fetched successfully",
+		Data:    leaveTypes,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveTypeHandler) DeleteLeaveTypeHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteLeaveType(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave type deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveTypeHandler) AdjustLeaveBalanceHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.AdjustLeaveBalance(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "leave balance adjusted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveTypeHandler) GetUserLeaveBalancesHandler(ctx echo.Context) error {
+	leaveBalances, statusCode, err := h.repo.GetUserLeaveBalances(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "user leave balances fetched successfully",
+		Data:    leaveBalances,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *leaveTypeHandler) GetUserLeaveLedgerHandler(ctx echo.Context) error {
+	ledgerCount, ledgerEntries, statusCode, err := h.repo.GetUserLeaveLedger(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "user leave ledger fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": ledgerCount,
+			"entries":     ledgerEntries,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
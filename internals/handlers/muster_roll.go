@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type musterRollHandler struct {
+	repo *repository.MusterRollRepo
+}
+
+func NewMusterRollHandler(repo *repository.MusterRollRepo) *musterRollHandler {
+	return &musterRollHandler{
+		repo,
+	}
+}
+
+func (h *musterRollHandler) DownloadMusterRollHandler(ctx echo.Context) error {
+	musterRoll, format, statusCode, err := h.repo.DownloadMusterRoll(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	var file bytes.Buffer
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	if format == "csv" {
+		contentType = "text/csv"
+		err = utils.WriteMusterRollCsv(&file, musterRoll)
+	} else {
+		err = utils.WriteMusterRollXlsx(&file, musterRoll)
+	}
+
+	if err != nil {
+		log.Println("error occurred while generating the muster roll, Error: ", err)
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  "error occurred while generating the muster roll",
+		}
+		ctx.JSON(500, response)
+		return err
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"muster_roll_%s.%s\"", musterRoll.Month, format))
+
+	return ctx.Blob(int(statusCode), contentType, file.Bytes())
+}
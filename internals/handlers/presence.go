@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+// comment sent while no events arrive, so proxies do not close an idle stream
+const presenceKeepAliveInterval = 25 * time.Second
+
+type presenceHandler struct {
+	repo *repository.PresenceRepo
+}
+
+func NewPresenceHandler(repo *repository.PresenceRepo) *presenceHandler {
+	return &presenceHandler{
+		repo,
+	}
+}
+
+// StreamPresenceHandler streams the presence events of the admin as server sent events until the
+// client disconnects, the token expires or a keep-alive check refuses the token
+func (h *presenceHandler) StreamPresenceHandler(ctx echo.Context) error {
+	claims, ok := ctx.Get(models.AuthClaimsKey).(*models.AuthClaims)
+
+	if !ok {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  "invalid or missing token",
+		}
+		ctx.JSON(http.StatusUnauthorized, response)
+		return errors.New("invalid or missing token")
+	}
+
+	events, unsubscribe, statusCode, err := h.repo.SubscribePresence(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	defer unsubscribe()
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+	response.Flush()
+
+	keepAlive := time.NewTicker(presenceKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	tokenExpiry := time.NewTimer(time.Until(claims.ExpiresAt))
+	defer tokenExpiry.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-tokenExpiry.C:
+			endPresenceStream(response, "token expired, please reconnect with a new token")
+			return nil
+		case <-keepAlive.C:
+			if _, err := h.repo.CheckSubscriber(ctx); err != nil {
+				endPresenceStream(response, err.Error())
+				return nil
+			}
+
+			if _, err := fmt.Fprint(response, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			response.Flush()
+		case event := <-events:
+			data, err := json.Marshal(event)
+
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.EventType, data); err != nil {
+				return nil
+			}
+			response.Flush()
+		}
+	}
+}
+
+// endPresenceStream tells the client why the stream ends before it is closed
+func endPresenceStream(response *echo.Response, reason string) {
+	data, err := json.Marshal(map[string]string{"error": reason})
+
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(response, "event: stream_closed\ndata: %s\n\n", data)
+	response.Flush()
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type reportBrandingHandler struct {
+	repo *repository.ReportBrandingRepo
+}
+
+func NewReportBrandingHandler(repo *repository.ReportBrandingRepo) *reportBrandingHandler {
+	return &reportBrandingHandler{
+		repo,
+	}
+}
+
+func (h *reportBrandingHandler) UpsertReportBrandingHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpsertReportBranding(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report branding updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *reportBrandingHandler) GetReportBrandingHandler(ctx echo.Context) error {
+	branding, statusCode, err := h.repo.GetReportBranding(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report branding fetched successfully",
+		Data:    branding,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *reportBrandingHandler) UpdateReportLogoHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpdateReportLogo(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report logo updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *reportBrandingHandler) DeleteReportLogoHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteReportLogo(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report logo deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
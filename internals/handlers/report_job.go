@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type reportJobHandler struct {
+	repo *repository.ReportJobRepo
+}
+
+func NewReportJobHandler(repo *repository.ReportJobRepo) *reportJobHandler {
+	return &reportJobHandler{
+		repo,
+	}
+}
+
+func (h *reportJobHandler) CreateReportJobHandler(ctx echo.Context) error {
+	jobId, statusCode, err := h.repo.CreateReportJob(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report job created successfully",
+		Data: map[string]interface{}{
+			"job_id": jobId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *reportJobHandler) GetReportJobHandler(ctx echo.Context) error {
+	reportJob, statusCode, err := h.repo.GetReportJob(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report job fetched successfully",
+		Data:    reportJob,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *reportJobHandler) GetReportJobsHandler(ctx echo.Context) error {
+	reportJobsCount, reportJobs, statusCode, err := h.repo.GetReportJobs(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "report jobs fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": reportJobsCount,
+			"jobs":        reportJobs,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type roleHandler struct {
+	repo *repository.RoleRepo
+}
+
+func NewRoleHandler(repo *repository.RoleRepo) *roleHandler {
+	return &roleHandler{
+		repo,
+	}
+}
+
+func (h *roleHandler) CreateRoleHandler(ctx echo.Context) error {
+	roleId, statusCode, err := h.repo.CreateRole(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "role created successfully",
+		Data: map[string]interface{}{
+			"role_id": roleId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) GetRolesHandler(ctx echo.Context) error {
+	roles, statusCode, err := h.repo.GetRoles(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "roles fetched successfully",
+		Data:    roles,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) UpdateRoleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpdateRole(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "role updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) DeleteRoleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteRole(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "role deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) AssignUserRoleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.AssignUserRole(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "role assigned successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) RemoveUserRoleHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RemoveUserRole(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "role removed successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) UpdateUserManagedCategoriesHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UpdateUserManagedCategories(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "managed categories updated successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *roleHandler) GetUserRolesHandler(ctx echo.Context) error {
+	userRoles, statusCode, err := h.repo.GetUserRoles(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "user roles fetched successfully",
+		Data:    userRoles,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
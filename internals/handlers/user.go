@@ -1,10 +1,9 @@
 package handlers
 
 import (
+	"bytes"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -137,7 +136,7 @@ func (h *userHandler) UserLoginHandler(ctx echo.Context) error {
 }
 
 func (h *userHandler) UserWorkLoginHandler(ctx echo.Context) error {
-	statusCode, err := h.repo.UserWorkLogin(ctx)
+	sessionId, statusCode, err := h.repo.UserWorkLogin(ctx)
 
 	if err != nil {
 		response := &models.ErrorResponse{
@@ -150,6 +149,9 @@ func (h *userHandler) UserWorkLoginHandler(ctx echo.Context) error {
 	response := &models.SuccessResponse{
 		Status:  "success",
 		Message: "user work login successfull",
+		Data: map[string]string{
+			"session_id": sessionId,
+		},
 	}
 
 	ctx.JSON(int(statusCode), response)
@@ -157,7 +159,7 @@ func (h *userHandler) UserWorkLoginHandler(ctx echo.Context) error {
 }
 
 func (h *userHandler) UserWorkLogoutHandler(ctx echo.Context) error {
-	statusCode, err := h.repo.UserWorkLogout(ctx)
+	sessionId, statusCode, err := h.repo.UserWorkLogout(ctx)
 	if err != nil {
 		response := &models.ErrorResponse{
 			Status: "error",
@@ -169,6 +171,9 @@ func (h *userHandler) UserWorkLogoutHandler(ctx echo.Context) error {
 	response := &models.SuccessResponse{
 		Status:  "success",
 		Message: "user work logout successfull",
+		Data: map[string]string{
+			"session_id": sessionId,
+		},
 	}
 
 	ctx.JSON(int(statusCode), response)
@@ -265,28 +270,6 @@ func (h *userHandler) CancelUserLeaveHandler(ctx echo.Context) error {
 	return nil
 }
 
-func (h *userHandler) GrantUserLeaveHandler(ctx echo.Context) error {
-	statusCode, err := h.repo.GrantUserLeave(ctx)
-
-	if err != nil {
-		response := &models.ErrorResponse{
-			Status: "error",
-			Error:  err.Error(),
-		}
-		ctx.JSON(int(statusCode), response)
-		return err
-	}
-
-	response := &models.SuccessResponse{
-		Status:  "success",
-		Message: "user leave granted successfully",
-	}
-
-	ctx.JSON(int(statusCode), response)
-
-	return nil
-}
-
 func (h *userHandler) UserProfileInfoUpdateHandler(ctx echo.Context) error {
 	statusCode, err := h.repo.UpdateUserProfileInfo(ctx)
 
@@ -516,7 +499,7 @@ func (h *userHandler) GetAllUsersWorkHistory(ctx echo.Context) error {
 }
 
 func (h *userHandler) DownloadUserReportPdf(ctx echo.Context) error {
-	userReportData, statusCode, err := h.repo.DownloadUserWorkHistory(ctx)
+	userReportData, file, statusCode, err := h.repo.DownloadUserWorkHistory(ctx)
 
 	if err != nil {
 		response := &models.ErrorResponse{
@@ -527,24 +510,8 @@ func (h *userHandler) DownloadUserReportPdf(ctx echo.Context) error {
 		return err
 	}
 
-	pdfId, err := utils.GenerateUserReportPdf(userReportData)
-
-	if err != nil {
-		log.Println("error occurred while generating the pdf, Error: ", err)
-		response := &models.ErrorResponse{
-			Status: "error",
-			Error:  "error occurred while generating the pdf",
-		}
-		ctx.JSON(int(statusCode), response)
-		return err
-	}
-
-	ctx.File(fmt.Sprintf("./users_cache/%s.pdf", pdfId))
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s_work_report.pdf\"", utils.SafeFileName(userReportData.Name)))
+	ctx.Response().Header().Set(echo.HeaderContentLength, strconv.Itoa(len(file)))
 
-	if err := os.Remove(fmt.Sprintf("./users_cache/%s.pdf", pdfId)); err != nil {
-		log.Println("error occurred while removing the pdf file: Error", err.Error())
-		return err
-	}
-
-	return nil
+	return ctx.Stream(int(statusCode), "application/pdf", bytes.NewReader(file))
 }
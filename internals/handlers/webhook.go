@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type webhookHandler struct {
+	repo *repository.WebhookRepo
+}
+
+func NewWebhookHandler(repo *repository.WebhookRepo) *webhookHandler {
+	return &webhookHandler{
+		repo,
+	}
+}
+
+func (h *webhookHandler) CreateWebhookHandler(ctx echo.Context) error {
+	webhookId, statusCode, err := h.repo.CreateWebhook(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhook created successfully",
+		Data: map[string]interface{}{
+			"webhook_id": webhookId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *webhookHandler) GetWebhooksHandler(ctx echo.Context) error {
+	webhooks, statusCode, err := h.repo.GetWebhooks(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhooks fetched successfully",
+		Data:    webhooks,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *webhookHandler) DeleteWebhookHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteWebhook(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhook deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *webhookHandler) GetWebhookDeliveriesHandler(ctx echo.Context) error {
+	deliveriesCount, deliveries, statusCode, err := h.repo.GetWebhookDeliveries(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhook deliveries fetched successfully",
+		Data: map[string]interface{}{
+			"total_count": deliveriesCount,
+			"deliveries":  deliveries,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *webhookHandler) GetWebhookDeliveryHandler(ctx echo.Context) error {
+	delivery, statusCode, err := h.repo.GetWebhookDelivery(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhook delivery fetched successfully",
+		Data:    delivery,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *webhookHandler) RedeliverWebhookDeliveryHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.RedeliverWebhookDelivery(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "webhook delivery queued for redelivery",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type workSessionHandler struct {
+	repo *repository.WorkSessionRepo
+}
+
+func NewWorkSessionHandler(repo *repository.WorkSessionRepo) *workSessionHandler {
+	return &workSessionHandler{
+		repo,
+	}
+}
+
+func (h *workSessionHandler) UserWorkHeartbeatHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.UserWorkHeartbeat(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work activity recorded successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSessionHandler) GetWorkSessionsForReviewHandler(ctx echo.Context) error {
+	sessions, statusCode, err := h.repo.GetWorkSessionsForReview(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work sessions for review fetched successfully",
+		Data:    sessions,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSessionHandler) ResolveWorkSessionReviewHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.ResolveWorkSessionReview(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work session review resolved successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
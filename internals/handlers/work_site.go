@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+type workSiteHandler struct {
+	repo *repository.WorkSiteRepo
+}
+
+func NewWorkSiteHandler(repo *repository.WorkSiteRepo) *workSiteHandler {
+	return &workSiteHandler{
+		repo,
+	}
+}
+
+func (h *workSiteHandler) CreateWorkSiteHandler(ctx echo.Context) error {
+	siteId, statusCode, err := h.repo.CreateWorkSite(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work site created successfully",
+		Data: map[string]string{
+			"site_id": siteId,
+		},
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSiteHandler) GetWorkSitesHandler(ctx echo.Context) error {
+	workSites, statusCode, err := h.repo.GetWorkSites(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work sites fetched successfully",
+		Data:    workSites,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSiteHandler) DeleteWorkSiteHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteWorkSite(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work site deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSiteHandler) AssignWorkSiteHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.AssignWorkSite(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work site assigned successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSiteHandler) GetWorkSiteAssignmentsHandler(ctx echo.Context) error {
+	assignments, statusCode, err := h.repo.GetWorkSiteAssignments(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work site assignments fetched successfully",
+		Data:    assignments,
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
+
+func (h *workSiteHandler) DeleteWorkSiteAssignmentHandler(ctx echo.Context) error {
+	statusCode, err := h.repo.DeleteWorkSiteAssignment(ctx)
+
+	if err != nil {
+		response := &models.ErrorResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}
+		ctx.JSON(int(statusCode), response)
+		return err
+	}
+
+	response := &models.SuccessResponse{
+		Status:  "success",
+		Message: "work site assignment deleted successfully",
+	}
+
+	ctx.JSON(int(statusCode), response)
+	return nil
+}
@@ -0,0 +1,126 @@
+package middlewares
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+var errInvalidToken = errors.New("invalid or missing token")
+
+type Authorization struct {
+	repo *repository.AuthorizationRepo
+}
+
+func NewAuthorization(repo *repository.AuthorizationRepo) *Authorization {
+	return &Authorization{
+		repo,
+	}
+}
+
+// Authenticate lets admin and user tokens through and keeps their claims for Owns and Can. it
+// runs after the jwt middleware.
+func (a *Authorization) Authenticate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, statusCode, err := a.repo.Authenticate(ctx)
+
+			if err != nil {
+				return authorizationError(ctx, statusCode, err)
+			}
+
+			ctx.Set(models.AuthClaimsKey, claims)
+
+			return next(ctx)
+		}
+	}
+}
+
+// Role only lets tokens of the role through and keeps their claims for Owns. it runs after the
+// jwt middleware.
+func (a *Authorization) Role(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, statusCode, err := a.repo.Authenticate(ctx)
+
+			if err != nil {
+				return authorizationError(ctx, statusCode, err)
+			}
+
+			if claims.Role != role {
+				return authorizationError(ctx, 403, errors.New("access denied for the token"))
+			}
+
+			ctx.Set(models.AuthClaimsKey, claims)
+
+			return next(ctx)
+		}
+	}
+}
+
+// Owns rejects the request with 403 when an id pointed at by the rules does not belong to the
+// caller
+func (a *Authorization) Owns(rules ...models.AuthRule) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := ctx.Get(models.AuthClaimsKey).(*models.AuthClaims)
+
+			if !ok {
+				return authorizationError(ctx, 401, errInvalidToken)
+			}
+
+			statusCode, err := a.repo.Authorize(ctx, claims, rules)
+
+			if err != nil {
+				return authorizationError(ctx, statusCode, err)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// Can rejects the request with 403 when the caller does not hold the permission over the ids
+// pointed at by the rules
+func (a *Authorization) Can(permission string, rules ...models.AuthRule) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := ctx.Get(models.AuthClaimsKey).(*models.AuthClaims)
+
+			if !ok {
+				return authorizationError(ctx, 401, errInvalidToken)
+			}
+
+			statusCode, err := a.repo.AuthorizePermission(ctx, claims, permission, rules)
+
+			if err != nil {
+				return authorizationError(ctx, statusCode, err)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+func Param(name string, resource string) models.AuthRule {
+	return models.AuthRule{Source: models.SourceParam, Name: name, Resource: resource}
+}
+
+func Query(name string, resource string) models.AuthRule {
+	return models.AuthRule{Source: models.SourceQuery, Name: name, Resource: resource}
+}
+
+func Body(name string, resource string) models.AuthRule {
+	return models.AuthRule{Source: models.SourceBody, Name: name, Resource: resource}
+}
+
+func authorizationError(ctx echo.Context, statusCode int32, err error) error {
+	response := &models.ErrorResponse{
+		Status: "error",
+		Error:  err.Error(),
+	}
+	ctx.JSON(int(statusCode), response)
+	return err
+}
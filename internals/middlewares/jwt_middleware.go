@@ -1,13 +1,26 @@
 package middlewares
 
 import (
-	"os"
-
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
 )
 
 func JwtMiddleware() echo.MiddlewareFunc {
-	secretKey := os.Getenv("JWT_TOKEN_SCRETE_KEY")
-	return echojwt.JWT([]byte(secretKey))
+	return echojwt.WithConfig(echojwt.Config{
+		ParseTokenFunc: parseAccessToken,
+	})
+}
+
+// JwtStreamMiddleware also reads the token from the token query parameter, browser event sources
+// can not set the authorization header
+func JwtStreamMiddleware() echo.MiddlewareFunc {
+	return echojwt.WithConfig(echojwt.Config{
+		ParseTokenFunc: parseAccessToken,
+		TokenLookup:    "header:Authorization:Bearer ,query:token",
+	})
+}
+
+func parseAccessToken(ctx echo.Context, auth string) (interface{}, error) {
+	return utils.ParseAccessToken(auth)
 }
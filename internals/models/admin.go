@@ -52,10 +52,6 @@ type AdminLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-type AdminLoginResponse struct {
-	Token string `json:"token"`
-}
-
 type AdminForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
@@ -96,6 +92,7 @@ type AdminInterface interface {
 	UpdateAdminProfilePictureUrl(adminId string, url string) error
 	StoreAdminOtp(email string, otp string, expireTime time.Time) error
 	DeleteAdminOtp(email string, otp string) error
+	DeleteAdminOtps(email string) error
 	ValidateAdminOtp(email string, otp string) (bool, error)
 	GetAdminDetailsForValidOtp(email string) (string, string, error)
 	UpdateAdminNewPassword(adminId string, password string) error
@@ -0,0 +1,84 @@
+package models
+
+// analytics read the attendance ledger, so only finished days are counted
+type AnalyticsRequest struct {
+	AdminId    string `query:"admin_id" validate:"required"`
+	StartDate  string `query:"start_date" validate:"required,date"`
+	EndDate    string `query:"end_date" validate:"required,date"`
+	CategoryId string `query:"category_id"`
+}
+
+// AttendanceTrendRow holds the sums of one day, the averages are derived from them
+type AttendanceTrendRow struct {
+	Date                 string
+	Present              int32
+	HalfDay              int32
+	Absent               int32
+	OnLeave              int32
+	LoggedIn             int32
+	FirstLoginMinutesSum int64
+	Worked               int32
+	WorkedMinutesSum     int64
+	LateArrivals         int32
+}
+
+// present counts half days too, the late arrival rate is the share of the days with a punch
+// that started late
+type AttendanceTrendDay struct {
+	Date               string   `json:"date"`
+	Present            int32    `json:"present"`
+	HalfDay            int32    `json:"half_day"`
+	Absent             int32    `json:"absent"`
+	OnLeave            int32    `json:"on_leave"`
+	AverageLoginTime   *string  `json:"average_login_time"`
+	AverageWorkedHours *string  `json:"average_worked_hours"`
+	LateArrivals       int32    `json:"late_arrivals"`
+	LateArrivalRate    *float64 `json:"late_arrival_rate"`
+}
+
+type AttendanceTrendSummary struct {
+	AveragePresent     float64  `json:"average_present"`
+	AverageLoginTime   *string  `json:"average_login_time"`
+	AverageWorkedHours *string  `json:"average_worked_hours"`
+	LateArrivals       int32    `json:"late_arrivals"`
+	LateArrivalRate    *float64 `json:"late_arrival_rate"`
+}
+
+type AttendanceTrendResponse struct {
+	StartDate string                 `json:"start_date"`
+	EndDate   string                 `json:"end_date"`
+	Summary   AttendanceTrendSummary `json:"summary"`
+	Days      []*AttendanceTrendDay  `json:"days"`
+}
+
+// leave days count partial day leaves as their share of the net shift, the quota is the annual
+// quota of every user of the category prorated to the date range
+type LeaveUtilization struct {
+	LeaveTypeId     string   `json:"leave_type_id"`
+	LeaveName       string   `json:"leave_name"`
+	CategoryName    string   `json:"category_name"`
+	UsersCount      int32    `json:"users_count"`
+	UsersOnLeave    int32    `json:"users_on_leave"`
+	LeaveDays       float64  `json:"leave_days"`
+	AnnualQuota     float64  `json:"annual_quota"`
+	ProratedQuota   float64  `json:"prorated_quota"`
+	UtilizationRate *float64 `json:"utilization_rate"`
+}
+
+// absent days count a half day as half absent
+type TopAbsentee struct {
+	UserId       string  `json:"user_id"`
+	Name         string  `json:"name"`
+	CategoryName string  `json:"category_name"`
+	AbsentDays   float64 `json:"absent_days"`
+	LeaveDays    int32   `json:"leave_days"`
+	WorkingDays  int32   `json:"working_days"`
+}
+
+type AnalyticsInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	GetAttendanceTrend(adminId string, categoryId *string, startDate string, endDate string) ([]*AttendanceTrendRow, error)
+	GetLeaveUtilization(adminId string, categoryId *string, startDate string, endDate string) ([]*LeaveUtilization, error)
+	GetTopAbsentees(adminId string, categoryId *string, startDate string, endDate string, limit int) ([]*TopAbsentee, error)
+}
@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// dates and times are wall clock values of the organization timezone, a missed login
+// describes a whole session that was never punched so both login and logout are needed
+type AttendanceCorrectionRequest struct {
+	UserId         string `json:"user_id" validate:"required"`
+	SessionId      string `json:"session_id" validate:"required_unless=CorrectionType missed_login,excluded_if=CorrectionType missed_login"`
+	CorrectionType string `json:"correction_type" validate:"required,oneof=missed_login missed_logout wrong_time"`
+	LoginDate      string `json:"login_date" validate:"required_with=LoginTime,omitempty,date"`
+	LoginTime      string `json:"login_time" validate:"required_with=LoginDate,omitempty,time"`
+	LogoutDate     string `json:"logout_date" validate:"required_with=LogoutTime,omitempty,date"`
+	LogoutTime     string `json:"logout_time" validate:"required_with=LogoutDate,omitempty,time"`
+	Work           string `json:"work"`
+	Reason         string `json:"reason" validate:"required"`
+}
+
+type AttendanceCorrection struct {
+	CorrectionId      string
+	UserId            string
+	SessionId         *string
+	CorrectionType    string
+	RequestedLoginAt  *time.Time
+	RequestedLogoutAt *time.Time
+	Work              string
+	Reason            string
+	Status            string
+}
+
+type AttendanceCorrectionResponse struct {
+	CorrectionId      string     `json:"correction_id"`
+	UserId            string     `json:"user_id"`
+	UserName          string     `json:"user_name"`
+	SessionId         *string    `json:"session_id"`
+	CorrectionType    string     `json:"correction_type"`
+	CurrentLoginAt    *time.Time `json:"current_login_at"`
+	CurrentLogoutAt   *time.Time `json:"current_logout_at"`
+	RequestedLoginAt  *time.Time `json:"requested_login_at"`
+	RequestedLogoutAt *time.Time `json:"requested_logout_at"`
+	Work              string     `json:"work"`
+	Reason            string     `json:"reason"`
+	Status            string     `json:"status"`
+	ReviewedBy        *string    `json:"reviewed_by"`
+	ReviewComment     string     `json:"review_comment"`
+	ReviewedAt        *time.Time `json:"reviewed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+type AttendanceCorrectionReviewRequest struct {
+	AdminId string `json:"admin_id" validate:"required"`
+	Comment string `json:"comment"`
+}
+
+type WorkSessionTimes struct {
+	SessionId  string
+	UserId     string
+	LoginAt    time.Time
+	LogoutAt   *time.Time
+	AutoClosed bool
+}
+
+// AttendanceCorrectionApproval carries the final session times, a missed login creates the session
+type AttendanceCorrectionApproval struct {
+	CorrectionId  string
+	UserId        string
+	SessionId     string
+	NewSession    bool
+	LoginAt       time.Time
+	LogoutAt      *time.Time
+	Work          string
+	Reason        string
+	ReviewedBy    string
+	ReviewComment string
+}
+
+type WorkSessionAuditResponse struct {
+	AuditId      string     `json:"audit_id"`
+	SessionId    string     `json:"session_id"`
+	CorrectionId *string    `json:"correction_id"`
+	ChangedBy    string     `json:"changed_by"`
+	ChangeReason string     `json:"change_reason"`
+	OldWorkDate  *string    `json:"old_work_date"`
+	OldLoginAt   *time.Time `json:"old_login_at"`
+	OldLogoutAt  *time.Time `json:"old_logout_at"`
+	NewWorkDate  string     `json:"new_work_date"`
+	NewLoginAt   time.Time  `json:"new_login_at"`
+	NewLogoutAt  *time.Time `json:"new_logout_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type AttendanceCorrectionInterface interface {
+	CheckUserIdExists(userId string) (bool, error)
+	CheckAdminIdExists(adminId string) (bool, error)
+	GetAdminIdByUserId(userId string) (string, error)
+	CheckUserWorkSessionIdExists(userId string, sessionId string) (bool, error)
+	GetWorkSessionTimes(sessionId string) (*WorkSessionTimes, error)
+	CheckWorkSessionTimeOverlap(userId string, excludeSessionId string, loginAt time.Time, logoutAt *time.Time) (bool, error)
+	CheckPendingAttendanceCorrectionExists(sessionId string) (bool, error)
+	CreateAttendanceCorrection(correction *AttendanceCorrection) error
+	GetUserAttendanceCorrectionsCount(userId string, status string) (int, error)
+	GetUserAttendanceCorrections(userId string, status string, limit uint32, offset uint32) ([]*AttendanceCorrectionResponse, error)
+	GetPendingAttendanceCorrectionsCount(adminId string) (int, error)
+	GetPendingAttendanceCorrections(adminId string, limit uint32, offset uint32) ([]*AttendanceCorrectionResponse, error)
+	CheckAttendanceCorrectionIdExists(correctionId string) (bool, error)
+	GetAttendanceCorrection(correctionId string) (*AttendanceCorrection, error)
+	ApproveAttendanceCorrection(approval *AttendanceCorrectionApproval) (bool, error)
+	RejectAttendanceCorrection(correctionId string, reviewedBy string, reviewComment string) (bool, error)
+	GetWorkSessionAuditTrail(sessionId string) ([]*WorkSessionAuditResponse, error)
+}
@@ -0,0 +1,66 @@
+package models
+
+// AttendanceUser is a user with the shift of the user's category. the ledger of a user starts
+// on the day the user was created, last computed date is nil until the first run.
+type AttendanceUser struct {
+	UserId           string
+	CreatedDate      string
+	LastComputedDate *string
+	ShiftStartTime   string
+	ShiftEndTime     string
+	BreakMinutes     int
+}
+
+// AttendanceSessionDay sums the closed sessions of one work date, a session still open or
+// waiting for admin review leaves the day incomplete
+// first login minute is the first punch of the day in minutes after midnight
+type AttendanceSessionDay struct {
+	Date             string
+	WorkedMinutes    int
+	HasOpenSession   bool
+	FirstLoginMinute *int
+	LateMinutes      int
+}
+
+type AttendanceDay struct {
+	UserId           string
+	AttendanceDate   string
+	Status           string
+	WorkedMinutes    int
+	LeaveMinutes     int
+	Note             *string
+	FirstLoginMinute *int
+	LateMinutes      int
+	LeaveTypeId      *string
+}
+
+type AttendanceDayResponse struct {
+	Date          string  `json:"date"`
+	Status        string  `json:"status"`
+	WorkedMinutes int32   `json:"worked_minutes"`
+	LeaveMinutes  int32   `json:"leave_minutes"`
+	Note          *string `json:"note"`
+}
+
+// days holds one entry per day of the month, days not computed yet are null
+type UserAttendanceMonth struct {
+	UserId  string                   `json:"user_id"`
+	Name    string                   `json:"name"`
+	Days    []*AttendanceDayResponse `json:"days"`
+	Summary map[string]int           `json:"summary"`
+}
+
+type AttendanceMonthGridResponse struct {
+	Month string                 `json:"month"`
+	Users []*UserAttendanceMonth `json:"users"`
+}
+
+type AttendanceLedgerInterface interface {
+	GetAttendanceUsers(userId string) ([]*AttendanceUser, error)
+	GetAttendanceSessionDays(userId string, fromDate string, toDate string) ([]*AttendanceSessionDay, error)
+	GetUserGrantedLeavesBetween(userId string, fromDate string, toDate string) ([]*UserLeave, error)
+	GetUserWorkCalendar(userId string, fromDate string, toDate string) (*WorkCalendar, error)
+	UpsertAttendanceDays(days []*AttendanceDay) error
+	CheckAdminIdExists(adminId string) (bool, error)
+	GetAttendanceMonth(adminId string, month string) ([]*UserAttendanceMonth, error)
+}
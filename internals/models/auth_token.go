@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// token is the access token, expires in is its lifetime in seconds
+type AuthTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshToken struct {
+	TokenId   string
+	FamilyId  string
+	AdminId   string
+	UserId    *string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// the admin or user a token is issued to, user id is nil for an admin
+type TokenSubject struct {
+	AdminId string
+	UserId  *string
+	Email   string
+	Name    string
+}
+
+// results of presenting a refresh token
+const (
+	RefreshTokenRotated = "rotated"
+	RefreshTokenInvalid = "invalid"
+	RefreshTokenExpired = "expired"
+	RefreshTokenRevoked = "revoked"
+	RefreshTokenReused  = "reused"
+)
+
+type AuthTokenInterface interface {
+	CheckUserIdExists(userId string) (bool, error)
+	CreateRefreshToken(token *RefreshToken) error
+	RotateRefreshToken(tokenHash string, next *RefreshToken) (*TokenSubject, string, error)
+	RevokeRefreshTokenFamily(tokenHash string) error
+	RevokeUserSessions(userId string) error
+	DeleteExpiredRefreshTokens() (int64, error)
+}
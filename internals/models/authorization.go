@@ -0,0 +1,141 @@
+package models
+
+import "time"
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// resources a route may reference, each is owned by an admin and the user scoped ones also by
+// a user
+const (
+	ResourceAdmin           = "admin"
+	ResourceUser            = "user"
+	ResourceCategory        = "category"
+	ResourceLeave           = "leave"
+	ResourceLeaveType       = "leave_type"
+	ResourceWorkSession     = "work_session"
+	ResourceCorrection      = "attendance_correction"
+	ResourceHoliday         = "holiday"
+	ResourceWeeklyOffRule   = "weekly_off_rule"
+	ResourceWorkSite        = "work_site"
+	ResourceSiteAssignment  = "work_site_assignment"
+	ResourceReportJob       = "report_job"
+	ResourceWebhook         = "webhook"
+	ResourceWebhookDelivery = "webhook_delivery"
+	ResourceRole            = "role"
+	ResourceApprovalChain   = "leave_approval_chain"
+)
+
+// permissions a route may require. the admin of an organization holds all of them, a user holds
+// the ones granted by the roles of the user.
+const (
+	PermissionAccountManage     = "account.manage"
+	PermissionRoleManage        = "role.manage"
+	PermissionUserCreate        = "user.create"
+	PermissionUserRead          = "user.read"
+	PermissionUserDelete        = "user.delete"
+	PermissionUserRevokeSession = "user.revoke_sessions"
+	PermissionCategoryRead      = "category.read"
+	PermissionCategoryManage    = "category.manage"
+	PermissionLeaveRead         = "leave.read"
+	PermissionLeaveApprove      = "leave.approve"
+	PermissionLeaveAdjust       = "leave.adjust"
+	PermissionLeaveTypeManage   = "leave_type.manage"
+	PermissionAttendanceRead    = "attendance.read"
+	PermissionAttendanceCorrect = "attendance.correct"
+	PermissionCalendarRead      = "calendar.read"
+	PermissionCalendarManage    = "calendar.manage"
+	PermissionWorkSiteRead      = "work_site.read"
+	PermissionWorkSiteManage    = "work_site.manage"
+	PermissionReportDownload    = "report.download"
+	PermissionReportBranding    = "report.branding"
+	PermissionAnalyticsRead     = "analytics.read"
+	PermissionPresenceRead      = "presence.read"
+	PermissionWebhookManage     = "webhook.manage"
+	PermissionReportingManage   = "reporting.manage"
+	PermissionLeaveChainManage  = "leave_chain.manage"
+)
+
+// permissions a role may grant. account and role management stay with the admin, so a role can
+// not widen its own rights.
+var GrantablePermissions = map[string]bool{
+	PermissionUserCreate:        true,
+	PermissionUserRead:          true,
+	PermissionUserDelete:        true,
+	PermissionUserRevokeSession: true,
+	PermissionCategoryRead:      true,
+	PermissionCategoryManage:    true,
+	PermissionLeaveRead:         true,
+	PermissionLeaveApprove:      true,
+	PermissionLeaveAdjust:       true,
+	PermissionLeaveTypeManage:   true,
+	PermissionAttendanceRead:    true,
+	PermissionAttendanceCorrect: true,
+	PermissionCalendarRead:      true,
+	PermissionCalendarManage:    true,
+	PermissionWorkSiteRead:      true,
+	PermissionWorkSiteManage:    true,
+	PermissionReportDownload:    true,
+	PermissionReportBranding:    true,
+	PermissionAnalyticsRead:     true,
+	PermissionPresenceRead:      true,
+	PermissionWebhookManage:     true,
+	PermissionReportingManage:   true,
+	PermissionLeaveChainManage:  true,
+}
+
+// where a route carries the id of a resource
+const (
+	SourceParam = "param"
+	SourceQuery = "query"
+	SourceBody  = "body"
+)
+
+// context key of the claims of the caller
+const AuthClaimsKey = "auth_claims"
+
+// the caller of a request, subject is the admin id of an admin token and the user id of a user
+// token. admin id is the organization of the caller for both.
+type AuthClaims struct {
+	Subject   string
+	Role      string
+	AdminId   string
+	ExpiresAt time.Time
+}
+
+// the id named Name in the Source of the request must belong to the caller
+type AuthRule struct {
+	Source   string
+	Name     string
+	Resource string
+}
+
+// category id is the category of the resource or of its user, nil for organization wide
+// resources
+type ResourceOwner struct {
+	AdminId    string
+	UserId     *string
+	CategoryId *string
+}
+
+// a permission a user holds through roles. a category scoped grant only covers the resources of
+// the categories the user manages.
+type PermissionGrant struct {
+	CategoryScoped     bool
+	ManagedCategoryIds []string
+}
+
+// the admin of a token subject, access tokens of a user issued up to sessions revoked at are
+// refused
+type TokenOwner struct {
+	AdminId           string
+	SessionsRevokedAt *time.Time
+}
+
+type AuthorizationInterface interface {
+	GetResourceOwner(resource string, resourceId string) (*ResourceOwner, error)
+	GetTokenOwner(role string, subjectId string) (*TokenOwner, error)
+	GetUserPermissionGrant(userId string, permission string) (*PermissionGrant, error)
+}
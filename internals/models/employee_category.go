@@ -19,10 +19,33 @@ type EmployeeCategoryResponse struct {
 	CategoryDescription string `json:"category_description"`
 }
 
+// a shift ending at or before its start time crosses midnight, e.g. 22:00 to 06:00
+type CategoryAttendanceSettingsRequest struct {
+	CategoryId            string `json:"category_id" validate:"required"`
+	ShiftStartTime        string `json:"shift_start_time" validate:"required,time"`
+	ShiftEndTime          string `json:"shift_end_time" validate:"required,time"`
+	GraceMinutes          int32  `json:"grace_minutes" validate:"gte=0,lte=240"`
+	BreakMinutes          int32  `json:"break_minutes" validate:"gte=0"`
+	AutoCloseAfterMinutes int32  `json:"auto_close_after_minutes" validate:"required,gt=0"`
+	AutoClosePolicy       string `json:"auto_close_policy" validate:"required,oneof=end_of_shift last_activity admin_review"`
+}
+
+type CategoryAttendanceSettingsResponse struct {
+	CategoryId            string `json:"category_id"`
+	ShiftStartTime        string `json:"shift_start_time"`
+	ShiftEndTime          string `json:"shift_end_time"`
+	GraceMinutes          int32  `json:"grace_minutes"`
+	BreakMinutes          int32  `json:"break_minutes"`
+	AutoCloseAfterMinutes int32  `json:"auto_close_after_minutes"`
+	AutoClosePolicy       string `json:"auto_close_policy"`
+}
+
 type CategoryInterface interface {
 	CheckEmployeeCategoryExists(adminId string, categoryName string) (bool, error)
 	CreateEmployeeCategory(category *EmployeeCategory) error
 	GetEmployeeCategories(adminId string) ([]*EmployeeCategoryResponse, error)
 	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
 	DeleteEmployeeCategory(categoryId string) error
+	UpsertCategoryAttendanceSettings(settings *CategoryAttendanceSettingsRequest) error
+	GetCategoryAttendanceSettings(categoryId string) (*CategoryAttendanceSettingsResponse, error)
 }
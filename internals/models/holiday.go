@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// holidays without a category apply to every category of the admin
+type CreateHolidayRequest struct {
+	AdminId     string `json:"admin_id" validate:"required"`
+	CategoryId  string `json:"category_id"`
+	HolidayDate string `json:"holiday_date" validate:"required,date"`
+	HolidayName string `json:"holiday_name" validate:"required"`
+}
+
+type Holiday struct {
+	HolidayId   string
+	AdminId     string
+	CategoryId  *string
+	HolidayDate string
+	HolidayName string
+	Source      string
+	IcsUid      *string
+}
+
+type HolidayResponse struct {
+	HolidayId    string    `json:"holiday_id"`
+	CategoryId   *string   `json:"category_id"`
+	CategoryName *string   `json:"category_name"`
+	HolidayDate  string    `json:"holiday_date"`
+	HolidayName  string    `json:"holiday_name"`
+	Source       string    `json:"source"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IcsHoliday is one date of an all day event read from an iCalendar file
+type IcsHoliday struct {
+	Uid  string
+	Date string
+	Name string
+}
+
+type HolidayImportResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// weekday follows time.Weekday, sunday is 0. week numbers are the occurrences of the weekday
+// within the month, e.g. [2,4] for alternate saturdays, and are left empty for every week.
+type WeeklyOffRuleRequest struct {
+	AdminId     string  `json:"admin_id" validate:"required"`
+	CategoryId  string  `json:"category_id"`
+	Weekday     *int    `json:"weekday" validate:"required,gte=0,lte=6"`
+	WeekNumbers []int32 `json:"week_numbers" validate:"omitempty,unique,dive,gte=1,lte=5"`
+}
+
+type WeeklyOffRule struct {
+	RuleId      string
+	AdminId     string
+	CategoryId  *string
+	Weekday     int
+	WeekNumbers []int32
+}
+
+type WeeklyOffRuleResponse struct {
+	RuleId       string    `json:"rule_id"`
+	CategoryId   *string   `json:"category_id"`
+	CategoryName *string   `json:"category_name"`
+	Weekday      int       `json:"weekday"`
+	WeekNumbers  []int32   `json:"week_numbers"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WorkCalendar holds the holidays, keyed by "2006-01-02" date, and the weekly offs that apply
+// to one user
+type WorkCalendar struct {
+	Holidays   map[string]string
+	WeeklyOffs []*WeeklyOffRule
+}
+
+type HolidayInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	CheckHolidayExists(adminId string, categoryId *string, holidayDate string) (bool, error)
+	CreateHoliday(holiday *Holiday) error
+	ImportHolidays(holidays []*Holiday) (int, error)
+	GetHolidays(adminId string, year string) ([]*HolidayResponse, error)
+	CheckHolidayIdExists(holidayId string) (bool, error)
+	DeleteHoliday(holidayId string) error
+	UpsertWeeklyOffRule(rule *WeeklyOffRule) error
+	GetWeeklyOffRules(adminId string) ([]*WeeklyOffRuleResponse, error)
+	CheckWeeklyOffRuleIdExists(ruleId string) (bool, error)
+	DeleteWeeklyOffRule(ruleId string) error
+	CheckUserIdExists(userId string) (bool, error)
+	GetUserHolidays(userId string, year string) ([]*HolidayResponse, error)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ReportVerification is printed on a report, as text and as a qr code of the verify url
+type ReportVerification struct {
+	ReportId  string
+	VerifyUrl string
+}
+
+type IssuedReport struct {
+	ReportId     string
+	UserId       string
+	UserName     string
+	CategoryName string
+	StartDate    string
+	EndDate      string
+	Sha256       string
+	Signature    string
+	IssuedAt     time.Time
+}
+
+// the signature is the ed25519 signature of the signed payload, base64 encoded, and can be
+// checked offline with the public key. hash matches is only set when a hash was given.
+type ReportVerificationResponse struct {
+	ReportId      string    `json:"report_id"`
+	UserName      string    `json:"user_name"`
+	CategoryName  string    `json:"category_name"`
+	StartDate     string    `json:"start_date"`
+	EndDate       string    `json:"end_date"`
+	IssuedAt      time.Time `json:"issued_at"`
+	Sha256        string    `json:"sha256"`
+	HashMatches   *bool     `json:"hash_matches,omitempty"`
+	SignedPayload string    `json:"signed_payload"`
+	Signature     string    `json:"signature"`
+	PublicKey     string    `json:"public_key"`
+}
+
+type IssuedReportInterface interface {
+	CreateIssuedReport(report *IssuedReport) error
+	GetIssuedReport(reportId string) (*IssuedReport, error)
+	GetUserCategoryName(userId string) (string, error)
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// approvers of a leave approval step
+const (
+	ApproverReportingManager = "reporting_manager"
+	ApproverAdmin            = "admin"
+)
+
+// results of a decision on the current approval step of a leave
+const (
+	LeaveDecisionConflict            = "conflict"
+	LeaveDecisionInsufficientBalance = "insufficient_balance"
+	LeaveDecisionApproved            = "approved"
+	LeaveDecisionGranted             = "granted"
+	LeaveDecisionRejected            = "rejected"
+)
+
+// an empty manager id removes the reporting manager of the user
+type UpdateReportingManagerRequest struct {
+	UserId    string `json:"user_id" validate:"required"`
+	ManagerId string `json:"manager_id"`
+}
+
+// a chain without category id is the default of the admin
+type UpsertLeaveApprovalChainRequest struct {
+	AdminId    string   `json:"admin_id" validate:"required"`
+	CategoryId string   `json:"category_id"`
+	Steps      []string `json:"steps" validate:"required,min=1,max=2,unique,dive,oneof=reporting_manager admin"`
+}
+
+type LeaveApprovalChain struct {
+	ChainId    string
+	AdminId    string
+	CategoryId *string
+	Steps      []string
+}
+
+type LeaveApprovalChainResponse struct {
+	ChainId    string    `json:"chain_id"`
+	CategoryId *string   `json:"category_id"`
+	Steps      []string  `json:"steps"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type LeaveDecisionRequest struct {
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+type LeaveApprovalStep struct {
+	LeaveId        string
+	StepNumber     int16
+	ApproverType   string
+	ApproverUserId *string
+}
+
+type LeaveDecision struct {
+	Approved      bool
+	DecidedBy     string
+	DecidedByType string
+	Comment       *string
+}
+
+type LeaveApprovalStepResponse struct {
+	StepNumber     int16      `json:"step_number"`
+	ApproverType   string     `json:"approver_type"`
+	ApproverUserId *string    `json:"approver_user_id"`
+	ApproverName   *string    `json:"approver_name"`
+	Status         string     `json:"status"`
+	DecidedBy      *string    `json:"decided_by"`
+	DecidedByType  *string    `json:"decided_by_type"`
+	DecidedAt      *time.Time `json:"decided_at"`
+	Comment        *string    `json:"comment"`
+}
+
+type LeaveApprovalInterface interface {
+	CheckUserIdExists(userId string) (bool, error)
+	GetAdminIdByUserId(userId string) (string, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	CheckLeaveIdExists(leaveId string) (bool, error)
+	GetUserLeave(leaveId string) (*UserLeave, error)
+	GetUserLeaveBalance(userId string, leaveTypeId string) (*LeaveBalanceResponse, error)
+	CheckReportingLineCycle(userId string, managerId string) (bool, error)
+	SetUserReportingManager(userId string, managerId *string) error
+	UpsertLeaveApprovalChain(chain *LeaveApprovalChain) (string, error)
+	GetLeaveApprovalChains(adminId string) ([]*LeaveApprovalChainResponse, error)
+	CheckLeaveApprovalChainIdExists(chainId string) (bool, error)
+	DeleteLeaveApprovalChain(chainId string) error
+	GetCurrentLeaveApprovalStep(leaveId string) (*LeaveApprovalStep, bool, error)
+	DecideLeaveApprovalStep(step *LeaveApprovalStep, decision *LeaveDecision) (string, error)
+	GetLeaveApprovalSteps(leaveId string) ([]*LeaveApprovalStepResponse, error)
+	GetManagerPendingLeavesCount(managerId string) (int, error)
+	GetManagerPendingLeaves(managerId string, limit uint32, offset uint32) ([]*UserPendingLeaveResponse, error)
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+type CreateLeaveTypeRequest struct {
+	AdminId              string  `json:"admin_id" validate:"required"`
+	CategoryId           string  `json:"category_id" validate:"required"`
+	LeaveName            string  `json:"leave_name" validate:"required"`
+	AnnualQuota          float64 `json:"annual_quota" validate:"gte=0,lte=366"`
+	AccrualFrequency     string  `json:"accrual_frequency" validate:"omitempty,oneof=yearly monthly"`
+	CarryForwardLimit    float64 `json:"carry_forward_limit" validate:"gte=0,lte=366"`
+	AllowNegativeBalance bool    `json:"allow_negative_balance"`
+}
+
+type LeaveType struct {
+	LeaveTypeId          string
+	AdminId              string
+	CategoryId           string
+	LeaveName            string
+	AnnualQuota          float64
+	AccrualFrequency     string
+	CarryForwardLimit    float64
+	AllowNegativeBalance bool
+}
+
+type LeaveTypeResponse struct {
+	LeaveTypeId          string    `json:"leave_type_id"`
+	CategoryId           string    `json:"category_id"`
+	LeaveName            string    `json:"leave_name"`
+	AnnualQuota          float64   `json:"annual_quota"`
+	AccrualFrequency     string    `json:"accrual_frequency"`
+	CarryForwardLimit    float64   `json:"carry_forward_limit"`
+	AllowNegativeBalance bool      `json:"allow_negative_balance"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// days is signed, a positive value credits the balance and a negative value debits it
+type LeaveBalanceAdjustRequest struct {
+	UserId      string  `json:"user_id" validate:"required"`
+	LeaveTypeId string  `json:"leave_type_id" validate:"required"`
+	Days        float64 `json:"days" validate:"required,gte=-366,lte=366"`
+	Note        string  `json:"note" validate:"required"`
+}
+
+type LeaveLedgerEntry struct {
+	EntryId     string
+	UserId      string
+	LeaveTypeId string
+	EntryType   string
+	Days        float64
+	Note        string
+}
+
+// available days are the balance minus the days already requested by pending leaves
+type LeaveBalanceResponse struct {
+	LeaveTypeId          string  `json:"leave_type_id"`
+	LeaveName            string  `json:"leave_name"`
+	AnnualQuota          float64 `json:"annual_quota"`
+	AccrualFrequency     string  `json:"accrual_frequency"`
+	AllowNegativeBalance bool    `json:"allow_negative_balance"`
+	Balance              float64 `json:"balance"`
+	PendingDays          float64 `json:"pending_days"`
+	AvailableDays        float64 `json:"available_days"`
+}
+
+type LeaveLedgerResponse struct {
+	EntryId       string    `json:"entry_id"`
+	LeaveTypeId   string    `json:"leave_type_id"`
+	LeaveName     string    `json:"leave_name"`
+	EntryType     string    `json:"entry_type"`
+	Days          float64   `json:"days"`
+	LeaveId       *string   `json:"leave_id"`
+	AccrualPeriod *string   `json:"accrual_period"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type LeaveTypeInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	CheckLeaveTypeExists(categoryId string, leaveName string) (bool, error)
+	CreateLeaveType(leaveType *LeaveType) error
+	GetLeaveTypes(categoryId string) ([]*LeaveTypeResponse, error)
+	CheckLeaveTypeIdExists(leaveTypeId string) (bool, error)
+	DeleteLeaveType(leaveTypeId string) error
+	AccrueLeaveBalances(year string, month string) (int64, error)
+	CheckUserIdExists(userId string) (bool, error)
+	GetUserLeaveBalance(userId string, leaveTypeId string) (*LeaveBalanceResponse, error)
+	GetUserLeaveBalances(userId string) ([]*LeaveBalanceResponse, error)
+	AdjustLeaveBalance(entry *LeaveLedgerEntry) error
+	GetUserLeaveLedgerCount(userId string) (int, error)
+	GetUserLeaveLedger(userId string, limit uint32, offset uint32) ([]*LeaveLedgerResponse, error)
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// scopes of the failed attempt counters, the key of an account or otp scope is the email and
+// the key of the ip scope is the client ip
+const (
+	LoginScopeAdminAccount = "admin_account"
+	LoginScopeUserAccount  = "user_account"
+	LoginScopeAdminOtp     = "admin_otp"
+	LoginScopeUserOtp      = "user_otp"
+	LoginScopeIp           = "ip"
+)
+
+type LoginAttempt struct {
+	Failures    int32
+	LockedUntil *time.Time
+}
+
+// data is the account email, the client ip, the reason and, for a lockout, when it ends
+type LoginAlertEmailFormat struct {
+	To        string            `json:"to"`
+	Subject   string            `json:"subject"`
+	EmailType string            `json:"email_type"`
+	Data      map[string]string `json:"data"`
+}
+
+// LoginAttemptStoreInterface keeps the failed attempt counters. the store must be shared by
+// every replica of the server, otherwise each replica allows its own attempts.
+type LoginAttemptStoreInterface interface {
+	GetLoginAttempt(scope string, key string) (*LoginAttempt, error)
+	RecordLoginFailure(scope string, key string, resetAfter time.Duration) (*LoginAttempt, error)
+	LockLoginAttempt(scope string, key string, lockedUntil time.Time) error
+	ClearLoginAttempt(scope string, key string) error
+	DeleteStaleLoginAttempts(lastFailureBefore time.Time) error
+}
+
+type LoginThrottleInterface interface {
+	GetLoginAlertRecipient(scope string, email string) (string, error)
+}
+
+type LoginAlertEmailServiceInterface interface {
+	SendEmail(data []byte) error
+}
@@ -0,0 +1,43 @@
+package models
+
+type MusterRollDownloadRequest struct {
+	AdminId    string `query:"admin_id" validate:"required"`
+	Month      string `query:"month" validate:"required"`
+	CategoryId string `query:"category_id"`
+	Format     string `query:"format" validate:"omitempty,oneof=xlsx csv"`
+}
+
+type MusterRollEmployee struct {
+	UserId         string
+	Name           string
+	CategoryName   string
+	CreatedDate    string
+	ShiftStartTime string
+	ShiftEndTime   string
+	BreakMinutes   int
+}
+
+// codes holds one status code per day of the month, empty for days before the employee was
+// created or not finished yet. a half day counts half present and half absent.
+type MusterRollRow struct {
+	Name            string
+	CategoryName    string
+	Codes           []string
+	PresentDays     float64
+	AbsentDays      float64
+	LeaveDays       float64
+	OvertimeMinutes int
+}
+
+type MusterRoll struct {
+	Month string
+	Days  int
+	Rows  []*MusterRollRow
+}
+
+type MusterRollInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	GetMusterRollEmployees(adminId string, categoryId string) ([]*MusterRollEmployee, error)
+	GetMusterRollOvertime(adminId string, categoryId string, fromDate string, toDate string) (map[string]int, error)
+}
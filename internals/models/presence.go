@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// event type is punch_in, punch_out, leave_applied or leave_granted. admin id and user name are
+// filled in by the database when the event is published.
+type PresenceEvent struct {
+	EventType  string    `json:"event_type"`
+	AdminId    string    `json:"admin_id"`
+	UserId     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	SessionId  string    `json:"session_id,omitempty"`
+	Latitude   string    `json:"latitude,omitempty"`
+	Longitude  string    `json:"longitude,omitempty"`
+	LeaveId    string    `json:"leave_id,omitempty"`
+	LeaveFrom  string    `json:"leave_from,omitempty"`
+	LeaveTo    string    `json:"leave_to,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type PresenceInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	PublishPresenceEvent(event []byte) error
+	ListenPresenceEvents(handler func(payload []byte)) error
+}
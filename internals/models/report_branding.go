@@ -0,0 +1,62 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// colors are #RRGGBB, the primary color is used for the company name and the table headings
+// and the accent color for the borders and rules
+type ReportBrandingRequest struct {
+	AdminId      string `json:"admin_id" validate:"required"`
+	CompanyName  string `json:"company_name" validate:"required,max=100"`
+	Address      string `json:"address" validate:"max=150"`
+	PhoneNumber  string `json:"phone_number" validate:"max=30"`
+	Email        string `json:"email" validate:"omitempty,email,max=100"`
+	Website      string `json:"website" validate:"max=100"`
+	PrimaryColor string `json:"primary_color" validate:"omitempty,len=7,hexcolor"`
+	AccentColor  string `json:"accent_color" validate:"omitempty,len=7,hexcolor"`
+	FooterText   string `json:"footer_text" validate:"max=120"`
+}
+
+// logo holds the image bytes while a report is rendered, it is not stored in the database
+type ReportBranding struct {
+	AdminId      string
+	CompanyName  string
+	Address      string
+	PhoneNumber  string
+	Email        string
+	Website      string
+	PrimaryColor string
+	AccentColor  string
+	FooterText   string
+	LogoKey      *string
+	Logo         []byte
+}
+
+type ReportBrandingResponse struct {
+	CompanyName  string    `json:"company_name"`
+	Address      string    `json:"address"`
+	PhoneNumber  string    `json:"phone_number"`
+	Email        string    `json:"email"`
+	Website      string    `json:"website"`
+	PrimaryColor string    `json:"primary_color"`
+	AccentColor  string    `json:"accent_color"`
+	FooterText   string    `json:"footer_text"`
+	HasLogo      bool      `json:"has_logo"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ReportBrandingInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	UpsertReportBranding(branding *ReportBranding) error
+	GetReportBranding(adminId string) (*ReportBrandingResponse, *string, error)
+	GetUserReportBranding(userId string) (*ReportBranding, error)
+	UpdateReportBrandingLogo(adminId string, logoKey *string) error
+}
+
+type ReportBrandingStorageInterface interface {
+	UploadReportLogo(fileName string, file io.ReadSeeker) error
+	DeleteReportLogo(fileName string) error
+	GetReportLogo(fileName string) ([]byte, error)
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// reports are rendered for every user of the admin, or only the users of the category when
+// one is given
+type CreateReportJobRequest struct {
+	AdminId    string `json:"admin_id" validate:"required"`
+	CategoryId string `json:"category_id"`
+	StartDate  string `json:"start_date" validate:"required,date"`
+	EndDate    string `json:"end_date" validate:"required,date"`
+}
+
+type ReportJob struct {
+	JobId      string
+	AdminId    string
+	CategoryId *string
+	StartDate  string
+	EndDate    string
+	Attempts   int
+}
+
+type ReportJobItem struct {
+	UserId   string
+	UserName string
+}
+
+type ReportJobFailure struct {
+	UserId    string  `json:"user_id"`
+	UserName  string  `json:"user_name"`
+	Attempts  int32   `json:"attempts"`
+	LastError *string `json:"last_error"`
+}
+
+type ReportJobResponse struct {
+	JobId         string              `json:"job_id"`
+	CategoryId    *string             `json:"category_id"`
+	StartDate     string              `json:"start_date"`
+	EndDate       string              `json:"end_date"`
+	Status        string              `json:"status"`
+	TotalUsers    int32               `json:"total_users"`
+	RenderedUsers int32               `json:"rendered_users"`
+	FailedUsers   int32               `json:"failed_users"`
+	Error         *string             `json:"error"`
+	Failures      []*ReportJobFailure `json:"failures,omitempty"`
+	DownloadUrl   *string             `json:"download_url,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	StartedAt     *time.Time          `json:"started_at"`
+	CompletedAt   *time.Time          `json:"completed_at"`
+	ObjectKey     *string             `json:"-"`
+}
+
+type ReportJobInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	CreateReportJob(job *ReportJob) (int, error)
+	ClaimReportJob(staleAfter time.Duration, maxAttempts int) (*ReportJob, error)
+	GetReportJobItems(jobId string) ([]*ReportJobItem, error)
+	UpdateReportJobItem(jobId string, userId string, status string, attempts int, lastError *string) error
+	CompleteReportJob(jobId string, status string, objectKey *string, jobError *string) error
+	GetReportJob(jobId string) (*ReportJobResponse, error)
+	GetReportJobFailures(jobId string) ([]*ReportJobFailure, error)
+	GetReportJobsCount(adminId string) (int32, error)
+	GetReportJobs(adminId string, limit uint32, offset uint32) ([]*ReportJobResponse, error)
+}
+
+type ReportJobStorageInterface interface {
+	UploadReportArchive(fileName string, file io.ReadSeeker) error
+	GetReportArchiveUrl(fileName string, expiry time.Duration) (string, error)
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+type CreateRoleRequest struct {
+	AdminId        string   `json:"admin_id" validate:"required"`
+	RoleName       string   `json:"role_name" validate:"required,max=255"`
+	Permissions    []string `json:"permissions" validate:"required,min=1,unique"`
+	CategoryScoped bool     `json:"category_scoped"`
+}
+
+type UpdateRoleRequest struct {
+	RoleId         string   `json:"role_id" validate:"required"`
+	RoleName       string   `json:"role_name" validate:"required,max=255"`
+	Permissions    []string `json:"permissions" validate:"required,min=1,unique"`
+	CategoryScoped bool     `json:"category_scoped"`
+}
+
+type Role struct {
+	RoleId         string
+	AdminId        string
+	RoleName       string
+	Permissions    []string
+	CategoryScoped bool
+}
+
+type RoleResponse struct {
+	RoleId         string    `json:"role_id"`
+	RoleName       string    `json:"role_name"`
+	Permissions    []string  `json:"permissions"`
+	CategoryScoped bool      `json:"category_scoped"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AssignUserRoleRequest struct {
+	UserId string `json:"user_id" validate:"required"`
+	RoleId string `json:"role_id" validate:"required"`
+}
+
+// an empty list of category ids clears the categories the user manages
+type UpdateUserManagedCategoriesRequest struct {
+	UserId      string   `json:"user_id" validate:"required"`
+	CategoryIds []string `json:"category_ids" validate:"unique,dive,required"`
+}
+
+type UserRolesResponse struct {
+	Roles              []*RoleResponse `json:"roles"`
+	ManagedCategoryIds []string        `json:"managed_category_ids"`
+}
+
+type RoleInterface interface {
+	CheckUserIdExists(userId string) (bool, error)
+	GetAdminIdByUserId(userId string) (string, error)
+	CheckRoleNameExists(adminId string, roleName string, roleId string) (bool, error)
+	CreateRole(role *Role) error
+	GetRoles(adminId string) ([]*RoleResponse, error)
+	GetRole(roleId string) (*Role, error)
+	UpdateRole(role *Role) error
+	DeleteRole(roleId string) error
+	AssignUserRole(userId string, roleId string) error
+	RemoveUserRole(userId string, roleId string) (bool, error)
+	CheckAdminCategoriesExist(adminId string, categoryIds []string) (bool, error)
+	SetUserManagedCategories(userId string, categoryIds []string) error
+	GetUserRoles(userId string) ([]*RoleResponse, error)
+	GetUserManagedCategoryIds(userId string) ([]string, error)
+}
@@ -30,17 +30,18 @@ type CreateUserRequest struct {
 }
 
 type UserProfileDetailsResponse struct {
-	Name         string    `json:"name"`
-	Dob          string    `json:"dob"`
-	Email        string    `json:"email"`
-	PhoneNumber  string    `json:"phone_number"`
-	ProfileUrl   string    `json:"profile_url"`
-	CategoryId   string    `json:"category_id"`
-	CategoryName string    `json:"category_name"`
-	LoginStatus  bool      `json:"login_status"`
-	Latitude     string    `json:"latitude"`
-	Longitude    string    `json:"longitude"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	Name               string    `json:"name"`
+	Dob                string    `json:"dob"`
+	Email              string    `json:"email"`
+	PhoneNumber        string    `json:"phone_number"`
+	ProfileUrl         string    `json:"profile_url"`
+	CategoryId         string    `json:"category_id"`
+	CategoryName       string    `json:"category_name"`
+	LoginStatus        bool      `json:"login_status"`
+	Latitude           string    `json:"latitude"`
+	Longitude          string    `json:"longitude"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	ReportingManagerId *string   `json:"reporting_manager_id"`
 }
 
 type UserResponse struct {
@@ -63,37 +64,65 @@ type UserLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-type UserLoginResponse struct {
-	Token string `json:"token"`
-}
-
+// date and time are the device clock, the punch itself is stamped with the server time
 type UserWorkLoginRequest struct {
 	UserId    string `json:"user_id" validate:"required"`
-	LoginDate string `json:"date" validate:"required,date"`
-	LoginTime string `json:"time" validate:"required,time"`
+	LoginDate string `json:"date" validate:"required_with=LoginTime,omitempty,date"`
+	LoginTime string `json:"time" validate:"required_with=LoginDate,omitempty,time"`
 	Latitude  string `json:"latitude" validate:"required,latitude"`
 	Longitude string `json:"longitude" validate:"required,longitude"`
 }
 
+type WorkPunch struct {
+	ServerAt         time.Time
+	ClientAt         *time.Time
+	ClockSkewSeconds *int32
+	ClockSkewFlagged bool
+}
+
 type UserWorkHistory struct {
+	SessionId    string
 	UserId       string
 	WorkDate     string
-	LoginTime    string
-	LogoutTime   string
 	Latitude     string
 	Longitude    string
 	UploadedWork string
+	Punch        *WorkPunch
+	Geofence     *GeofenceResult
 }
 
 type UserWorkHistoryResponse struct {
-	Name         string    `json:"name"`
-	WorkDate     string    `json:"work_date"`
-	LoginTime    string    `json:"login_time"`
-	LogoutTime   string    `json:"logout_time"`
-	Latitude     string    `json:"latitude"`
-	Longitude    string    `json:"longitude"`
-	UploadedWork string    `json:"uploaded_work"`
-	TimeStamp    time.Time `json:"timestamp"`
+	SessionId                string     `json:"session_id"`
+	Name                     string     `json:"name"`
+	WorkDate                 string     `json:"work_date"`
+	LoginTime                string     `json:"login_time"`
+	LogoutTime               string     `json:"logout_time"`
+	LoginAt                  time.Time  `json:"login_at"`
+	LogoutAt                 *time.Time `json:"logout_at"`
+	ClientLoginAt            *time.Time `json:"client_login_at"`
+	ClientLogoutAt           *time.Time `json:"client_logout_at"`
+	ClockSkewFlagged         bool       `json:"clock_skew_flagged"`
+	DayTotalHours            string     `json:"day_total_hours"`
+	ShiftStartAt             time.Time  `json:"shift_start_at"`
+	LateArrivalMinutes       int32      `json:"late_arrival_minutes"`
+	EarlyDepartureMinutes    *int32     `json:"early_departure_minutes"`
+	OvertimeMinutes          *int32     `json:"overtime_minutes"`
+	NetWorkedHours           *string    `json:"net_worked_hours"`
+	Latitude                 string     `json:"latitude"`
+	Longitude                string     `json:"longitude"`
+	SiteId                   *string    `json:"site_id"`
+	SiteName                 *string    `json:"site_name"`
+	SiteDistanceMeters       *float64   `json:"site_distance_meters"`
+	OutsideGeofence          bool       `json:"outside_geofence"`
+	LogoutLatitude           *string    `json:"logout_latitude"`
+	LogoutLongitude          *string    `json:"logout_longitude"`
+	LogoutSiteId             *string    `json:"logout_site_id"`
+	LogoutSiteDistanceMeters *float64   `json:"logout_site_distance_meters"`
+	LogoutOutsideGeofence    bool       `json:"logout_outside_geofence"`
+	UploadedWork             string     `json:"uploaded_work"`
+	PartialLeave             *string    `json:"partial_leave"`
+	PartialLeaveHours        *string    `json:"partial_leave_hours"`
+	TimeStamp                time.Time  `json:"timestamp"`
 }
 
 type UserReportPdfDownloadRequest struct {
@@ -102,64 +131,136 @@ type UserReportPdfDownloadRequest struct {
 	EndDate   string `query:"end_date" validate:"required,date"`
 }
 
+// ShiftSession is a work session placed on the shift of the user's category, shifts crossing
+// midnight start on the evening before
+type ShiftSession struct {
+	LoginAt      time.Time
+	LogoutAt     *time.Time
+	ShiftStartAt time.Time
+	ShiftMinutes int
+	GraceMinutes int
+	BreakMinutes int
+	FirstSession bool
+	LastSession  bool
+}
+
+// early departure, overtime and net worked minutes are nil while the session is open
+type ShiftSessionMetrics struct {
+	LateMinutes           int
+	EarlyDepartureMinutes *int
+	OvertimeMinutes       *int
+	NetWorkedMinutes      *int
+}
+
+// the minutes are measured from the punch timestamps, so sessions crossing midnight count fully
 type UserWorkHistoryForPdf struct {
-	Date        string
-	WorkSummary string
-	LoginTime   string
-	LogoutTime  string
+	Date                  string
+	WorkSummary           string
+	LoginTime             string
+	LogoutTime            string
+	WorkHours             string
+	WorkedMinutes         int
+	LateMinutes           int
+	EarlyDepartureMinutes int
+	OvertimeMinutes       int
+	NetWorkedMinutes      int
+	PartialLeave          string
+	PartialLeaveHours     string
+	DayNote               string
 }
 
+// branding is the letterhead of the user's admin, nil for the default letterhead. verification
+// is set when the report is issued.
 type UserReportPdf struct {
-	Name     string
-	Position string
-	History  []*UserWorkHistoryForPdf
+	Name         string
+	Position     string
+	History      []*UserWorkHistoryForPdf
+	Branding     *ReportBranding
+	Verification *ReportVerification
+	GeneratedAt  time.Time
 }
 
+// date and time are the device clock, the punch itself is stamped with the server time
 type UserWorkLogoutRequest struct {
 	UserId     string `json:"user_id" validate:"required"`
-	LogoutDate string `json:"date" validate:"required,date"`
-	LogoutTime string `json:"time" validate:"required,time"`
+	SessionId  string `json:"session_id"`
+	LogoutDate string `json:"date" validate:"required_with=LogoutTime,omitempty,date"`
+	LogoutTime string `json:"time" validate:"required_with=LogoutDate,omitempty,time"`
 	Work       string `json:"work" validate:"required"`
+	Latitude   string `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude  string `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
 }
 
+// partial day leaves start and end on the same date, start and end time are only sent for hours
 type UserLeaveRequest struct {
-	UserId      string `json:"user_id" validate:"required"`
-	LeaveFrom   string `json:"leave_from" validate:"required,date"`
-	LeaveTo     string `json:"leave_to" validate:"required,date"`
-	LeaveReason string `json:"leave_reason" validate:"required"`
+	UserId           string `json:"user_id" validate:"required"`
+	LeaveFrom        string `json:"leave_from" validate:"required,date"`
+	LeaveTo          string `json:"leave_to" validate:"required,date"`
+	LeaveReason      string `json:"leave_reason" validate:"required"`
+	LeaveTypeId      string `json:"leave_type_id"`
+	LeaveGranularity string `json:"leave_granularity" validate:"omitempty,oneof=full_day first_half second_half hours"`
+	StartTime        string `json:"start_time" validate:"required_if=LeaveGranularity hours,excluded_unless=LeaveGranularity hours,omitempty,time"`
+	EndTime          string `json:"end_time" validate:"required_if=LeaveGranularity hours,excluded_unless=LeaveGranularity hours,omitempty,time"`
 }
 
+// results of applying a leave
+const (
+	LeaveApplyApplied             = "applied"
+	LeaveApplyConflict            = "conflict"
+	LeaveApplyInsufficientBalance = "insufficient_balance"
+)
+
 type UserLeave struct {
 	LeaveId              string
 	UserId               string
+	LeaveTypeId          *string
 	LeaveFrom            string
 	LeaveTo              string
+	LeaveGranularity     string
+	LeaveStartTime       *string
+	LeaveEndTime         *string
+	LeaveMinutes         *int32
+	LeaveDays            float64
 	LeaveReason          string
 	LeaveStatus          string
 	LeaveStatusUpdatedBy string
 }
 
 type UserLeaveResponse struct {
-	LeaveId                string `json:"leave_id"`
-	LeaveFrom              string `json:"leave_from"`
-	LeaveTo                string `json:"leave_to"`
-	LeaveReason            string `json:"leave_reason"`
-	LeaveStatus            string `json:"leave_status"`
-	LeaveStatusUpdatedBy   string `json:"leave_status_updated_by"`
-	LeaveStatusUpdatedDate string `json:"leave_status_updated_date"`
-	LeaveStatusUpdtedTime  string `json:"leave_status_updated_time"`
+	LeaveId                string  `json:"leave_id"`
+	LeaveTypeId            *string `json:"leave_type_id"`
+	LeaveTypeName          *string `json:"leave_type_name"`
+	LeaveFrom              string  `json:"leave_from"`
+	LeaveTo                string  `json:"leave_to"`
+	LeaveGranularity       string  `json:"leave_granularity"`
+	LeaveStartTime         *string `json:"leave_start_time"`
+	LeaveEndTime           *string `json:"leave_end_time"`
+	LeaveDays              float64 `json:"leave_days"`
+	LeaveReason            string  `json:"leave_reason"`
+	LeaveStatus            string  `json:"leave_status"`
+	LeaveStatusUpdatedBy   string  `json:"leave_status_updated_by"`
+	LeaveStatusUpdatedDate string  `json:"leave_status_updated_date"`
+	LeaveStatusUpdtedTime  string  `json:"leave_status_updated_time"`
 }
 
 type UserPendingLeaveResponse struct {
-	UserId         string    `json:"user_id"`
-	UserName       string    `json:"user_name"`
-	UserEmail      string    `json:"user_email"`
-	UserCategory   string    `json:"user_category"`
-	LeaveId        string    `json:"leave_id"`
-	LeaveFrom      string    `json:"leave_from"`
-	LeaveTo        string    `json:"leave_to"`
-	LeaveReason    string    `json:"leave_reason"`
-	LeaveCreatedAt time.Time `json:"leave_created_at"`
+	UserId           string    `json:"user_id"`
+	UserName         string    `json:"user_name"`
+	UserEmail        string    `json:"user_email"`
+	UserCategory     string    `json:"user_category"`
+	LeaveId          string    `json:"leave_id"`
+	LeaveTypeId      *string   `json:"leave_type_id"`
+	LeaveTypeName    *string   `json:"leave_type_name"`
+	LeaveFrom        string    `json:"leave_from"`
+	LeaveTo          string    `json:"leave_to"`
+	LeaveGranularity string    `json:"leave_granularity"`
+	LeaveStartTime   *string   `json:"leave_start_time"`
+	LeaveEndTime     *string   `json:"leave_end_time"`
+	LeaveDays        float64   `json:"leave_days"`
+	LeaveReason      string    `json:"leave_reason"`
+	LeaveCreatedAt   time.Time `json:"leave_created_at"`
+	ApprovalStep     int16     `json:"approval_step"`
+	ApprovalSteps    int16     `json:"approval_steps"`
 }
 
 type UserProfileInfoUpdateRequest struct {
@@ -221,20 +322,28 @@ type UserDatabaseInterface interface {
 	CheckUserIdExists(userId string) (bool, error)
 	DeleteUser(userId string) error
 	GetUserForLogin(email string) (string, string, string, error)
-	CheckUserWorkEntryExists(userId string, date string) (bool, error)
-	UserWorkLogin(userWorkHistory *UserWorkHistory) error
-	CheckUserWorkLoginEntryExists(userId string, date string) (bool, error)
-	UserWorkLogout(userWorkLogoutRequest *UserWorkLogoutRequest) error
-	CheckUserPendingLeaveExists(userId string) (bool, error)
-	ApplyUserLeave(userLeave *UserLeave) error
+	CheckUserOpenWorkSessionExists(userId string) (bool, error)
+	CheckUserWorkSessionOverlap(userId string, loginAt time.Time) (bool, error)
+	UserWorkLogin(userWorkHistory *UserWorkHistory) (bool, error)
+	GetUserOpenWorkSessionId(userId string) (string, error)
+	CheckUserOpenWorkSessionIdExists(userId string, sessionId string) (bool, error)
+	UserWorkLogout(userWorkLogoutRequest *UserWorkLogoutRequest, punch *WorkPunch, geofence *GeofenceResult) error
+	GetUserWorkSites(userId string) ([]*WorkSite, error)
+	GetUserActiveLeavesBetween(userId string, leaveFrom string, leaveTo string) ([]*UserLeave, error)
+	GetLeaveApprovalChainSteps(userId string) ([]string, error)
+	GetUserReportingManagerId(userId string) (*string, error)
+	ApplyUserLeave(userLeave *UserLeave, approvalSteps []*LeaveApprovalStep, checkedLeaveIds []string) (string, error)
 	GetAllUsersPendingLeavesCount(adminId string) (int, error)
 	GetAllUsersPendingLeaves(adminId string, limit uint32, offset uint32) ([]*UserPendingLeaveResponse, error)
 	GetUsersLeavesCount(userId string, leaveStatus string) (int, error)
 	GetUserLeaves(userId string, leaveStatus string, limit uint32, offset uint32) ([]*UserLeaveResponse, error)
 	CheckLeaveIdExists(leaveId string) (bool, error)
-	CheckPendingLeaveExistsByLeaveId(leaveId string) (bool, error)
-	CancelUserLeave(leaveId string, userType string) error
-	GrantUserLeave(leaveId string) error
+	CheckUserHasLeaveTypes(userId string) (bool, error)
+	GetUserShift(userId string) (string, string, error)
+	GetUserWorkCalendar(userId string, fromDate string, toDate string) (*WorkCalendar, error)
+	GetUserLeaveBalance(userId string, leaveTypeId string) (*LeaveBalanceResponse, error)
+	GetUserLeave(leaveId string) (*UserLeave, error)
+	CancelUserLeave(leaveId string, userType string, leaveStatus string) (bool, error)
 	UpdateUserProfileInfo(userId string, userProfileUpdateRequest *UserProfileInfoUpdateRequest) error
 	UpdateUserProfileUrl(userId string, url string) error
 	GetUserProfileUrl(userId string) (string, error)
@@ -242,6 +351,7 @@ type UserDatabaseInterface interface {
 	UpdateNewUserPassword(userId string, password string) error
 	StoreUserOtp(email string, otp string, expireTime *time.Time) error
 	ClearOtp(email string, otp string) error
+	ClearUserOtps(email string) error
 	CheckOtpExists(email string, otp string) (bool, error)
 	GetUserDetailsForValidateOtp(email string) (string, string, error)
 	GetUsersWorkHistoryCount(userId string) (int, error)
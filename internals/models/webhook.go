@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+type CreateWebhookRequest struct {
+	AdminId    string   `json:"admin_id" validate:"required"`
+	Url        string   `json:"url" validate:"required,url,max=2048"`
+	Secret     string   `json:"secret" validate:"required,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,unique,dive,oneof=work.login work.logout leave.applied leave.granted leave.cancelled user.created"`
+}
+
+type Webhook struct {
+	WebhookId  string
+	AdminId    string
+	Url        string
+	Secret     string
+	EventTypes []string
+}
+
+type WebhookResponse struct {
+	WebhookId  string    `json:"webhook_id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// data of the work.login and work.logout events
+type WebhookWorkEvent struct {
+	UserId    string    `json:"user_id"`
+	SessionId string    `json:"session_id"`
+	PunchedAt time.Time `json:"punched_at"`
+	Latitude  string    `json:"latitude,omitempty"`
+	Longitude string    `json:"longitude,omitempty"`
+}
+
+// data of the leave.applied, leave.granted and leave.cancelled events
+type WebhookLeaveEvent struct {
+	UserId           string  `json:"user_id"`
+	LeaveId          string  `json:"leave_id"`
+	LeaveTypeId      *string `json:"leave_type_id"`
+	LeaveFrom        string  `json:"leave_from"`
+	LeaveTo          string  `json:"leave_to"`
+	LeaveGranularity string  `json:"leave_granularity"`
+	LeaveStartTime   *string `json:"leave_start_time"`
+	LeaveEndTime     *string `json:"leave_end_time"`
+	LeaveDays        float64 `json:"leave_days"`
+	CancelledBy      string  `json:"cancelled_by,omitempty"`
+}
+
+// data of the user.created event
+type WebhookUserEvent struct {
+	UserId      string `json:"user_id"`
+	CategoryId  string `json:"category_id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Position    string `json:"position"`
+}
+
+// a claimed delivery with the endpoint it goes to
+type WebhookDelivery struct {
+	DeliveryId string
+	WebhookId  string
+	EventType  string
+	Payload    []byte
+	Attempts   int
+	Url        string
+	Secret     string
+}
+
+type WebhookDeliveryAttempt struct {
+	StatusCode  *int32    `json:"status_code"`
+	Error       *string   `json:"error"`
+	DurationMs  int32     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryId     string                    `json:"delivery_id"`
+	WebhookId      string                    `json:"webhook_id"`
+	EventType      string                    `json:"event_type"`
+	Status         string                    `json:"status"`
+	Attempts       int32                     `json:"attempts"`
+	NextAttemptAt  *time.Time                `json:"next_attempt_at"`
+	LastStatusCode *int32                    `json:"last_status_code"`
+	LastError      *string                   `json:"last_error"`
+	DeliveredAt    *time.Time                `json:"delivered_at"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	Payload        interface{}               `json:"payload,omitempty"`
+	AttemptLog     []*WebhookDeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+type WebhookInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CreateWebhook(webhook *Webhook) error
+	GetWebhooks(adminId string) ([]*WebhookResponse, error)
+	DeleteWebhook(webhookId string) error
+	CheckWebhookIdExists(webhookId string) (bool, error)
+	EnqueueWebhookDeliveries(userId string, eventType string, data []byte) (int, error)
+	ClaimWebhookDelivery(lease time.Duration) (*WebhookDelivery, error)
+	RecordWebhookAttempt(deliveryId string, attempt *WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error
+	GetWebhookDeliveriesCount(webhookId string, status string) (int32, error)
+	GetWebhookDeliveries(webhookId string, status string, limit uint32, offset uint32) ([]*WebhookDeliveryResponse, error)
+	GetWebhookDelivery(deliveryId string) (*WebhookDeliveryResponse, error)
+	GetWebhookDeliveryAttempts(deliveryId string) ([]*WebhookDeliveryAttempt, error)
+	RedeliverWebhookDelivery(deliveryId string) (bool, error)
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+type OpenWorkSession struct {
+	SessionId       string
+	UserId          string
+	UserName        string
+	UserEmail       string
+	AdminEmail      string
+	WorkDate        string
+	LoginAt         time.Time
+	LastActivityAt  *time.Time
+	ShiftStartTime  string
+	ShiftEndTime    string
+	AutoClosePolicy string
+}
+
+type WorkSessionAutoClose struct {
+	SessionId   string
+	UserId      string
+	LogoutAt    time.Time
+	Policy      string
+	NeedsReview bool
+}
+
+type WorkSessionAutoClosedEmailFormat struct {
+	To        string            `json:"to"`
+	Subject   string            `json:"subject"`
+	EmailType string            `json:"email_type"`
+	Data      map[string]string `json:"data"`
+}
+
+type UserWorkHeartbeatRequest struct {
+	UserId string `json:"user_id" validate:"required"`
+}
+
+type WorkSessionForReviewResponse struct {
+	SessionId       string     `json:"session_id"`
+	UserId          string     `json:"user_id"`
+	UserName        string     `json:"user_name"`
+	WorkDate        string     `json:"work_date"`
+	LoginTime       string     `json:"login_time"`
+	LogoutTime      string     `json:"logout_time"`
+	LoginAt         time.Time  `json:"login_at"`
+	LogoutAt        *time.Time `json:"logout_at"`
+	LastActivityAt  *time.Time `json:"last_activity_at"`
+	AutoClosePolicy string     `json:"auto_close_policy"`
+	AutoClosedAt    *time.Time `json:"auto_closed_at"`
+}
+
+// logout date and time are wall clock values of the organization timezone
+type WorkSessionReviewResolveRequest struct {
+	LogoutDate string `json:"logout_date" validate:"required,date"`
+	LogoutTime string `json:"logout_time" validate:"required,time"`
+	Work       string `json:"work"`
+}
+
+type WorkSessionInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	GetOverdueOpenWorkSessions(limit int) ([]*OpenWorkSession, error)
+	AutoCloseWorkSession(autoClose *WorkSessionAutoClose) (bool, error)
+	UpdateUserWorkActivity(userId string) (bool, error)
+	GetWorkSessionsForReview(adminId string) ([]*WorkSessionForReviewResponse, error)
+	GetWorkSessionForReviewLoginAt(sessionId string) (*time.Time, error)
+	ResolveWorkSessionReview(sessionId string, logoutAt time.Time, work string) (bool, error)
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" validate:"gte=-180,lte=180"`
+}
+
+type WorkSite struct {
+	SiteId          string
+	AdminId         string
+	SiteName        string
+	SiteType        string
+	CenterLatitude  *float64
+	CenterLongitude *float64
+	RadiusMeters    *float64
+	Polygon         []GeoPoint
+	Enforcement     string
+}
+
+type CreateWorkSiteRequest struct {
+	AdminId         string     `json:"admin_id" validate:"required"`
+	SiteName        string     `json:"site_name" validate:"required"`
+	SiteType        string     `json:"site_type" validate:"required,oneof=circle polygon"`
+	CenterLatitude  *float64   `json:"center_latitude" validate:"omitempty,gte=-90,lte=90"`
+	CenterLongitude *float64   `json:"center_longitude" validate:"omitempty,gte=-180,lte=180"`
+	RadiusMeters    *float64   `json:"radius_meters" validate:"omitempty,gt=0"`
+	Polygon         []GeoPoint `json:"polygon" validate:"omitempty,dive"`
+	Enforcement     string     `json:"enforcement" validate:"omitempty,oneof=flag reject"`
+}
+
+type WorkSiteResponse struct {
+	SiteId          string     `json:"site_id"`
+	SiteName        string     `json:"site_name"`
+	SiteType        string     `json:"site_type"`
+	CenterLatitude  *float64   `json:"center_latitude"`
+	CenterLongitude *float64   `json:"center_longitude"`
+	RadiusMeters    *float64   `json:"radius_meters"`
+	Polygon         []GeoPoint `json:"polygon"`
+	Enforcement     string     `json:"enforcement"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type WorkSiteAssignRequest struct {
+	SiteId     string `json:"site_id" validate:"required"`
+	CategoryId string `json:"category_id" validate:"required_without=UserId,excluded_with=UserId"`
+	UserId     string `json:"user_id" validate:"required_without=CategoryId,excluded_with=CategoryId"`
+}
+
+type WorkSiteAssignment struct {
+	AssignmentId string
+	SiteId       string
+	CategoryId   *string
+	UserId       *string
+}
+
+type WorkSiteAssignmentResponse struct {
+	AssignmentId string    `json:"assignment_id"`
+	SiteId       string    `json:"site_id"`
+	CategoryId   *string   `json:"category_id"`
+	CategoryName *string   `json:"category_name"`
+	UserId       *string   `json:"user_id"`
+	UserName     *string   `json:"user_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GeofenceResult is the outcome of checking a punch location against the work sites
+// assigned to a user. SiteId is the matched site, or the nearest one when the punch
+// was outside every site. it stays nil when no work site is assigned at all.
+type GeofenceResult struct {
+	SiteId          *string
+	DistanceMeters  *float64
+	OutsideGeofence bool
+	Reject          bool
+}
+
+type WorkSiteInterface interface {
+	CheckAdminIdExists(adminId string) (bool, error)
+	CheckWorkSiteExists(adminId string, siteName string) (bool, error)
+	CreateWorkSite(site *WorkSite) error
+	GetWorkSites(adminId string) ([]*WorkSiteResponse, error)
+	CheckWorkSiteIdExists(siteId string) (bool, error)
+	DeleteWorkSite(siteId string) error
+	CheckEmployeeCategoryIdExists(categoryId string) (bool, error)
+	CheckUserIdExists(userId string) (bool, error)
+	CheckWorkSiteAssignmentExists(siteId string, categoryId string, userId string) (bool, error)
+	CreateWorkSiteAssignment(assignment *WorkSiteAssignment) error
+	GetWorkSiteAssignments(siteId string) ([]*WorkSiteAssignmentResponse, error)
+	CheckWorkSiteAssignmentIdExists(assignmentId string) (bool, error)
+	DeleteWorkSiteAssignment(assignmentId string) error
+}
@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/handlers"
+	"github.com/vithsutra/ca_project_http_server/internals/middlewares"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+func InitHttpRoutes(e *echo.Echo,
+	rootRepo *repository.RootRepo,
+	adminRepo *repository.AdminRepo,
+	employeeCategoryRepo *repository.EmployeeCategoryRepo,
+	userRepo *repository.UserRepo,
+	workSiteRepo *repository.WorkSiteRepo,
+	workSessionRepo *repository.WorkSessionRepo,
+	attendanceCorrectionRepo *repository.AttendanceCorrectionRepo,
+	leaveTypeRepo *repository.LeaveTypeRepo,
+	holidayRepo *repository.HolidayRepo,
+	attendanceLedgerRepo *repository.AttendanceLedgerRepo,
+	musterRollRepo *repository.MusterRollRepo,
+	reportJobRepo *repository.ReportJobRepo,
+	reportBrandingRepo *repository.ReportBrandingRepo,
+	issuedReportRepo *repository.IssuedReportRepo,
+	analyticsRepo *repository.AnalyticsRepo,
+	presenceRepo *repository.PresenceRepo,
+	webhookRepo *repository.WebhookRepo,
+	authorizationRepo *repository.AuthorizationRepo,
+	authTokenRepo *repository.AuthTokenRepo,
+	roleRepo *repository.RoleRepo,
+	leaveApprovalRepo *repository.LeaveApprovalRepo,
+) *echo.Echo {
+
+	rootHandler := handlers.NewRootHandler(rootRepo)
+	adminHandler := handlers.NewAdminHandler(adminRepo)
+	employeeCategoryHandler := handlers.NewEmployeeCategoryHandler(employeeCategoryRepo)
+	userHandler := handlers.NewUserHandler(userRepo)
+	workSiteHandler := handlers.NewWorkSiteHandler(workSiteRepo)
+	workSessionHandler := handlers.NewWorkSessionHandler(workSessionRepo)
+	attendanceCorrectionHandler := handlers.NewAttendanceCorrectionHandler(attendanceCorrectionRepo)
+	leaveTypeHandler := handlers.NewLeaveTypeHandler(leaveTypeRepo)
+	holidayHandler := handlers.NewHolidayHandler(holidayRepo)
+	attendanceLedgerHandler := handlers.NewAttendanceLedgerHandler(attendanceLedgerRepo)
+	musterRollHandler := handlers.NewMusterRollHandler(musterRollRepo)
+	reportJobHandler := handlers.NewReportJobHandler(reportJobRepo)
+	reportBrandingHandler := handlers.NewReportBrandingHandler(reportBrandingRepo)
+	issuedReportHandler := handlers.NewIssuedReportHandler(issuedReportRepo)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsRepo)
+	presenceHandler := handlers.NewPresenceHandler(presenceRepo)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo)
+	authTokenHandler := handlers.NewAuthTokenHandler(authTokenRepo)
+	roleHandler := handlers.NewRoleHandler(roleRepo)
+	leaveApprovalHandler := handlers.NewLeaveApprovalHandler(leaveApprovalRepo)
+	authorization := middlewares.NewAuthorization(authorizationRepo)
+
+	//cors
+	e.Use(middlewares.CorsMiddlware())
+	e.OPTIONS("/*", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	//root routes
+	root := e.Group("/r")
+	// root.Use(middlewares.RootMiddleware())
+	root.POST("/create/admin", rootHandler.CreateAdminHandler)
+	root.GET("/get/admins", rootHandler.GetAllAdminsHandler)
+
+	//public routes
+	e.GET("/verify/report/:reportId", issuedReportHandler.VerifyReportHandler)
+
+	//auth routes
+	auth := e.Group("/auth")
+	auth.POST("/login/admin", adminHandler.AdminLoginHandler)
+	auth.POST("/login/user", userHandler.UserLoginHandler)
+	auth.POST("/admin/forgot/password", adminHandler.AdminForgotPasswordHandler)
+	auth.POST("/admin/validate/otp", adminHandler.AdminValidateOtpHandler)
+	auth.POST("/user/forgot/password", userHandler.UserForgotPasswordHandler)
+	auth.POST("/user/validate/otp", userHandler.ValidateUserOtpHandler)
+	auth.POST("/refresh/token", authTokenHandler.RefreshTokensHandler)
+	auth.POST("/logout", authTokenHandler.LogoutHandler)
+
+	//admin routes, open to admins and to users holding the permission of the route through a role
+	admin := e.Group("/admin")
+	admin.Use(middlewares.JwtMiddleware(), authorization.Authenticate())
+	admin.GET("/get/profile_details/:adminId", adminHandler.GetAdminProfileDetailsHandler, authorization.Can(models.PermissionAccountManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PATCH("/update/password/:adminId", adminHandler.UpdateAdminNewPasswordHandler, authorization.Can(models.PermissionAccountManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PUT("/update/profile_info", adminHandler.UpdateAdminProfileInfoHandler, authorization.Can(models.PermissionAccountManage, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.PUT("/update/profile_picture/:adminId", adminHandler.UpdateAdminProfilePictureHandler, authorization.Can(models.PermissionAccountManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/profile_picture/:adminId", adminHandler.DeleteAdminProfilePictureHandler, authorization.Can(models.PermissionAccountManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.POST("/create/employee_category", employeeCategoryHandler.CreateEmployeeCategoryHandler, authorization.Can(models.PermissionCategoryManage, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/employee_categories/:adminId", employeeCategoryHandler.GetEmployeeCategoriesHandler, authorization.Can(models.PermissionCategoryRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PUT("/update/category_attendance_settings", employeeCategoryHandler.UpdateCategoryAttendanceSettingsHandler, authorization.Can(models.PermissionCategoryManage, middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/category_attendance_settings/:categoryId", employeeCategoryHandler.GetCategoryAttendanceSettingsHandler, authorization.Can(models.PermissionCategoryRead, middlewares.Param("categoryId", models.ResourceCategory)))
+	admin.DELETE("/delete/employee_category/:categoryId", employeeCategoryHandler.DeleteEmployeeCategory, authorization.Can(models.PermissionCategoryManage, middlewares.Param("categoryId", models.ResourceCategory)))
+	admin.POST("/create/user", userHandler.CreateUserHandler, authorization.Can(models.PermissionUserCreate, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/users/:adminId", userHandler.GetUsers, authorization.Can(models.PermissionUserRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/user/:userId", userHandler.DeleteUser, authorization.Can(models.PermissionUserDelete, middlewares.Param("userId", models.ResourceUser)))
+	admin.GET("/get/user_work_history/:userId", userHandler.GetUserWorkHistoryHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("userId", models.ResourceUser)))
+	admin.GET("/get/all_users_work_history/:adminId", userHandler.GetAllUsersWorkHistory, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.GET("/get/attendance_month/:adminId", attendanceLedgerHandler.GetAttendanceMonthHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.GET("/get/analytics/attendance_trend", analyticsHandler.GetAttendanceTrendHandler, authorization.Can(models.PermissionAnalyticsRead, middlewares.Query("admin_id", models.ResourceAdmin), middlewares.Query("category_id", models.ResourceCategory)))
+	admin.GET("/get/analytics/leave_utilization", analyticsHandler.GetLeaveUtilizationHandler, authorization.Can(models.PermissionAnalyticsRead, middlewares.Query("admin_id", models.ResourceAdmin), middlewares.Query("category_id", models.ResourceCategory)))
+	admin.GET("/get/analytics/top_absentees", analyticsHandler.GetTopAbsenteesHandler, authorization.Can(models.PermissionAnalyticsRead, middlewares.Query("admin_id", models.ResourceAdmin), middlewares.Query("category_id", models.ResourceCategory)))
+
+	admin.GET("/get/users_pending_leaves/:adminId", userHandler.GetUserPendingLeavesHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.GET("/get/user_leaves/:userId", userHandler.GetUserLeavesHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("userId", models.ResourceUser)))
+
+	admin.PATCH("/cancel/user_leave/:userId/:leaveId", userHandler.CancelUserLeaveHandler, authorization.Can(models.PermissionLeaveApprove, middlewares.Param("userId", models.ResourceUser), middlewares.Param("leaveId", models.ResourceLeave)))
+	admin.PATCH("/grant/user_leave/:leaveId", leaveApprovalHandler.GrantUserLeaveHandler, authorization.Can(models.PermissionLeaveApprove, middlewares.Param("leaveId", models.ResourceLeave)))
+	admin.PATCH("/reject/user_leave/:leaveId", leaveApprovalHandler.RejectUserLeaveHandler, authorization.Can(models.PermissionLeaveApprove, middlewares.Param("leaveId", models.ResourceLeave)))
+	admin.GET("/get/leave_approval_steps/:leaveId", leaveApprovalHandler.GetLeaveApprovalStepsHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("leaveId", models.ResourceLeave)))
+	admin.PUT("/update/reporting_manager", leaveApprovalHandler.UpdateReportingManagerHandler, authorization.Can(models.PermissionReportingManage, middlewares.Body("user_id", models.ResourceUser), middlewares.Body("manager_id", models.ResourceUser)))
+	admin.PUT("/update/leave_approval_chain", leaveApprovalHandler.UpsertLeaveApprovalChainHandler, authorization.Can(models.PermissionLeaveChainManage, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/leave_approval_chains/:adminId", leaveApprovalHandler.GetLeaveApprovalChainsHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/leave_approval_chain/:chainId", leaveApprovalHandler.DeleteLeaveApprovalChainHandler, authorization.Can(models.PermissionLeaveChainManage, middlewares.Param("chainId", models.ResourceApprovalChain)))
+	admin.GET("/download/user/report", userHandler.DownloadUserReportPdf, authorization.Can(models.PermissionReportDownload, middlewares.Query("user_id", models.ResourceUser)))
+	admin.GET("/download/muster_roll", musterRollHandler.DownloadMusterRollHandler, authorization.Can(models.PermissionReportDownload, middlewares.Query("admin_id", models.ResourceAdmin), middlewares.Query("category_id", models.ResourceCategory)))
+	admin.POST("/create/report_job", reportJobHandler.CreateReportJobHandler, authorization.Can(models.PermissionReportDownload, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/report_job/:jobId", reportJobHandler.GetReportJobHandler, authorization.Can(models.PermissionReportDownload, middlewares.Param("jobId", models.ResourceReportJob)))
+	admin.GET("/get/report_jobs/:adminId", reportJobHandler.GetReportJobsHandler, authorization.Can(models.PermissionReportDownload, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PUT("/update/report_branding", reportBrandingHandler.UpsertReportBrandingHandler, authorization.Can(models.PermissionReportBranding, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/report_branding/:adminId", reportBrandingHandler.GetReportBrandingHandler, authorization.Can(models.PermissionReportBranding, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PUT("/update/report_logo/:adminId", reportBrandingHandler.UpdateReportLogoHandler, authorization.Can(models.PermissionReportBranding, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/report_logo/:adminId", reportBrandingHandler.DeleteReportLogoHandler, authorization.Can(models.PermissionReportBranding, middlewares.Param("adminId", models.ResourceAdmin)))
+
+	admin.POST("/create/leave_type", leaveTypeHandler.CreateLeaveTypeHandler, authorization.Can(models.PermissionLeaveTypeManage, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/leave_types/:categoryId", leaveTypeHandler.GetLeaveTypesHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("categoryId", models.ResourceCategory)))
+	admin.DELETE("/delete/leave_type/:leaveTypeId", leaveTypeHandler.DeleteLeaveTypeHandler, authorization.Can(models.PermissionLeaveTypeManage, middlewares.Param("leaveTypeId", models.ResourceLeaveType)))
+	admin.POST("/adjust/leave_balance", leaveTypeHandler.AdjustLeaveBalanceHandler, authorization.Can(models.PermissionLeaveAdjust, middlewares.Body("user_id", models.ResourceUser), middlewares.Body("leave_type_id", models.ResourceLeaveType)))
+	admin.GET("/get/user_leave_balances/:userId", leaveTypeHandler.GetUserLeaveBalancesHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("userId", models.ResourceUser)))
+	admin.GET("/get/user_leave_ledger/:userId", leaveTypeHandler.GetUserLeaveLedgerHandler, authorization.Can(models.PermissionLeaveRead, middlewares.Param("userId", models.ResourceUser)))
+
+	admin.POST("/create/holiday", holidayHandler.CreateHolidayHandler, authorization.Can(models.PermissionCalendarManage, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.POST("/import/holidays", holidayHandler.ImportHolidaysHandler, authorization.Can(models.PermissionCalendarManage, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/holidays/:adminId", holidayHandler.GetHolidaysHandler, authorization.Can(models.PermissionCalendarRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/holiday/:holidayId", holidayHandler.DeleteHolidayHandler, authorization.Can(models.PermissionCalendarManage, middlewares.Param("holidayId", models.ResourceHoliday)))
+	admin.PUT("/update/weekly_off_rule", holidayHandler.UpsertWeeklyOffRuleHandler, authorization.Can(models.PermissionCalendarManage, middlewares.Body("admin_id", models.ResourceAdmin), middlewares.Body("category_id", models.ResourceCategory)))
+	admin.GET("/get/weekly_off_rules/:adminId", holidayHandler.GetWeeklyOffRulesHandler, authorization.Can(models.PermissionCalendarRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/weekly_off_rule/:ruleId", holidayHandler.DeleteWeeklyOffRuleHandler, authorization.Can(models.PermissionCalendarManage, middlewares.Param("ruleId", models.ResourceWeeklyOffRule)))
+
+	admin.POST("/create/work_site", workSiteHandler.CreateWorkSiteHandler, authorization.Can(models.PermissionWorkSiteManage, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/work_sites/:adminId", workSiteHandler.GetWorkSitesHandler, authorization.Can(models.PermissionWorkSiteRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/work_site/:siteId", workSiteHandler.DeleteWorkSiteHandler, authorization.Can(models.PermissionWorkSiteManage, middlewares.Param("siteId", models.ResourceWorkSite)))
+	admin.POST("/assign/work_site", workSiteHandler.AssignWorkSiteHandler, authorization.Can(models.PermissionWorkSiteManage, middlewares.Body("site_id", models.ResourceWorkSite), middlewares.Body("category_id", models.ResourceCategory), middlewares.Body("user_id", models.ResourceUser)))
+	admin.GET("/get/work_site_assignments/:siteId", workSiteHandler.GetWorkSiteAssignmentsHandler, authorization.Can(models.PermissionWorkSiteRead, middlewares.Param("siteId", models.ResourceWorkSite)))
+	admin.DELETE("/delete/work_site_assignment/:assignmentId", workSiteHandler.DeleteWorkSiteAssignmentHandler, authorization.Can(models.PermissionWorkSiteManage, middlewares.Param("assignmentId", models.ResourceSiteAssignment)))
+
+	admin.GET("/get/work_sessions_for_review/:adminId", workSessionHandler.GetWorkSessionsForReviewHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PATCH("/resolve/work_session_review/:sessionId", workSessionHandler.ResolveWorkSessionReviewHandler, authorization.Can(models.PermissionAttendanceCorrect, middlewares.Param("sessionId", models.ResourceWorkSession)))
+
+	admin.GET("/get/pending_attendance_corrections/:adminId", attendanceCorrectionHandler.GetPendingAttendanceCorrectionsHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.GET("/get/user_attendance_corrections/:userId", attendanceCorrectionHandler.GetUserAttendanceCorrectionsHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("userId", models.ResourceUser)))
+	admin.PATCH("/approve/attendance_correction/:correctionId", attendanceCorrectionHandler.ApproveAttendanceCorrectionHandler, authorization.Can(models.PermissionAttendanceCorrect, middlewares.Param("correctionId", models.ResourceCorrection), middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.PATCH("/reject/attendance_correction/:correctionId", attendanceCorrectionHandler.RejectAttendanceCorrectionHandler, authorization.Can(models.PermissionAttendanceCorrect, middlewares.Param("correctionId", models.ResourceCorrection), middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/work_session_audit/:sessionId", attendanceCorrectionHandler.GetWorkSessionAuditTrailHandler, authorization.Can(models.PermissionAttendanceRead, middlewares.Param("sessionId", models.ResourceWorkSession)))
+
+	admin.POST("/create/webhook", webhookHandler.CreateWebhookHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/webhooks/:adminId", webhookHandler.GetWebhooksHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.DELETE("/delete/webhook/:webhookId", webhookHandler.DeleteWebhookHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Param("webhookId", models.ResourceWebhook)))
+	admin.GET("/get/webhook_deliveries/:webhookId", webhookHandler.GetWebhookDeliveriesHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Param("webhookId", models.ResourceWebhook)))
+	admin.GET("/get/webhook_delivery/:deliveryId", webhookHandler.GetWebhookDeliveryHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Param("deliveryId", models.ResourceWebhookDelivery)))
+	admin.POST("/redeliver/webhook_delivery/:deliveryId", webhookHandler.RedeliverWebhookDeliveryHandler, authorization.Can(models.PermissionWebhookManage, middlewares.Param("deliveryId", models.ResourceWebhookDelivery)))
+	admin.POST("/create/role", roleHandler.CreateRoleHandler, authorization.Can(models.PermissionRoleManage, middlewares.Body("admin_id", models.ResourceAdmin)))
+	admin.GET("/get/roles/:adminId", roleHandler.GetRolesHandler, authorization.Can(models.PermissionRoleManage, middlewares.Param("adminId", models.ResourceAdmin)))
+	admin.PUT("/update/role", roleHandler.UpdateRoleHandler, authorization.Can(models.PermissionRoleManage, middlewares.Body("role_id", models.ResourceRole)))
+	admin.DELETE("/delete/role/:roleId", roleHandler.DeleteRoleHandler, authorization.Can(models.PermissionRoleManage, middlewares.Param("roleId", models.ResourceRole)))
+	admin.POST("/assign/user_role", roleHandler.AssignUserRoleHandler, authorization.Can(models.PermissionRoleManage, middlewares.Body("user_id", models.ResourceUser), middlewares.Body("role_id", models.ResourceRole)))
+	admin.DELETE("/delete/user_role/:userId/:roleId", roleHandler.RemoveUserRoleHandler, authorization.Can(models.PermissionRoleManage, middlewares.Param("userId", models.ResourceUser), middlewares.Param("roleId", models.ResourceRole)))
+	admin.PUT("/update/user_managed_categories", roleHandler.UpdateUserManagedCategoriesHandler, authorization.Can(models.PermissionRoleManage, middlewares.Body("user_id", models.ResourceUser)))
+	admin.GET("/get/user_roles/:userId", roleHandler.GetUserRolesHandler, authorization.Can(models.PermissionRoleManage, middlewares.Param("userId", models.ResourceUser)))
+
+	admin.POST("/revoke/user_sessions/:userId", authTokenHandler.RevokeUserSessionsHandler, authorization.Can(models.PermissionUserRevokeSession, middlewares.Param("userId", models.ResourceUser)))
+
+	//admin stream routes, the token may also be passed as a query parameter
+	adminStream := e.Group("/admin/stream")
+	adminStream.Use(middlewares.JwtStreamMiddleware(), authorization.Authenticate())
+	adminStream.GET("/presence/:adminId", presenceHandler.StreamPresenceHandler, authorization.Can(models.PermissionPresenceRead, middlewares.Param("adminId", models.ResourceAdmin)))
+
+	//user routes
+	//the otp is validated before the user holds a token
+	e.POST("/user/validate/otp", userHandler.ValidateUserOtpHandler)
+
+	user := e.Group("/user")
+	user.Use(middlewares.JwtMiddleware(), authorization.Role(models.RoleUser))
+	user.GET("/get/profile_details/:userId", userHandler.GetUserProfileDetailsHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.POST("/work/login", userHandler.UserWorkLoginHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser)))
+	user.POST("/work/logout", userHandler.UserWorkLogoutHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser), middlewares.Body("session_id", models.ResourceWorkSession)))
+	user.POST("/work/heartbeat", workSessionHandler.UserWorkHeartbeatHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser)))
+	user.GET("/get/work_history/:userId", userHandler.GetUserWorkHistoryHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.POST("/apply/leave", userHandler.ApplyUserLeaveHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser), middlewares.Body("leave_type_id", models.ResourceLeaveType)))
+	user.PATCH("/cancel/leave/:userId/:leaveId", userHandler.CancelUserLeaveHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser), middlewares.Param("leaveId", models.ResourceLeave)))
+	user.GET("/get/leaves/:userId", userHandler.GetUserLeavesHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/get/leave_approval_steps/:userId/:leaveId", leaveApprovalHandler.GetLeaveApprovalStepsHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser), middlewares.Param("leaveId", models.ResourceLeave)))
+	user.GET("/get/pending_approvals/:userId", leaveApprovalHandler.GetManagerPendingLeavesHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.PATCH("/approve/leave/:userId/:leaveId", leaveApprovalHandler.ApproveManagedLeaveHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.PATCH("/reject/leave/:userId/:leaveId", leaveApprovalHandler.RejectManagedLeaveHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/get/leave_types/:categoryId", leaveTypeHandler.GetLeaveTypesHandler, authorization.Owns(middlewares.Param("categoryId", models.ResourceCategory)))
+	user.GET("/get/leave_balances/:userId", leaveTypeHandler.GetUserLeaveBalancesHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/get/leave_ledger/:userId", leaveTypeHandler.GetUserLeaveLedgerHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/get/holidays/:userId", holidayHandler.GetUserHolidaysHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.POST("/apply/attendance_correction", attendanceCorrectionHandler.ApplyAttendanceCorrectionHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser), middlewares.Body("session_id", models.ResourceWorkSession)))
+	user.GET("/get/attendance_corrections/:userId", attendanceCorrectionHandler.GetUserAttendanceCorrectionsHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.PUT("/update/profile_info", userHandler.UserProfileInfoUpdateHandler, authorization.Owns(middlewares.Body("user_id", models.ResourceUser), middlewares.Body("category_id", models.ResourceCategory)))
+	user.PUT("/update/profile_picture/:userId", userHandler.UpdateUserProfilePictureHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.PATCH("/delete/profile_picture/:userId", userHandler.DeleteProfilePictureHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/last_profile_update_time/:userId", userHandler.GetUserLastProfileUpdateTimeHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.POST("/update/password/:userId", userHandler.UpdateUserNewPaswordHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+	user.GET("/get/roles/:userId", roleHandler.GetUserRolesHandler, authorization.Owns(middlewares.Param("userId", models.ResourceUser)))
+
+	return e
+}
@@ -0,0 +1,422 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+	"github.com/vithsutra/ca_project_http_server/repository"
+)
+
+// resources that belong to a single user, the others only belong to an organization
+var userScopedResources = map[string]bool{
+	models.ResourceUser:        true,
+	models.ResourceLeave:       true,
+	models.ResourceWorkSession: true,
+	models.ResourceCorrection:  true,
+}
+
+// ids the rules of the routes can name, in the path, the query or the json body
+var authorizationFields = []string{
+	"admin_id",
+	"category_id",
+	"leave_type_id",
+	"manager_id",
+	"role_id",
+	"session_id",
+	"site_id",
+	"user_id",
+}
+
+// fakeAuthorizationDb resolves every resource id to the owners of the id whatever the resource
+type fakeAuthorizationDb struct {
+	resourceOwners map[string]*models.ResourceOwner
+	tokenOwners    map[string]*models.TokenOwner
+	grants         map[string]*models.PermissionGrant
+}
+
+func (db *fakeAuthorizationDb) GetResourceOwner(resource string, resourceId string) (*models.ResourceOwner, error) {
+	owner, ok := db.resourceOwners[resourceId]
+
+	if !ok {
+		return nil, nil
+	}
+
+	resourceOwner := *owner
+
+	if !userScopedResources[resource] {
+		resourceOwner.UserId = nil
+	}
+
+	return &resourceOwner, nil
+}
+
+func (db *fakeAuthorizationDb) GetTokenOwner(role string, subjectId string) (*models.TokenOwner, error) {
+	return db.tokenOwners[subjectId], nil
+}
+
+func (db *fakeAuthorizationDb) GetUserPermissionGrant(userId string, permission string) (*models.PermissionGrant, error) {
+	return db.grants[userId], nil
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func newTestServer(db *fakeAuthorizationDb) *echo.Echo {
+	e := echo.New()
+	//a request the authorization lets through reaches a handler without repositories
+	e.Use(middleware.Recover())
+
+	return InitHttpRoutes(
+		e,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		repository.NewAuthorizationRepo(db),
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func newTestAuthorizationDb() *fakeAuthorizationDb {
+	revokedAt := time.Now().Add(time.Minute)
+
+	return &fakeAuthorizationDb{
+		resourceOwners: map[string]*models.ResourceOwner{
+			"own":                {AdminId: "admin-a", UserId: stringPointer("user-a"), CategoryId: stringPointer("category-a")},
+			"other-user":         {AdminId: "admin-a", UserId: stringPointer("user-a2"), CategoryId: stringPointer("category-a")},
+			"other-organization": {AdminId: "admin-b", UserId: stringPointer("user-b"), CategoryId: stringPointer("category-b")},
+		},
+		tokenOwners: map[string]*models.TokenOwner{
+			"admin-a":      {AdminId: "admin-a"},
+			"user-a":       {AdminId: "admin-a"},
+			"user-officer": {AdminId: "admin-a"},
+			"user-revoked": {AdminId: "admin-a", SessionsRevokedAt: &revokedAt},
+		},
+		grants: map[string]*models.PermissionGrant{
+			"user-officer": {CategoryScoped: false},
+		},
+	}
+}
+
+func newTestToken(t *testing.T, role string, subject string) string {
+	token, err := utils.GenerateAccessToken(role, subject, "admin-a", subject+"@example.com", subject, time.Hour)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+// tokenIssuedAt reads the issue time of a token back, revocations are compared with it to the second
+func tokenIssuedAt(t *testing.T, token string) time.Time {
+	parsed, err := utils.ParseAccessToken(token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt, err := parsed.Claims.GetIssuedAt()
+
+	if err != nil || issuedAt == nil {
+		t.Fatalf("token without issue time: %v", err)
+	}
+
+	return issuedAt.Time
+}
+
+// newRouteRequest names resource id in every path parameter and in every id field the rules of
+// the route could read, the query for a GET and the json body otherwise
+func newRouteRequest(route *echo.Route, resourceId string, token string) *http.Request {
+	segments := strings.Split(route.Path, "/")
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = resourceId
+		}
+	}
+
+	path := strings.Join(segments, "/")
+
+	var request *http.Request
+
+	if route.Method == http.MethodGet {
+		query := url.Values{}
+
+		for _, field := range authorizationFields {
+			query.Set(field, resourceId)
+		}
+
+		request = httptest.NewRequest(route.Method, path+"?"+query.Encode(), nil)
+	} else {
+		fields := make(map[string]string)
+
+		for _, field := range authorizationFields {
+			fields[field] = resourceId
+		}
+
+		body, _ := json.Marshal(fields)
+
+		request = httptest.NewRequest(route.Method, path, strings.NewReader(string(body)))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	request.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+	return request
+}
+
+// admin routes of the permissions no role can grant
+var adminOnlyRoutes = map[string]bool{
+	"GET /admin/get/profile_details/:adminId":        true,
+	"PATCH /admin/update/password/:adminId":          true,
+	"PUT /admin/update/profile_info":                 true,
+	"PUT /admin/update/profile_picture/:adminId":     true,
+	"DELETE /admin/delete/profile_picture/:adminId":  true,
+	"POST /admin/create/role":                        true,
+	"GET /admin/get/roles/:adminId":                  true,
+	"PUT /admin/update/role":                         true,
+	"DELETE /admin/delete/role/:roleId":              true,
+	"POST /admin/assign/user_role":                   true,
+	"DELETE /admin/delete/user_role/:userId/:roleId": true,
+	"PUT /admin/update/user_managed_categories":      true,
+	"GET /admin/get/user_roles/:userId":              true,
+}
+
+func selectRoutes(routes []*echo.Route, selected map[string]bool) []*echo.Route {
+	var selectedRoutes []*echo.Route
+
+	for _, route := range routes {
+		if selected[route.Method+" "+route.Path] {
+			selectedRoutes = append(selectedRoutes, route)
+		}
+	}
+
+	return selectedRoutes
+}
+
+func TestRouteAuthorization(t *testing.T) {
+	t.Setenv("JWT_TOKEN_SCRETE_KEY", "route-authorization-test-key")
+
+	db := newTestAuthorizationDb()
+	e := newTestServer(db)
+
+	adminToken := newTestToken(t, models.RoleAdmin, "admin-a")
+	userToken := newTestToken(t, models.RoleUser, "user-a")
+	officerToken := newTestToken(t, models.RoleUser, "user-officer")
+	revokedToken := newTestToken(t, models.RoleUser, "user-revoked")
+	missingAccountToken := newTestToken(t, models.RoleUser, "user-deleted")
+	revokedAtIssueToken := newTestToken(t, models.RoleUser, "user-revoked-at-issue")
+	reloggedToken := newTestToken(t, models.RoleUser, "user-relogged")
+
+	//sessions revoked in the second the token was issued, and a token issued the second after a revocation
+	revokedAtIssue := tokenIssuedAt(t, revokedAtIssueToken)
+	revokedBeforeIssue := tokenIssuedAt(t, reloggedToken).Add(-time.Second)
+
+	db.tokenOwners["user-revoked-at-issue"] = &models.TokenOwner{AdminId: "admin-a", SessionsRevokedAt: &revokedAtIssue}
+	db.tokenOwners["user-relogged"] = &models.TokenOwner{AdminId: "admin-a", SessionsRevokedAt: &revokedBeforeIssue}
+	db.resourceOwners["relogged"] = &models.ResourceOwner{AdminId: "admin-a", UserId: stringPointer("user-relogged"), CategoryId: stringPointer("category-a")}
+
+	var adminRoutes, userRoutes []*echo.Route
+
+	for _, route := range e.Routes() {
+		switch route.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			continue
+		}
+
+		switch {
+		case route.Path == "/user/validate/otp":
+			//public, the otp is validated before the user holds a token
+		case strings.HasPrefix(route.Path, "/admin/"):
+			adminRoutes = append(adminRoutes, route)
+		case strings.HasPrefix(route.Path, "/user/"):
+			userRoutes = append(userRoutes, route)
+		}
+	}
+
+	if len(adminRoutes) == 0 || len(userRoutes) == 0 {
+		t.Fatal("no admin or user routes registered")
+	}
+
+	if len(selectRoutes(adminRoutes, adminOnlyRoutes)) != len(adminOnlyRoutes) {
+		t.Fatal("an admin only route is not registered")
+	}
+
+	tests := []struct {
+		name       string
+		routes     []*echo.Route
+		token      string
+		resourceId string
+		// zero when the authorization lets the request through to the handler
+		statusCode int
+		// routes the scenario does not apply to
+		skip map[string]bool
+	}{
+		{
+			name:       "admin on own organization",
+			routes:     adminRoutes,
+			token:      adminToken,
+			resourceId: "own",
+		},
+		{
+			name:       "role holder on own organization",
+			routes:     adminRoutes,
+			token:      officerToken,
+			resourceId: "own",
+			// account and role management are never granted through a role
+			skip: adminOnlyRoutes,
+		},
+		{
+			name:       "role holder on account and role management",
+			routes:     selectRoutes(adminRoutes, adminOnlyRoutes),
+			token:      officerToken,
+			resourceId: "own",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "user on own resources",
+			routes:     userRoutes,
+			token:      userToken,
+			resourceId: "own",
+		},
+		{
+			name:       "token issued after the sessions were revoked",
+			routes:     userRoutes,
+			token:      reloggedToken,
+			resourceId: "relogged",
+		},
+		{
+			name:       "admin on another organization",
+			routes:     adminRoutes,
+			token:      adminToken,
+			resourceId: "other-organization",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "role holder on another organization",
+			routes:     adminRoutes,
+			token:      officerToken,
+			resourceId: "other-organization",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "user without permission on admin route",
+			routes:     adminRoutes,
+			token:      userToken,
+			resourceId: "own",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "revoked token on admin route",
+			routes:     adminRoutes,
+			token:      revokedToken,
+			resourceId: "own",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "deleted account on admin route",
+			routes:     adminRoutes,
+			token:      missingAccountToken,
+			resourceId: "own",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "user on another organization",
+			routes:     userRoutes,
+			token:      userToken,
+			resourceId: "other-organization",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "user on another user",
+			routes:     userRoutes,
+			token:      userToken,
+			resourceId: "other-user",
+			statusCode: http.StatusForbidden,
+			skip: map[string]bool{
+				"GET /user/get/leave_types/:categoryId": true,
+			},
+		},
+		{
+			name:       "admin token on user route",
+			routes:     userRoutes,
+			token:      adminToken,
+			resourceId: "own",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "revoked token on user route",
+			routes:     userRoutes,
+			token:      revokedToken,
+			resourceId: "own",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "token issued in the second the sessions were revoked",
+			routes:     userRoutes,
+			token:      revokedAtIssueToken,
+			resourceId: "own",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "deleted account on user route",
+			routes:     userRoutes,
+			token:      missingAccountToken,
+			resourceId: "own",
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, route := range test.routes {
+				if test.skip[route.Method+" "+route.Path] {
+					continue
+				}
+
+				recorder := httptest.NewRecorder()
+
+				e.ServeHTTP(recorder, newRouteRequest(route, test.resourceId, test.token))
+
+				if test.statusCode == 0 {
+					if recorder.Code == http.StatusUnauthorized || recorder.Code == http.StatusForbidden {
+						t.Errorf("%s %s: expected the request to be let through, got %d %s", route.Method, route.Path, recorder.Code, strings.TrimSpace(recorder.Body.String()))
+					}
+
+					continue
+				}
+
+				if recorder.Code != test.statusCode {
+					t.Errorf("%s %s: expected status %d, got %d %s", route.Method, route.Path, test.statusCode, recorder.Code, strings.TrimSpace(recorder.Body.String()))
+				}
+			}
+		})
+	}
+}
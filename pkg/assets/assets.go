@@ -0,0 +1,14 @@
+// Package assets embeds the logo and fonts of the pdf reports, so the server does not depend on
+// the working directory it is started from
+package assets
+
+import _ "embed"
+
+//go:embed vithsutra_logo.png
+var Logo []byte
+
+//go:embed fonts/Roboto/static/Roboto-Bold.ttf
+var BoldFont []byte
+
+//go:embed fonts/Roboto/static/Roboto-Regular.ttf
+var RegularFont []byte
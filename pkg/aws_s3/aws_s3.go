@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -115,3 +116,130 @@ func (awsS3 *awsS3Repo) DeleteAdminProfilePicture(fileName string) error {
 
 	return err
 }
+
+func (awsS3 *awsS3Repo) UploadReportArchive(fileName string, file io.ReadSeeker) error {
+	bucketName := os.Getenv("AWS_S3_BUCKET_NAME")
+
+	if bucketName == "" {
+		return errors.New("missing AWS_S3_BUCKET_NAME env variable")
+	}
+
+	rootKey := os.Getenv("AWS_S3_ROOT_KEY")
+
+	if rootKey == "" {
+		return errors.New("missing AWS_S3_ROOT_KEY env variable")
+	}
+
+	filePath := fmt.Sprintf("%v/reports/%v", rootKey, fileName)
+
+	_, err := awsS3.conn.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(filePath),
+		Body:        file,
+		ContentType: aws.String("application/zip"),
+	})
+
+	return err
+}
+
+// GetReportArchiveUrl returns a presigned download url of the report archive valid for expiry
+func (awsS3 *awsS3Repo) GetReportArchiveUrl(fileName string, expiry time.Duration) (string, error) {
+	bucketName := os.Getenv("AWS_S3_BUCKET_NAME")
+
+	if bucketName == "" {
+		return "", errors.New("missing AWS_S3_BUCKET_NAME env variable")
+	}
+
+	rootKey := os.Getenv("AWS_S3_ROOT_KEY")
+
+	if rootKey == "" {
+		return "", errors.New("missing AWS_S3_ROOT_KEY env variable")
+	}
+
+	filePath := fmt.Sprintf("%v/reports/%v", rootKey, fileName)
+
+	request, _ := awsS3.conn.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     aws.String(bucketName),
+		Key:                        aws.String(filePath),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=\"%v\"", fileName)),
+	})
+
+	return request.Presign(expiry)
+}
+
+func (awsS3 *awsS3Repo) UploadReportLogo(fileName string, file io.ReadSeeker) error {
+	bucketName := os.Getenv("AWS_S3_BUCKET_NAME")
+
+	if bucketName == "" {
+		return errors.New("missing AWS_S3_BUCKET_NAME env variable")
+	}
+
+	rootKey := os.Getenv("AWS_S3_ROOT_KEY")
+
+	if rootKey == "" {
+		return errors.New("missing AWS_S3_ROOT_KEY env variable")
+	}
+
+	filePath := fmt.Sprintf("%v/branding/%v", rootKey, fileName)
+
+	_, err := awsS3.conn.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(filePath),
+		Body:   file,
+	})
+
+	return err
+}
+
+func (awsS3 *awsS3Repo) DeleteReportLogo(fileName string) error {
+	bucketName := os.Getenv("AWS_S3_BUCKET_NAME")
+
+	if bucketName == "" {
+		return errors.New("missing AWS_S3_BUCKET_NAME env variable")
+	}
+
+	rootKey := os.Getenv("AWS_S3_ROOT_KEY")
+
+	if rootKey == "" {
+		return errors.New("missing AWS_S3_ROOT_KEY env variable")
+	}
+
+	filePath := fmt.Sprintf("%v/branding/%v", rootKey, fileName)
+
+	_, err := awsS3.conn.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(filePath),
+	})
+
+	return err
+}
+
+// GetReportLogo downloads the logo of a report letterhead
+func (awsS3 *awsS3Repo) GetReportLogo(fileName string) ([]byte, error) {
+	bucketName := os.Getenv("AWS_S3_BUCKET_NAME")
+
+	if bucketName == "" {
+		return nil, errors.New("missing AWS_S3_BUCKET_NAME env variable")
+	}
+
+	rootKey := os.Getenv("AWS_S3_ROOT_KEY")
+
+	if rootKey == "" {
+		return nil, errors.New("missing AWS_S3_ROOT_KEY env variable")
+	}
+
+	filePath := fmt.Sprintf("%v/branding/%v", rootKey, fileName)
+
+	object, err := awsS3.conn.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(filePath),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer object.Body.Close()
+
+	return io.ReadAll(object.Body)
+}
@@ -152,8 +152,14 @@ func (repo *PostgresRepo) DeleteAdminOtp(email string, otp string) error {
 	return err
 }
 
+func (repo *PostgresRepo) DeleteAdminOtps(email string) error {
+	query := `DELETE FROM admin_otps WHERE email=$1`
+	_, err := repo.pool.Exec(context.Background(), query, email)
+	return err
+}
+
 func (repo *PostgresRepo) ValidateAdminOtp(email string, otp string) (bool, error) {
-	query1 := `SELECT EXISTS ( SELECT 1 FROM admin_otps WHERE email=$1 AND otp=$2 )`
+	query1 := `SELECT EXISTS ( SELECT 1 FROM admin_otps WHERE email=$1 AND otp=$2 AND expire_time > NOW() )`
 	query2 := `DELETE FROM admin_otps WHERE email=$1 AND otp=$2`
 
 	var otpExists bool
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// GetAttendanceTrend returns one row per date of the range, dates without ledger days are zero
+func (repo *PostgresRepo) GetAttendanceTrend(adminId string, categoryId *string, startDate string, endDate string) ([]*models.AttendanceTrendRow, error) {
+	query := `SELECT
+				to_char(d.day,'YYYY-MM-DD'),
+				COUNT(ad.user_id) FILTER (WHERE ad.status='present'),
+				COUNT(ad.user_id) FILTER (WHERE ad.status='half_day'),
+				COUNT(ad.user_id) FILTER (WHERE ad.status='absent'),
+				COUNT(ad.user_id) FILTER (WHERE ad.status='on_leave'),
+				COUNT(ad.first_login_minute),
+				COALESCE(SUM(ad.first_login_minute), 0),
+				COUNT(ad.user_id) FILTER (WHERE ad.worked_minutes > 0),
+				COALESCE(SUM(ad.worked_minutes), 0),
+				COUNT(ad.user_id) FILTER (WHERE ad.late_minutes > 0)
+			FROM generate_series($2::date, $3::date, interval '1 day') d(day)
+			LEFT JOIN (
+				attendance_days ad
+				JOIN users u ON u.user_id=ad.user_id
+					AND u.admin_id=$1 AND ($4::varchar IS NULL OR u.category_id=$4)
+			) ON ad.attendance_date=d.day::date
+			GROUP BY d.day
+			ORDER BY d.day`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, startDate, endDate, categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var trend []*models.AttendanceTrendRow
+
+	for rows.Next() {
+		var row models.AttendanceTrendRow
+
+		if err := rows.Scan(
+			&row.Date,
+			&row.Present,
+			&row.HalfDay,
+			&row.Absent,
+			&row.OnLeave,
+			&row.LoggedIn,
+			&row.FirstLoginMinutesSum,
+			&row.Worked,
+			&row.WorkedMinutesSum,
+			&row.LateArrivals,
+		); err != nil {
+			return nil, err
+		}
+
+		trend = append(trend, &row)
+	}
+
+	return trend, rows.Err()
+}
+
+// GetLeaveUtilization returns the leave days taken per leave type of the admin within the range,
+// partial day leaves count as their share of the category's net shift
+func (repo *PostgresRepo) GetLeaveUtilization(adminId string, categoryId *string, startDate string, endDate string) ([]*models.LeaveUtilization, error) {
+	query := `SELECT
+				lt.leave_type_id,
+				lt.leave_name,
+				ec.category_name,
+				(SELECT COUNT(*) FROM users cu WHERE cu.category_id=lt.category_id),
+				COUNT(DISTINCT ad.user_id) FILTER (WHERE ad.status='on_leave' OR ad.leave_minutes > 0),
+				COUNT(ad.user_id) FILTER (WHERE ad.status='on_leave')
+					+ COALESCE(SUM(ad.leave_minutes / ns.net_shift_minutes) FILTER (WHERE ad.status IN ('present', 'half_day', 'absent', 'incomplete')), 0),
+				lt.annual_quota::float8
+			FROM leave_types lt
+			JOIN employee_category ec ON ec.category_id=lt.category_id
+			LEFT JOIN category_attendance_settings cas ON cas.category_id=lt.category_id
+			CROSS JOIN LATERAL (
+				SELECT GREATEST(
+					EXTRACT(EPOCH FROM (
+						COALESCE(cas.shift_end_time,'18:00')::time - COALESCE(cas.shift_start_time,'09:00')::time
+						+ CASE WHEN COALESCE(cas.shift_end_time,'18:00')::time > COALESCE(cas.shift_start_time,'09:00')::time
+							THEN interval '0' ELSE interval '24 hours' END
+					))::float8 / 60 - COALESCE(cas.break_minutes,0),
+					1
+				) AS net_shift_minutes
+			) ns
+			LEFT JOIN attendance_days ad ON ad.leave_type_id=lt.leave_type_id
+				AND ad.attendance_date BETWEEN $2::date AND $3::date
+			WHERE lt.admin_id=$1 AND ($4::varchar IS NULL OR lt.category_id=$4)
+			GROUP BY lt.leave_type_id, lt.leave_name, ec.category_name, lt.annual_quota
+			ORDER BY ec.category_name, lt.leave_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, startDate, endDate, categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var utilization []*models.LeaveUtilization
+
+	for rows.Next() {
+		var leaveType models.LeaveUtilization
+
+		if err := rows.Scan(
+			&leaveType.LeaveTypeId,
+			&leaveType.LeaveName,
+			&leaveType.CategoryName,
+			&leaveType.UsersCount,
+			&leaveType.UsersOnLeave,
+			&leaveType.LeaveDays,
+			&leaveType.AnnualQuota,
+		); err != nil {
+			return nil, err
+		}
+
+		utilization = append(utilization, &leaveType)
+	}
+
+	return utilization, rows.Err()
+}
+
+// GetTopAbsentees returns the users with the most absent days within the range, a half day
+// counts half
+func (repo *PostgresRepo) GetTopAbsentees(adminId string, categoryId *string, startDate string, endDate string, limit int) ([]*models.TopAbsentee, error) {
+	query := `SELECT
+				u.user_id,
+				u.name,
+				ec.category_name,
+				COUNT(*) FILTER (WHERE ad.status='absent') + COUNT(*) FILTER (WHERE ad.status='half_day') * 0.5 AS absent_days,
+				COUNT(*) FILTER (WHERE ad.status='on_leave'),
+				COUNT(*) FILTER (WHERE ad.status IN ('present', 'half_day', 'absent', 'on_leave', 'incomplete'))
+			FROM users u
+			JOIN employee_category ec ON ec.category_id=u.category_id
+			JOIN attendance_days ad ON ad.user_id=u.user_id
+				AND ad.attendance_date BETWEEN $2::date AND $3::date
+			WHERE u.admin_id=$1 AND ($4::varchar IS NULL OR u.category_id=$4)
+			GROUP BY u.user_id, u.name, ec.category_name
+			HAVING COUNT(*) FILTER (WHERE ad.status IN ('absent', 'half_day')) > 0
+			ORDER BY absent_days DESC, u.name
+			LIMIT $5`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, startDate, endDate, categoryId, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var absentees []*models.TopAbsentee
+
+	for rows.Next() {
+		var absentee models.TopAbsentee
+
+		if err := rows.Scan(
+			&absentee.UserId,
+			&absentee.Name,
+			&absentee.CategoryName,
+			&absentee.AbsentDays,
+			&absentee.LeaveDays,
+			&absentee.WorkingDays,
+		); err != nil {
+			return nil, err
+		}
+
+		absentees = append(absentees, &absentee)
+	}
+
+	return absentees, rows.Err()
+}
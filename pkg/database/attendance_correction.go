@@ -0,0 +1,449 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+const attendanceCorrectionColumns = `
+				ac.correction_id,
+				ac.user_id,
+				u.name,
+				ac.session_id,
+				ac.correction_type,
+				uh.login_at,
+				uh.logout_at,
+				ac.requested_login_at,
+				ac.requested_logout_at,
+				ac.work,
+				ac.reason,
+				ac.status,
+				ac.reviewed_by,
+				ac.review_comment,
+				ac.reviewed_at,
+				ac.created_at`
+
+func scanAttendanceCorrections(rows pgx.Rows) ([]*models.AttendanceCorrectionResponse, error) {
+	defer rows.Close()
+
+	var corrections []*models.AttendanceCorrectionResponse
+
+	for rows.Next() {
+		var correction models.AttendanceCorrectionResponse
+
+		if err := rows.Scan(
+			&correction.CorrectionId,
+			&correction.UserId,
+			&correction.UserName,
+			&correction.SessionId,
+			&correction.CorrectionType,
+			&correction.CurrentLoginAt,
+			&correction.CurrentLogoutAt,
+			&correction.RequestedLoginAt,
+			&correction.RequestedLogoutAt,
+			&correction.Work,
+			&correction.Reason,
+			&correction.Status,
+			&correction.ReviewedBy,
+			&correction.ReviewComment,
+			&correction.ReviewedAt,
+			&correction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		corrections = append(corrections, &correction)
+	}
+
+	return corrections, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckUserWorkSessionIdExists(userId string, sessionId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM users_history WHERE user_id=$1 AND session_id=$2 )`
+	var sessionExists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId, sessionId).Scan(&sessionExists)
+	return sessionExists, err
+}
+
+func (repo *PostgresRepo) GetWorkSessionTimes(sessionId string) (*models.WorkSessionTimes, error) {
+	query := `SELECT session_id,user_id,login_at,logout_at,auto_closed FROM users_history WHERE session_id=$1`
+	var session models.WorkSessionTimes
+	err := repo.pool.QueryRow(context.Background(), query, sessionId).Scan(
+		&session.SessionId,
+		&session.UserId,
+		&session.LoginAt,
+		&session.LogoutAt,
+		&session.AutoClosed,
+	)
+	return &session, err
+}
+
+// CheckWorkSessionTimeOverlap reports whether the given range overlaps another session of the
+// user, an open range or an open session extends to infinity
+func (repo *PostgresRepo) CheckWorkSessionTimeOverlap(userId string, excludeSessionId string, loginAt time.Time, logoutAt *time.Time) (bool, error) {
+	query := `SELECT EXISTS (
+				SELECT 1 FROM users_history
+				WHERE user_id=$1 AND session_id<>$2
+				AND login_at < COALESCE($4::timestamptz,'infinity')
+				AND COALESCE(logout_at,'infinity') > $3
+			)`
+	var overlapExists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId, excludeSessionId, loginAt, logoutAt).Scan(&overlapExists)
+	return overlapExists, err
+}
+
+func (repo *PostgresRepo) CheckPendingAttendanceCorrectionExists(sessionId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM attendance_corrections WHERE session_id=$1 AND status='pending' )`
+	var correctionExists bool
+	err := repo.pool.QueryRow(context.Background(), query, sessionId).Scan(&correctionExists)
+	return correctionExists, err
+}
+
+func (repo *PostgresRepo) CreateAttendanceCorrection(correction *models.AttendanceCorrection) error {
+	query := `INSERT INTO attendance_corrections (
+				correction_id,
+				user_id,
+				session_id,
+				correction_type,
+				requested_login_at,
+				requested_logout_at,
+				work,
+				reason,
+				status
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		correction.CorrectionId,
+		correction.UserId,
+		correction.SessionId,
+		correction.CorrectionType,
+		correction.RequestedLoginAt,
+		correction.RequestedLogoutAt,
+		correction.Work,
+		correction.Reason,
+		correction.Status,
+	)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetUserAttendanceCorrectionsCount(userId string, status string) (int, error) {
+	query := `SELECT COUNT(*) FROM attendance_corrections WHERE user_id=$1 AND ($2='' OR status=$2)`
+	var count int
+	err := repo.pool.QueryRow(context.Background(), query, userId, status).Scan(&count)
+	return count, err
+}
+
+func (repo *PostgresRepo) GetUserAttendanceCorrections(userId string, status string, limit uint32, offset uint32) ([]*models.AttendanceCorrectionResponse, error) {
+	query := `SELECT` + attendanceCorrectionColumns + `
+			FROM attendance_corrections ac
+			JOIN users u ON ac.user_id=u.user_id
+			LEFT JOIN users_history uh ON ac.session_id=uh.session_id
+			WHERE ac.user_id=$1 AND ($2='' OR ac.status=$2)
+			ORDER BY ac.created_at DESC
+			LIMIT $3 OFFSET $4`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, status, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAttendanceCorrections(rows)
+}
+
+func (repo *PostgresRepo) GetPendingAttendanceCorrectionsCount(adminId string) (int, error) {
+	query := `SELECT
+				COUNT(*)
+			FROM attendance_corrections ac
+			JOIN users u ON ac.user_id=u.user_id
+			WHERE u.admin_id=$1 AND ac.status='pending'`
+	var count int
+	err := repo.pool.QueryRow(context.Background(), query, adminId).Scan(&count)
+	return count, err
+}
+
+func (repo *PostgresRepo) GetPendingAttendanceCorrections(adminId string, limit uint32, offset uint32) ([]*models.AttendanceCorrectionResponse, error) {
+	query := `SELECT` + attendanceCorrectionColumns + `
+			FROM attendance_corrections ac
+			JOIN users u ON ac.user_id=u.user_id
+			LEFT JOIN users_history uh ON ac.session_id=uh.session_id
+			WHERE u.admin_id=$1 AND ac.status='pending'
+			ORDER BY ac.created_at
+			LIMIT $2 OFFSET $3`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAttendanceCorrections(rows)
+}
+
+func (repo *PostgresRepo) CheckAttendanceCorrectionIdExists(correctionId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM attendance_corrections WHERE correction_id=$1 )`
+	var correctionExists bool
+	err := repo.pool.QueryRow(context.Background(), query, correctionId).Scan(&correctionExists)
+	return correctionExists, err
+}
+
+func (repo *PostgresRepo) GetAttendanceCorrection(correctionId string) (*models.AttendanceCorrection, error) {
+	query := `SELECT
+				correction_id,
+				user_id,
+				session_id,
+				correction_type,
+				requested_login_at,
+				requested_logout_at,
+				work,
+				reason,
+				status
+			FROM attendance_corrections WHERE correction_id=$1`
+
+	var correction models.AttendanceCorrection
+
+	err := repo.pool.QueryRow(context.Background(), query, correctionId).Scan(
+		&correction.CorrectionId,
+		&correction.UserId,
+		&correction.SessionId,
+		&correction.CorrectionType,
+		&correction.RequestedLoginAt,
+		&correction.RequestedLogoutAt,
+		&correction.Work,
+		&correction.Reason,
+		&correction.Status,
+	)
+
+	return &correction, err
+}
+
+// ApproveAttendanceCorrection rewrites the session and records the replaced values in the audit
+// trail. false is returned when the correction was already reviewed by someone else.
+func (repo *PostgresRepo) ApproveAttendanceCorrection(approval *models.AttendanceCorrectionApproval) (bool, error) {
+	query1 := `UPDATE attendance_corrections SET
+					status='approved',
+					reviewed_by=$2,
+					review_comment=$3,
+					reviewed_at=NOW()
+				WHERE correction_id=$1 AND status='pending'`
+
+	query2 := `INSERT INTO users_history (
+					session_id,
+					user_id,
+					work_date,
+					login_at,
+					logout_at,
+					uploaded_work,
+					corrected
+				) VALUES ($1,$2,$3::timestamptz::date,$3,$4,COALESCE(NULLIF($5,''),'pending'),true)`
+
+	query3 := `INSERT INTO users_history_audit (
+					audit_id,
+					session_id,
+					correction_id,
+					changed_by,
+					change_reason,
+					old_work_date,
+					old_login_at,
+					old_logout_at,
+					new_work_date,
+					new_login_at,
+					new_logout_at
+				)
+				SELECT
+					$1,$2,$3,$4,$5,
+					CASE WHEN $8 THEN NULL ELSE work_date END,
+					CASE WHEN $8 THEN NULL ELSE login_at END,
+					CASE WHEN $8 THEN NULL ELSE logout_at END,
+					$6::timestamptz::date,$6,$7
+				FROM users_history WHERE session_id=$2`
+
+	query4 := `UPDATE users_history SET
+					work_date=$2::timestamptz::date,
+					login_at=$2,
+					logout_at=$3,
+					uploaded_work=COALESCE(NULLIF($4,''),uploaded_work),
+					needs_review=false,
+					corrected=true
+				WHERE session_id=$1`
+
+	query5 := `UPDATE users SET login_status=false
+				WHERE user_id=$1
+				AND NOT EXISTS ( SELECT 1 FROM users_history WHERE user_id=$1 AND logout_at IS NULL )`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(
+		context.Background(),
+		query1,
+		approval.CorrectionId,
+		approval.ReviewedBy,
+		approval.ReviewComment,
+	)
+
+	if err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		tx.Rollback(context.Background())
+		return false, nil
+	}
+
+	if approval.NewSession {
+		if _, err := tx.Exec(
+			context.Background(),
+			query2,
+			approval.SessionId,
+			approval.UserId,
+			approval.LoginAt,
+			approval.LogoutAt,
+			approval.Work,
+		); err != nil {
+			tx.Rollback(context.Background())
+			return false, err
+		}
+
+		if _, err := tx.Exec(
+			context.Background(),
+			`UPDATE attendance_corrections SET session_id=$2 WHERE correction_id=$1`,
+			approval.CorrectionId,
+			approval.SessionId,
+		); err != nil {
+			tx.Rollback(context.Background())
+			return false, err
+		}
+	}
+
+	//the audit row is written before the rewrite so it captures the replaced values,
+	//a created session had nothing to replace and records empty old values
+	if _, err := tx.Exec(
+		context.Background(),
+		query3,
+		uuid.NewString(),
+		approval.SessionId,
+		approval.CorrectionId,
+		approval.ReviewedBy,
+		approval.Reason,
+		approval.LoginAt,
+		approval.LogoutAt,
+		approval.NewSession,
+	); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if !approval.NewSession {
+		if _, err := tx.Exec(
+			context.Background(),
+			query4,
+			approval.SessionId,
+			approval.LoginAt,
+			approval.LogoutAt,
+			approval.Work,
+		); err != nil {
+			tx.Rollback(context.Background())
+			return false, err
+		}
+	}
+
+	if _, err := tx.Exec(context.Background(), query5, approval.UserId); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *PostgresRepo) RejectAttendanceCorrection(correctionId string, reviewedBy string, reviewComment string) (bool, error) {
+	query := `UPDATE attendance_corrections SET
+				status='rejected',
+				reviewed_by=$2,
+				review_comment=$3,
+				reviewed_at=NOW()
+			WHERE correction_id=$1 AND status='pending'`
+	result, err := repo.pool.Exec(context.Background(), query, correctionId, reviewedBy, reviewComment)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (repo *PostgresRepo) GetWorkSessionAuditTrail(sessionId string) ([]*models.WorkSessionAuditResponse, error) {
+	query := `SELECT
+				audit_id,
+				session_id,
+				correction_id,
+				changed_by,
+				change_reason,
+				to_char(old_work_date,'YYYY-MM-DD'),
+				old_login_at,
+				old_logout_at,
+				to_char(new_work_date,'YYYY-MM-DD'),
+				new_login_at,
+				new_logout_at,
+				created_at
+			FROM users_history_audit
+			WHERE session_id=$1
+			ORDER BY created_at`
+
+	rows, err := repo.pool.Query(context.Background(), query, sessionId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var auditTrail []*models.WorkSessionAuditResponse
+
+	for rows.Next() {
+		var audit models.WorkSessionAuditResponse
+
+		if err := rows.Scan(
+			&audit.AuditId,
+			&audit.SessionId,
+			&audit.CorrectionId,
+			&audit.ChangedBy,
+			&audit.ChangeReason,
+			&audit.OldWorkDate,
+			&audit.OldLoginAt,
+			&audit.OldLogoutAt,
+			&audit.NewWorkDate,
+			&audit.NewLoginAt,
+			&audit.NewLogoutAt,
+			&audit.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		auditTrail = append(auditTrail, &audit)
+	}
+
+	return auditTrail, rows.Err()
+}
@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+// GetAttendanceUsers returns every user, or only the given user when the id is not empty
+func (repo *PostgresRepo) GetAttendanceUsers(userId string) ([]*models.AttendanceUser, error) {
+	query := `SELECT
+				u.user_id,
+				to_char(u.created_at,'YYYY-MM-DD'),
+				(SELECT to_char(MAX(ad.attendance_date),'YYYY-MM-DD') FROM attendance_days ad WHERE ad.user_id=u.user_id),
+				COALESCE(cas.shift_start_time,'09:00'),
+				COALESCE(cas.shift_end_time,'18:00'),
+				COALESCE(cas.break_minutes,0)
+			FROM users u
+			LEFT JOIN category_attendance_settings cas ON u.category_id=cas.category_id
+			WHERE $1='' OR u.user_id=$1
+			ORDER BY u.user_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []*models.AttendanceUser
+
+	for rows.Next() {
+		var user models.AttendanceUser
+
+		if err := rows.Scan(
+			&user.UserId,
+			&user.CreatedDate,
+			&user.LastComputedDate,
+			&user.ShiftStartTime,
+			&user.ShiftEndTime,
+			&user.BreakMinutes,
+		); err != nil {
+			return nil, err
+		}
+
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetAttendanceSessionDays sums the sessions of every work date between the two dates, the
+// lateness of each session is measured against its shift
+func (repo *PostgresRepo) GetAttendanceSessionDays(userId string, fromDate string, toDate string) ([]*models.AttendanceSessionDay, error) {
+	query := `SELECT
+				to_char(uh.work_date,'YYYY-MM-DD'),
+				COALESCE(EXTRACT(EPOCH FROM (uh.logout_at - uh.login_at)), 0)::float8,
+				uh.logout_at IS NULL OR uh.needs_review,
+				EXTRACT(EPOCH FROM uh.login_at::time)::int / 60,` + shiftSessionColumns + `
+			FROM users_history uh` + shiftMetricsJoin + `
+			WHERE uh.user_id=$1 AND uh.work_date BETWEEN $2::date AND $3::date
+			ORDER BY uh.work_date, uh.login_at`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessionDays []*models.AttendanceSessionDay
+	var workedSeconds []float64
+
+	for rows.Next() {
+		var date string
+		var sessionSeconds float64
+		var sessionOpen bool
+		var loginMinute int
+		var shiftSession models.ShiftSession
+
+		if err := rows.Scan(
+			&date,
+			&sessionSeconds,
+			&sessionOpen,
+			&loginMinute,
+			&shiftSession.LoginAt,
+			&shiftSession.LogoutAt,
+			&shiftSession.ShiftStartAt,
+			&shiftSession.ShiftMinutes,
+			&shiftSession.GraceMinutes,
+			&shiftSession.BreakMinutes,
+			&shiftSession.FirstSession,
+			&shiftSession.LastSession,
+		); err != nil {
+			return nil, err
+		}
+
+		//the sessions come in login order, so the first login of the day opens it
+		if len(sessionDays) == 0 || sessionDays[len(sessionDays)-1].Date != date {
+			sessionDays = append(sessionDays, &models.AttendanceSessionDay{
+				Date:             date,
+				FirstLoginMinute: &loginMinute,
+			})
+			workedSeconds = append(workedSeconds, 0)
+		}
+
+		sessionDay := sessionDays[len(sessionDays)-1]
+
+		if shiftSession.LogoutAt != nil {
+			workedSeconds[len(workedSeconds)-1] += sessionSeconds
+		}
+
+		sessionDay.HasOpenSession = sessionDay.HasOpenSession || sessionOpen
+		sessionDay.LateMinutes += utils.MeasureShiftSession(&shiftSession).LateMinutes
+	}
+
+	for index, sessionDay := range sessionDays {
+		sessionDay.WorkedMinutes = int(workedSeconds[index]) / 60
+	}
+
+	return sessionDays, rows.Err()
+}
+
+func (repo *PostgresRepo) GetUserGrantedLeavesBetween(userId string, fromDate string, toDate string) ([]*models.UserLeave, error) {
+	query := `SELECT
+				leave_id,
+				leave_type_id,
+				leave_from,
+				leave_to,
+				leave_granularity,
+				leave_start_time,
+				leave_end_time,
+				leave_minutes
+			FROM users_leave_history
+			WHERE user_id=$1
+			AND status='granted'
+			AND leave_from::date <= $3::date
+			AND leave_to::date >= $2::date
+			ORDER BY leave_from::date, leave_start_time`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var userLeaves []*models.UserLeave
+
+	for rows.Next() {
+		userLeave := models.UserLeave{UserId: userId, LeaveStatus: "granted"}
+
+		if err := rows.Scan(
+			&userLeave.LeaveId,
+			&userLeave.LeaveTypeId,
+			&userLeave.LeaveFrom,
+			&userLeave.LeaveTo,
+			&userLeave.LeaveGranularity,
+			&userLeave.LeaveStartTime,
+			&userLeave.LeaveEndTime,
+			&userLeave.LeaveMinutes,
+		); err != nil {
+			return nil, err
+		}
+
+		userLeaves = append(userLeaves, &userLeave)
+	}
+
+	return userLeaves, rows.Err()
+}
+
+// UpsertAttendanceDays replaces the computed days in one transaction
+func (repo *PostgresRepo) UpsertAttendanceDays(days []*models.AttendanceDay) error {
+	query := `INSERT INTO attendance_days (
+				user_id,
+				attendance_date,
+				status,
+				worked_minutes,
+				leave_minutes,
+				note,
+				first_login_minute,
+				late_minutes,
+				leave_type_id
+			) VALUES ($1,$2::date,$3,$4,$5,$6,$7,$8,$9)
+			ON CONFLICT (user_id, attendance_date) DO UPDATE SET
+				status=EXCLUDED.status,
+				worked_minutes=EXCLUDED.worked_minutes,
+				leave_minutes=EXCLUDED.leave_minutes,
+				note=EXCLUDED.note,
+				first_login_minute=EXCLUDED.first_login_minute,
+				late_minutes=EXCLUDED.late_minutes,
+				leave_type_id=EXCLUDED.leave_type_id,
+				computed_at=NOW()`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	for _, day := range days {
+		if _, err := tx.Exec(
+			context.Background(),
+			query,
+			day.UserId,
+			day.AttendanceDate,
+			day.Status,
+			day.WorkedMinutes,
+			day.LeaveMinutes,
+			day.Note,
+			day.FirstLoginMinute,
+			day.LateMinutes,
+			day.LeaveTypeId,
+		); err != nil {
+			tx.Rollback(context.Background())
+			return err
+		}
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// GetAttendanceMonth returns the users of the admin with the computed days of the "2006-01"
+// month, users without computed days are returned with no days
+func (repo *PostgresRepo) GetAttendanceMonth(adminId string, month string) ([]*models.UserAttendanceMonth, error) {
+	query := `SELECT
+				u.user_id,
+				u.name,
+				to_char(ad.attendance_date,'YYYY-MM-DD'),
+				ad.status,
+				ad.worked_minutes,
+				ad.leave_minutes,
+				ad.note
+			FROM users u
+			LEFT JOIN attendance_days ad ON ad.user_id=u.user_id
+				AND ad.attendance_date >= ($2 || '-01')::date
+				AND ad.attendance_date < ($2 || '-01')::date + interval '1 month'
+			WHERE u.admin_id=$1
+			ORDER BY u.name, u.user_id, ad.attendance_date`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, month)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []*models.UserAttendanceMonth
+
+	for rows.Next() {
+		var userId, name string
+		var date, status *string
+		var workedMinutes, leaveMinutes *int32
+		var note *string
+
+		if err := rows.Scan(
+			&userId,
+			&name,
+			&date,
+			&status,
+			&workedMinutes,
+			&leaveMinutes,
+			&note,
+		); err != nil {
+			return nil, err
+		}
+
+		if len(users) == 0 || users[len(users)-1].UserId != userId {
+			users = append(users, &models.UserAttendanceMonth{
+				UserId: userId,
+				Name:   name,
+			})
+		}
+
+		if date == nil {
+			continue
+		}
+
+		user := users[len(users)-1]
+
+		user.Days = append(user.Days, &models.AttendanceDayResponse{
+			Date:          *date,
+			Status:        *status,
+			WorkedMinutes: *workedMinutes,
+			LeaveMinutes:  *leaveMinutes,
+			Note:          note,
+		})
+	}
+
+	return users, rows.Err()
+}
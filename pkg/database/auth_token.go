@@ -0,0 +1,234 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func (repo *PostgresRepo) CreateRefreshToken(token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (
+				token_id,
+				family_id,
+				admin_id,
+				user_id,
+				token_hash,
+				expires_at
+			) VALUES ($1,$2,$3,$4,$5,$6)`
+
+	_, err := repo.pool.Exec(context.Background(), query, token.TokenId, token.FamilyId, token.AdminId, token.UserId, token.TokenHash, token.ExpiresAt)
+
+	return err
+}
+
+// RotateRefreshToken replaces the active token with next, which joins the family of the token.
+// a token that was already replaced revokes its whole family, as either the holder or a thief
+// already used it. the subject is returned with the result.
+func (repo *PostgresRepo) RotateRefreshToken(tokenHash string, next *models.RefreshToken) (*models.TokenSubject, string, error) {
+	tokenQuery := `SELECT
+					t.token_id,
+					t.family_id,
+					t.admin_id,
+					t.user_id,
+					t.expires_at,
+					t.revoked_reason,
+					COALESCE(u.email, a.email),
+					COALESCE(u.name, a.name)
+				FROM refresh_tokens t
+				JOIN admins a ON a.admin_id=t.admin_id
+				LEFT JOIN users u ON u.user_id=t.user_id
+				WHERE t.token_hash=$1
+				FOR UPDATE OF t`
+
+	revokeFamilyQuery := `UPDATE refresh_tokens SET
+					revoked_at=NOW(),
+					revoked_reason='reuse_detected'
+				WHERE family_id=$1 AND revoked_at IS NULL`
+
+	rotateQuery := `UPDATE refresh_tokens SET
+					revoked_at=NOW(),
+					revoked_reason='rotated',
+					replaced_by=$2
+				WHERE token_id=$1`
+
+	insertQuery := `INSERT INTO refresh_tokens (
+					token_id,
+					family_id,
+					admin_id,
+					user_id,
+					token_hash,
+					expires_at
+				) VALUES ($1,$2,$3,$4,$5,$6)`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	var tokenId, familyId string
+	var expiresAt time.Time
+	var revokedReason *string
+	var subject models.TokenSubject
+
+	if err := tx.QueryRow(context.Background(), tokenQuery, tokenHash).Scan(
+		&tokenId,
+		&familyId,
+		&subject.AdminId,
+		&subject.UserId,
+		&expiresAt,
+		&revokedReason,
+		&subject.Email,
+		&subject.Name,
+	); err != nil {
+		tx.Rollback(context.Background())
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.RefreshTokenInvalid, nil
+		}
+
+		return nil, "", err
+	}
+
+	if revokedReason != nil && *revokedReason == "rotated" {
+		if _, err := tx.Exec(context.Background(), revokeFamilyQuery, familyId); err != nil {
+			tx.Rollback(context.Background())
+			return nil, "", err
+		}
+
+		if err := tx.Commit(context.Background()); err != nil {
+			tx.Rollback(context.Background())
+			return nil, "", err
+		}
+
+		return &subject, models.RefreshTokenReused, nil
+	}
+
+	if revokedReason != nil {
+		tx.Rollback(context.Background())
+		return &subject, models.RefreshTokenRevoked, nil
+	}
+
+	if !expiresAt.After(time.Now()) {
+		tx.Rollback(context.Background())
+		return &subject, models.RefreshTokenExpired, nil
+	}
+
+	if _, err := tx.Exec(context.Background(), rotateQuery, tokenId, next.TokenId); err != nil {
+		tx.Rollback(context.Background())
+		return nil, "", err
+	}
+
+	if _, err := tx.Exec(
+		context.Background(),
+		insertQuery,
+		next.TokenId,
+		familyId,
+		subject.AdminId,
+		subject.UserId,
+		next.TokenHash,
+		next.ExpiresAt,
+	); err != nil {
+		tx.Rollback(context.Background())
+		return nil, "", err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return nil, "", err
+	}
+
+	next.FamilyId = familyId
+	next.AdminId = subject.AdminId
+	next.UserId = subject.UserId
+
+	return &subject, models.RefreshTokenRotated, nil
+}
+
+// RevokeRefreshTokenFamily logs out the device of the token by revoking every token of its family
+func (repo *PostgresRepo) RevokeRefreshTokenFamily(tokenHash string) error {
+	query := `UPDATE refresh_tokens SET
+				revoked_at=NOW(),
+				revoked_reason='logout'
+			WHERE family_id=(SELECT family_id FROM refresh_tokens WHERE token_hash=$1)
+			AND revoked_at IS NULL`
+
+	_, err := repo.pool.Exec(context.Background(), query, tokenHash)
+
+	return err
+}
+
+// RevokeUserSessions revokes every refresh token of the user and refuses the access tokens issued
+// so far
+func (repo *PostgresRepo) RevokeUserSessions(userId string) error {
+	tokensQuery := `UPDATE refresh_tokens SET
+					revoked_at=NOW(),
+					revoked_reason='revoked_by_admin'
+				WHERE user_id=$1 AND revoked_at IS NULL`
+
+	revocationQuery := `INSERT INTO user_session_revocations (user_id, revoked_at)
+				VALUES ($1, NOW())
+				ON CONFLICT (user_id) DO UPDATE SET revoked_at=EXCLUDED.revoked_at`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), tokensQuery, userId); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), revocationQuery, userId); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens removes the tokens of the families whose every token expired. a
+// family is kept while one of its tokens is live, presenting one of its replaced tokens still
+// has to revoke the live one.
+func (repo *PostgresRepo) DeleteExpiredRefreshTokens() (int64, error) {
+	query := `DELETE FROM refresh_tokens t
+			WHERE t.expires_at < NOW()
+			AND NOT EXISTS (
+				SELECT 1 FROM refresh_tokens f
+				WHERE f.family_id=t.family_id AND f.expires_at >= NOW()
+			)`
+
+	commandTag, err := repo.pool.Exec(context.Background(), query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
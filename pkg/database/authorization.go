@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// owner queries of the resources, each selects the owning admin id, for user scoped resources
+// the owning user id, and the category of the resource or of its user
+var resourceOwnerQueries = map[string]string{
+	models.ResourceAdmin:           `SELECT admin_id, NULL::varchar, NULL::varchar FROM admins WHERE admin_id=$1`,
+	models.ResourceUser:            `SELECT admin_id, user_id, category_id FROM users WHERE user_id=$1`,
+	models.ResourceCategory:        `SELECT admin_id, NULL::varchar, category_id FROM employee_category WHERE category_id=$1`,
+	models.ResourceLeave:           `SELECT u.admin_id, u.user_id, u.category_id FROM users_leave_history l JOIN users u ON u.user_id=l.user_id WHERE l.leave_id=$1`,
+	models.ResourceLeaveType:       `SELECT admin_id, NULL::varchar, category_id FROM leave_types WHERE leave_type_id=$1`,
+	models.ResourceWorkSession:     `SELECT u.admin_id, u.user_id, u.category_id FROM users_history h JOIN users u ON u.user_id=h.user_id WHERE h.session_id=$1 LIMIT 1`,
+	models.ResourceCorrection:      `SELECT u.admin_id, u.user_id, u.category_id FROM attendance_corrections c JOIN users u ON u.user_id=c.user_id WHERE c.correction_id=$1`,
+	models.ResourceHoliday:         `SELECT admin_id, NULL::varchar, category_id FROM holidays WHERE holiday_id=$1`,
+	models.ResourceWeeklyOffRule:   `SELECT admin_id, NULL::varchar, category_id FROM weekly_off_rules WHERE rule_id=$1`,
+	models.ResourceWorkSite:        `SELECT admin_id, NULL::varchar, NULL::varchar FROM work_sites WHERE site_id=$1`,
+	models.ResourceSiteAssignment:  `SELECT s.admin_id, NULL::varchar, COALESCE(a.category_id, u.category_id) FROM work_site_assignments a JOIN work_sites s ON s.site_id=a.site_id LEFT JOIN users u ON u.user_id=a.user_id WHERE a.assignment_id=$1`,
+	models.ResourceReportJob:       `SELECT admin_id, NULL::varchar, category_id FROM report_jobs WHERE job_id=$1`,
+	models.ResourceWebhook:         `SELECT admin_id, NULL::varchar, NULL::varchar FROM webhooks WHERE webhook_id=$1`,
+	models.ResourceWebhookDelivery: `SELECT w.admin_id, NULL::varchar, NULL::varchar FROM webhook_deliveries d JOIN webhooks w ON w.webhook_id=d.webhook_id WHERE d.delivery_id=$1`,
+	models.ResourceRole:            `SELECT admin_id, NULL::varchar, NULL::varchar FROM roles WHERE role_id=$1`,
+	models.ResourceApprovalChain:   `SELECT admin_id, NULL::varchar, category_id FROM leave_approval_chains WHERE chain_id=$1`,
+}
+
+// GetResourceOwner returns the owners of the resource, nil when the resource does not exist
+func (repo *PostgresRepo) GetResourceOwner(resource string, resourceId string) (*models.ResourceOwner, error) {
+	query, ok := resourceOwnerQueries[resource]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown resource %s", resource)
+	}
+
+	var owner models.ResourceOwner
+
+	if err := repo.pool.QueryRow(context.Background(), query, resourceId).Scan(&owner.AdminId, &owner.UserId, &owner.CategoryId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+// GetTokenOwner returns the admin of the token subject and, for a user, when the sessions of the
+// user were last revoked. nil is returned when the subject does not exist.
+func (repo *PostgresRepo) GetTokenOwner(role string, subjectId string) (*models.TokenOwner, error) {
+	query := `SELECT admin_id, NULL::timestamptz FROM admins WHERE admin_id=$1`
+
+	if role == models.RoleUser {
+		query = `SELECT u.admin_id, r.revoked_at
+				FROM users u
+				LEFT JOIN user_session_revocations r ON r.user_id=u.user_id
+				WHERE u.user_id=$1`
+	}
+
+	var owner models.TokenOwner
+
+	if err := repo.pool.QueryRow(context.Background(), query, subjectId).Scan(&owner.AdminId, &owner.SessionsRevokedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+// GetUserPermissionGrant returns the grant of the permission through the roles of the user, nil
+// when no role grants it. the grant is only category scoped when every granting role is.
+func (repo *PostgresRepo) GetUserPermissionGrant(userId string, permission string) (*models.PermissionGrant, error) {
+	query := `SELECT
+				bool_and(r.category_scoped),
+				ARRAY(SELECT category_id FROM user_managed_categories WHERE user_id=$1)
+			FROM user_roles ur
+			JOIN roles r ON r.role_id=ur.role_id
+			WHERE ur.user_id=$1 AND $2=ANY(r.permissions)`
+
+	var categoryScoped *bool
+	var managedCategoryIds []string
+
+	if err := repo.pool.QueryRow(context.Background(), query, userId, permission).Scan(&categoryScoped, &managedCategoryIds); err != nil {
+		return nil, err
+	}
+
+	if categoryScoped == nil {
+		return nil, nil
+	}
+
+	return &models.PermissionGrant{
+		CategoryScoped:     *categoryScoped,
+		ManagedCategoryIds: managedCategoryIds,
+	}, nil
+}
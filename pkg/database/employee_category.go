@@ -57,3 +57,65 @@ func (repo *PostgresRepo) DeleteEmployeeCategory(categoryId string) error {
 	_, err := repo.pool.Exec(context.Background(), query, categoryId)
 	return err
 }
+
+func (repo *PostgresRepo) UpsertCategoryAttendanceSettings(settings *models.CategoryAttendanceSettingsRequest) error {
+	query := `INSERT INTO category_attendance_settings (
+				category_id,
+				shift_start_time,
+				shift_end_time,
+				grace_minutes,
+				break_minutes,
+				auto_close_after_minutes,
+				auto_close_policy
+			) VALUES ($1,$2,$3,$4,$5,$6,$7)
+			ON CONFLICT (category_id) DO UPDATE SET
+				shift_start_time=EXCLUDED.shift_start_time,
+				shift_end_time=EXCLUDED.shift_end_time,
+				grace_minutes=EXCLUDED.grace_minutes,
+				break_minutes=EXCLUDED.break_minutes,
+				auto_close_after_minutes=EXCLUDED.auto_close_after_minutes,
+				auto_close_policy=EXCLUDED.auto_close_policy`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		settings.CategoryId,
+		settings.ShiftStartTime,
+		settings.ShiftEndTime,
+		settings.GraceMinutes,
+		settings.BreakMinutes,
+		settings.AutoCloseAfterMinutes,
+		settings.AutoClosePolicy,
+	)
+
+	return err
+}
+
+// GetCategoryAttendanceSettings falls back to the column defaults when the category was never configured
+func (repo *PostgresRepo) GetCategoryAttendanceSettings(categoryId string) (*models.CategoryAttendanceSettingsResponse, error) {
+	query := `SELECT
+				ec.category_id,
+				COALESCE(cas.shift_start_time,'09:00'),
+				COALESCE(cas.shift_end_time,'18:00'),
+				COALESCE(cas.grace_minutes,0),
+				COALESCE(cas.break_minutes,0),
+				COALESCE(cas.auto_close_after_minutes,720),
+				COALESCE(cas.auto_close_policy,'end_of_shift')
+			FROM employee_category ec
+			LEFT JOIN category_attendance_settings cas ON ec.category_id=cas.category_id
+			WHERE ec.category_id=$1`
+
+	var settings models.CategoryAttendanceSettingsResponse
+
+	err := repo.pool.QueryRow(context.Background(), query, categoryId).Scan(
+		&settings.CategoryId,
+		&settings.ShiftStartTime,
+		&settings.ShiftEndTime,
+		&settings.GraceMinutes,
+		&settings.BreakMinutes,
+		&settings.AutoCloseAfterMinutes,
+		&settings.AutoClosePolicy,
+	)
+
+	return &settings, err
+}
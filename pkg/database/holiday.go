@@ -0,0 +1,345 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func (repo *PostgresRepo) CheckHolidayExists(adminId string, categoryId *string, holidayDate string) (bool, error) {
+	query := `SELECT EXISTS (
+				SELECT 1 FROM holidays
+				WHERE admin_id=$1 AND COALESCE(category_id,'')=COALESCE($2,'') AND holiday_date=$3::date
+			)`
+	var holidayExists bool
+	err := repo.pool.QueryRow(context.Background(), query, adminId, categoryId, holidayDate).Scan(&holidayExists)
+	return holidayExists, err
+}
+
+func (repo *PostgresRepo) CreateHoliday(holiday *models.Holiday) error {
+	query := `INSERT INTO holidays (
+				holiday_id,
+				admin_id,
+				category_id,
+				holiday_date,
+				holiday_name,
+				source,
+				ics_uid
+			) VALUES ($1,$2,$3,$4::date,$5,$6,$7)`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		holiday.HolidayId,
+		holiday.AdminId,
+		holiday.CategoryId,
+		holiday.HolidayDate,
+		holiday.HolidayName,
+		holiday.Source,
+		holiday.IcsUid,
+	)
+
+	return err
+}
+
+// ImportHolidays inserts the holidays in one transaction, dates that already have a holiday
+// are skipped. the number of inserted holidays is returned.
+func (repo *PostgresRepo) ImportHolidays(holidays []*models.Holiday) (int, error) {
+	query := `INSERT INTO holidays (
+				holiday_id,
+				admin_id,
+				category_id,
+				holiday_date,
+				holiday_name,
+				source,
+				ics_uid
+			) VALUES ($1,$2,$3,$4::date,$5,$6,$7)
+			ON CONFLICT DO NOTHING`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+
+	for _, holiday := range holidays {
+		result, err := tx.Exec(
+			context.Background(),
+			query,
+			holiday.HolidayId,
+			holiday.AdminId,
+			holiday.CategoryId,
+			holiday.HolidayDate,
+			holiday.HolidayName,
+			holiday.Source,
+			holiday.IcsUid,
+		)
+
+		if err != nil {
+			tx.Rollback(context.Background())
+			return 0, err
+		}
+
+		imported += int(result.RowsAffected())
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	return imported, nil
+}
+
+func (repo *PostgresRepo) GetHolidays(adminId string, year string) ([]*models.HolidayResponse, error) {
+	query := `SELECT
+				h.holiday_id,
+				h.category_id,
+				ec.category_name,
+				to_char(h.holiday_date,'YYYY-MM-DD'),
+				h.holiday_name,
+				h.source,
+				h.created_at
+			FROM holidays h
+			LEFT JOIN employee_category ec ON h.category_id=ec.category_id
+			WHERE h.admin_id=$1 AND ($2='' OR to_char(h.holiday_date,'YYYY')=$2)
+			ORDER BY h.holiday_date`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, year)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanHolidays(rows)
+}
+
+// GetUserHolidays returns the holidays of the user's admin that apply to the user's category
+func (repo *PostgresRepo) GetUserHolidays(userId string, year string) ([]*models.HolidayResponse, error) {
+	query := `SELECT
+				h.holiday_id,
+				h.category_id,
+				ec.category_name,
+				to_char(h.holiday_date,'YYYY-MM-DD'),
+				h.holiday_name,
+				h.source,
+				h.created_at
+			FROM users u
+			JOIN holidays h ON h.admin_id=u.admin_id AND (h.category_id IS NULL OR h.category_id=u.category_id)
+			LEFT JOIN employee_category ec ON h.category_id=ec.category_id
+			WHERE u.user_id=$1 AND ($2='' OR to_char(h.holiday_date,'YYYY')=$2)
+			ORDER BY h.holiday_date`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, year)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanHolidays(rows)
+}
+
+func scanHolidays(rows pgx.Rows) ([]*models.HolidayResponse, error) {
+	defer rows.Close()
+
+	var holidays []*models.HolidayResponse
+
+	for rows.Next() {
+		var holiday models.HolidayResponse
+
+		if err := rows.Scan(
+			&holiday.HolidayId,
+			&holiday.CategoryId,
+			&holiday.CategoryName,
+			&holiday.HolidayDate,
+			&holiday.HolidayName,
+			&holiday.Source,
+			&holiday.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		holidays = append(holidays, &holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckHolidayIdExists(holidayId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM holidays WHERE holiday_id=$1 )`
+	var holidayExists bool
+	err := repo.pool.QueryRow(context.Background(), query, holidayId).Scan(&holidayExists)
+	return holidayExists, err
+}
+
+func (repo *PostgresRepo) DeleteHoliday(holidayId string) error {
+	query := `DELETE FROM holidays WHERE holiday_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, holidayId)
+	return err
+}
+
+// UpsertWeeklyOffRule keeps one rule per weekday for the admin, or for the category when given
+func (repo *PostgresRepo) UpsertWeeklyOffRule(rule *models.WeeklyOffRule) error {
+	query := `INSERT INTO weekly_off_rules (
+				rule_id,
+				admin_id,
+				category_id,
+				weekday,
+				week_numbers
+			) VALUES ($1,$2,$3,$4,$5)
+			ON CONFLICT (admin_id, (COALESCE(category_id,'')), weekday) DO UPDATE SET
+				week_numbers=EXCLUDED.week_numbers`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		rule.RuleId,
+		rule.AdminId,
+		rule.CategoryId,
+		rule.Weekday,
+		rule.WeekNumbers,
+	)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetWeeklyOffRules(adminId string) ([]*models.WeeklyOffRuleResponse, error) {
+	query := `SELECT
+				wr.rule_id,
+				wr.category_id,
+				ec.category_name,
+				wr.weekday,
+				wr.week_numbers,
+				wr.updated_at
+			FROM weekly_off_rules wr
+			LEFT JOIN employee_category ec ON wr.category_id=ec.category_id
+			WHERE wr.admin_id=$1
+			ORDER BY wr.category_id NULLS FIRST, wr.weekday`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var rules []*models.WeeklyOffRuleResponse
+
+	for rows.Next() {
+		var rule models.WeeklyOffRuleResponse
+
+		if err := rows.Scan(
+			&rule.RuleId,
+			&rule.CategoryId,
+			&rule.CategoryName,
+			&rule.Weekday,
+			&rule.WeekNumbers,
+			&rule.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckWeeklyOffRuleIdExists(ruleId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM weekly_off_rules WHERE rule_id=$1 )`
+	var ruleExists bool
+	err := repo.pool.QueryRow(context.Background(), query, ruleId).Scan(&ruleExists)
+	return ruleExists, err
+}
+
+func (repo *PostgresRepo) DeleteWeeklyOffRule(ruleId string) error {
+	query := `DELETE FROM weekly_off_rules WHERE rule_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, ruleId)
+	return err
+}
+
+// GetUserWorkCalendar returns the holidays between the two dates and the weekly offs that
+// apply to the user's category. a category holiday replaces an admin wide one of the same date.
+func (repo *PostgresRepo) GetUserWorkCalendar(userId string, fromDate string, toDate string) (*models.WorkCalendar, error) {
+	query1 := `SELECT
+					to_char(h.holiday_date,'YYYY-MM-DD'),
+					h.holiday_name
+				FROM users u
+				JOIN holidays h ON h.admin_id=u.admin_id AND (h.category_id IS NULL OR h.category_id=u.category_id)
+				WHERE u.user_id=$1 AND h.holiday_date BETWEEN $2::date AND $3::date
+				ORDER BY h.category_id NULLS FIRST`
+
+	query2 := `SELECT
+					wr.rule_id,
+					wr.admin_id,
+					wr.category_id,
+					wr.weekday,
+					wr.week_numbers
+				FROM users u
+				JOIN weekly_off_rules wr ON wr.admin_id=u.admin_id AND (wr.category_id IS NULL OR wr.category_id=u.category_id)
+				WHERE u.user_id=$1`
+
+	calendar := &models.WorkCalendar{
+		Holidays: make(map[string]string),
+	}
+
+	rows, err := repo.pool.Query(context.Background(), query1, userId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var holidayDate, holidayName string
+
+		if err := rows.Scan(&holidayDate, &holidayName); err != nil {
+			return nil, err
+		}
+
+		calendar.Holidays[holidayDate] = holidayName
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = repo.pool.Query(context.Background(), query2, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule models.WeeklyOffRule
+
+		if err := rows.Scan(
+			&rule.RuleId,
+			&rule.AdminId,
+			&rule.CategoryId,
+			&rule.Weekday,
+			&rule.WeekNumbers,
+		); err != nil {
+			return nil, err
+		}
+
+		calendar.WeeklyOffs = append(calendar.WeeklyOffs, &rule)
+	}
+
+	return calendar, rows.Err()
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// CreateIssuedReport stores the report with the admin of the user at the time of issue
+func (repo *PostgresRepo) CreateIssuedReport(report *models.IssuedReport) error {
+	query := `INSERT INTO issued_reports (
+				report_id,
+				user_id,
+				admin_id,
+				user_name,
+				category_name,
+				start_date,
+				end_date,
+				sha256,
+				signature,
+				issued_at
+			) SELECT $1, $2, u.admin_id, $3, $4, $5::date, $6::date, $7, $8, $9
+			FROM users u
+			WHERE u.user_id=$2`
+
+	tag, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		report.ReportId,
+		report.UserId,
+		report.UserName,
+		report.CategoryName,
+		report.StartDate,
+		report.EndDate,
+		report.Sha256,
+		report.Signature,
+		report.IssuedAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.New("user id not exists")
+	}
+
+	return nil
+}
+
+// GetUserCategoryName returns the name of the category of the user, empty without a category
+func (repo *PostgresRepo) GetUserCategoryName(userId string) (string, error) {
+	query := `SELECT COALESCE(c.category_name,'')
+			FROM users u
+			LEFT JOIN employee_category c ON c.category_id=u.category_id
+			WHERE u.user_id=$1`
+
+	var categoryName string
+
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&categoryName)
+
+	return categoryName, err
+}
+
+func (repo *PostgresRepo) GetIssuedReport(reportId string) (*models.IssuedReport, error) {
+	query := `SELECT
+				report_id,
+				COALESCE(user_id,''),
+				user_name,
+				category_name,
+				to_char(start_date,'YYYY-MM-DD'),
+				to_char(end_date,'YYYY-MM-DD'),
+				sha256,
+				signature,
+				issued_at
+			FROM issued_reports
+			WHERE report_id=$1`
+
+	var report models.IssuedReport
+
+	if err := repo.pool.QueryRow(context.Background(), query, reportId).Scan(
+		&report.ReportId,
+		&report.UserId,
+		&report.UserName,
+		&report.CategoryName,
+		&report.StartDate,
+		&report.EndDate,
+		&report.Sha256,
+		&report.Signature,
+		&report.IssuedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &report, nil
+}
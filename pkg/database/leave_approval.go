@@ -0,0 +1,455 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// GetLeaveApprovalChainSteps returns the steps of the chain of the category of the user, or of
+// the default chain of the admin, nil when the admin has no chain
+func (repo *PostgresRepo) GetLeaveApprovalChainSteps(userId string) ([]string, error) {
+	query := `SELECT c.steps
+			FROM users u
+			JOIN leave_approval_chains c ON c.admin_id=u.admin_id AND (c.category_id=u.category_id OR c.category_id IS NULL)
+			WHERE u.user_id=$1
+			ORDER BY c.category_id IS NULL
+			LIMIT 1`
+
+	var steps []string
+
+	if err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&steps); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// GetUserReportingManagerId returns nil when the user has no reporting manager
+func (repo *PostgresRepo) GetUserReportingManagerId(userId string) (*string, error) {
+	query := `SELECT manager_id FROM user_reporting_managers WHERE user_id=$1`
+
+	var managerId string
+
+	if err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&managerId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &managerId, nil
+}
+
+// CheckReportingLineCycle reports whether the user is the manager id itself or one of its
+// managers up the reporting line
+func (repo *PostgresRepo) CheckReportingLineCycle(userId string, managerId string) (bool, error) {
+	query := `WITH RECURSIVE reporting_line AS (
+				SELECT $2::varchar AS manager_id
+				UNION
+				SELECT rm.manager_id
+				FROM user_reporting_managers rm
+				JOIN reporting_line l ON rm.user_id=l.manager_id
+			)
+			SELECT EXISTS ( SELECT 1 FROM reporting_line WHERE manager_id=$1 )`
+
+	var cycle bool
+	err := repo.pool.QueryRow(context.Background(), query, userId, managerId).Scan(&cycle)
+	return cycle, err
+}
+
+// SetUserReportingManager sets or, with a nil manager id, removes the reporting manager of the
+// user. the pending reporting manager steps of the user move to the new manager, or to the admin
+// when the manager is removed.
+func (repo *PostgresRepo) SetUserReportingManager(userId string, managerId *string) error {
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM user_reporting_managers WHERE user_id=$1`
+	args := []interface{}{userId}
+
+	if managerId != nil {
+		query = `INSERT INTO user_reporting_managers (user_id, manager_id) VALUES ($1,$2)
+				ON CONFLICT (user_id) DO UPDATE SET manager_id=EXCLUDED.manager_id`
+		args = append(args, *managerId)
+	}
+
+	if _, err := tx.Exec(context.Background(), query, args...); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	stepsQuery := `UPDATE leave_approval_steps s SET approver_user_id=$2
+				FROM users_leave_history l
+				WHERE l.leave_id=s.leave_id AND l.user_id=$1 AND l.status='pending'
+				AND s.approver_type='reporting_manager' AND s.status='pending'`
+
+	if _, err := tx.Exec(context.Background(), stepsQuery, userId, managerId); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// UpsertLeaveApprovalChain stores the chain of the admin and category, replacing the steps of an
+// existing one, and returns the chain id
+func (repo *PostgresRepo) UpsertLeaveApprovalChain(chain *models.LeaveApprovalChain) (string, error) {
+	query := `INSERT INTO leave_approval_chains (chain_id, admin_id, category_id, steps) VALUES ($1,$2,$3,$4)
+			ON CONFLICT (admin_id, (COALESCE(category_id,''))) DO UPDATE SET steps=EXCLUDED.steps
+			RETURNING chain_id`
+
+	var chainId string
+	err := repo.pool.QueryRow(context.Background(), query, chain.ChainId, chain.AdminId, chain.CategoryId, chain.Steps).Scan(&chainId)
+	return chainId, err
+}
+
+func (repo *PostgresRepo) GetLeaveApprovalChains(adminId string) ([]*models.LeaveApprovalChainResponse, error) {
+	query := `SELECT chain_id, category_id, steps, updated_at FROM leave_approval_chains WHERE admin_id=$1 ORDER BY category_id NULLS FIRST`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var chains []*models.LeaveApprovalChainResponse
+
+	for rows.Next() {
+		var chain models.LeaveApprovalChainResponse
+
+		if err := rows.Scan(
+			&chain.ChainId,
+			&chain.CategoryId,
+			&chain.Steps,
+			&chain.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		chains = append(chains, &chain)
+	}
+
+	return chains, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckLeaveApprovalChainIdExists(chainId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM leave_approval_chains WHERE chain_id=$1 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, chainId).Scan(&exists)
+	return exists, err
+}
+
+func (repo *PostgresRepo) DeleteLeaveApprovalChain(chainId string) error {
+	query := `DELETE FROM leave_approval_chains WHERE chain_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, chainId)
+	return err
+}
+
+// GetCurrentLeaveApprovalStep returns the step of the pending leave waiting on a decision and
+// whether it is the last one, nil when the leave is not pending
+func (repo *PostgresRepo) GetCurrentLeaveApprovalStep(leaveId string) (*models.LeaveApprovalStep, bool, error) {
+	query := `SELECT
+				s.leave_id,
+				s.step_number,
+				s.approver_type,
+				s.approver_user_id,
+				NOT EXISTS ( SELECT 1 FROM leave_approval_steps n WHERE n.leave_id=s.leave_id AND n.step_number>s.step_number AND n.status='pending' )
+			FROM leave_approval_steps s
+			JOIN users_leave_history l ON l.leave_id=s.leave_id
+			WHERE s.leave_id=$1 AND s.status='pending' AND l.status='pending'
+			ORDER BY s.step_number
+			LIMIT 1`
+
+	var step models.LeaveApprovalStep
+	var isLast bool
+
+	if err := repo.pool.QueryRow(context.Background(), query, leaveId).Scan(
+		&step.LeaveId,
+		&step.StepNumber,
+		&step.ApproverType,
+		&step.ApproverUserId,
+		&isLast,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return &step, isLast, nil
+}
+
+// DecideLeaveApprovalStep records the decision on the step while it is still pending. a
+// rejection cancels the leave and skips the steps after it, the approval of the last step grants
+// the leave and debits the balance of its leave type. the conflict result is returned when the
+// step was decided in the meantime or the balance is not enough.
+func (repo *PostgresRepo) DecideLeaveApprovalStep(step *models.LeaveApprovalStep, decision *models.LeaveDecision) (string, error) {
+	stepStatus := "rejected"
+
+	if decision.Approved {
+		stepStatus = "approved"
+	}
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return "", err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return "", err
+	}
+
+	query := `UPDATE leave_approval_steps SET
+				status=$3,
+				decided_by=$4,
+				decided_by_type=$5,
+				decided_at=NOW(),
+				comment=$6
+			WHERE leave_id=$1 AND step_number=$2 AND status='pending'`
+
+	result, err := tx.Exec(context.Background(), query, step.LeaveId, step.StepNumber, stepStatus, decision.DecidedBy, decision.DecidedByType, decision.Comment)
+
+	if err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	if result.RowsAffected() == 0 {
+		tx.Rollback(context.Background())
+		return models.LeaveDecisionConflict, nil
+	}
+
+	var pendingSteps bool
+
+	if err := tx.QueryRow(context.Background(), `SELECT EXISTS ( SELECT 1 FROM leave_approval_steps WHERE leave_id=$1 AND status='pending' )`, step.LeaveId).Scan(&pendingSteps); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	leaveResult := models.LeaveDecisionApproved
+
+	switch {
+	case !decision.Approved:
+		leaveResult = models.LeaveDecisionRejected
+
+		if _, err := tx.Exec(context.Background(), `UPDATE leave_approval_steps SET status='skipped' WHERE leave_id=$1 AND status='pending'`, step.LeaveId); err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+
+		result, err := tx.Exec(context.Background(), `UPDATE users_leave_history SET status='canceled',status_updated_by=$2 WHERE leave_id=$1 AND status='pending'`, step.LeaveId, decision.DecidedByType)
+
+		if err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+
+		if result.RowsAffected() == 0 {
+			tx.Rollback(context.Background())
+			return models.LeaveDecisionConflict, nil
+		}
+	case !pendingSteps:
+		leaveResult = models.LeaveDecisionGranted
+
+		grantResult, err := grantUserLeave(tx, step.LeaveId, decision.DecidedByType)
+
+		if err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+
+		if grantResult != models.LeaveDecisionGranted {
+			tx.Rollback(context.Background())
+			return grantResult, nil
+		}
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	return leaveResult, nil
+}
+
+// grantUserLeave grants the pending leave and debits the balance of its leave type. a conflict is
+// returned when the leave is no longer pending, and insufficient balance when the balance is not
+// enough and the leave type does not allow a negative balance.
+func grantUserLeave(tx pgx.Tx, leaveId string, updatedBy string) (string, error) {
+	query := `UPDATE users_leave_history SET status='granted',status_updated_by=$2
+				WHERE leave_id=$1 AND status='pending'
+				RETURNING user_id,leave_type_id,leave_days`
+
+	userLeave := models.UserLeave{LeaveId: leaveId}
+
+	if err := tx.QueryRow(context.Background(), query, leaveId, updatedBy).Scan(
+		&userLeave.UserId,
+		&userLeave.LeaveTypeId,
+		&userLeave.LeaveDays,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.LeaveDecisionConflict, nil
+		}
+
+		return "", err
+	}
+
+	if userLeave.LeaveTypeId == nil {
+		return models.LeaveDecisionGranted, nil
+	}
+
+	debited, err := debitLeaveBalance(tx, &userLeave)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !debited {
+		return models.LeaveDecisionInsufficientBalance, nil
+	}
+
+	return models.LeaveDecisionGranted, nil
+}
+
+func (repo *PostgresRepo) GetLeaveApprovalSteps(leaveId string) ([]*models.LeaveApprovalStepResponse, error) {
+	query := `SELECT
+				s.step_number,
+				s.approver_type,
+				s.approver_user_id,
+				u.name,
+				s.status,
+				s.decided_by,
+				s.decided_by_type::text,
+				s.decided_at,
+				s.comment
+			FROM leave_approval_steps s
+			LEFT JOIN users u ON u.user_id=s.approver_user_id
+			WHERE s.leave_id=$1
+			ORDER BY s.step_number`
+
+	rows, err := repo.pool.Query(context.Background(), query, leaveId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var steps []*models.LeaveApprovalStepResponse
+
+	for rows.Next() {
+		var step models.LeaveApprovalStepResponse
+
+		if err := rows.Scan(
+			&step.StepNumber,
+			&step.ApproverType,
+			&step.ApproverUserId,
+			&step.ApproverName,
+			&step.Status,
+			&step.DecidedBy,
+			&step.DecidedByType,
+			&step.DecidedAt,
+			&step.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, &step)
+	}
+
+	return steps, rows.Err()
+}
+
+// GetManagerPendingLeavesCount counts the pending leaves waiting on the reporting manager
+func (repo *PostgresRepo) GetManagerPendingLeavesCount(managerId string) (int, error) {
+	query := `SELECT
+				COUNT(*)
+			FROM users_leave_history uh
+			JOIN LATERAL (
+				SELECT s.approver_type, s.approver_user_id
+				FROM leave_approval_steps s
+				WHERE s.leave_id=uh.leave_id AND s.status='pending'
+				ORDER BY s.step_number
+				LIMIT 1
+			) cs ON TRUE
+			WHERE uh.status='pending' AND cs.approver_type='reporting_manager' AND cs.approver_user_id=$1`
+
+	var count int
+	err := repo.pool.QueryRow(context.Background(), query, managerId).Scan(&count)
+	return count, err
+}
+
+// GetManagerPendingLeaves returns the pending leaves waiting on the reporting manager
+func (repo *PostgresRepo) GetManagerPendingLeaves(managerId string, limit uint32, offset uint32) ([]*models.UserPendingLeaveResponse, error) {
+	query := `SELECT
+				u.user_id,
+				u.name,
+				u.email,
+				uc.category_name,
+				uh.leave_id,
+				uh.leave_type_id,
+				lt.leave_name,
+				uh.leave_from,
+				uh.leave_to,
+				uh.leave_granularity,
+				uh.leave_start_time,
+				uh.leave_end_time,
+				uh.leave_days,
+				uh.leave_reason,
+				uh.created_at,
+				cs.step_number,
+				(SELECT COUNT(*) FROM leave_approval_steps WHERE leave_id=uh.leave_id)::smallint
+			FROM users u
+			JOIN users_leave_history uh ON u.user_id=uh.user_id
+			JOIN employee_category uc ON u.category_id=uc.category_id
+			LEFT JOIN leave_types lt ON uh.leave_type_id=lt.leave_type_id
+			JOIN LATERAL (
+				SELECT s.step_number, s.approver_type, s.approver_user_id
+				FROM leave_approval_steps s
+				WHERE s.leave_id=uh.leave_id AND s.status='pending'
+				ORDER BY s.step_number
+				LIMIT 1
+			) cs ON TRUE
+			WHERE uh.status='pending' AND cs.approver_type='reporting_manager' AND cs.approver_user_id=$1
+			ORDER BY uh.created_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := repo.pool.Query(context.Background(), query, managerId, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPendingLeaves(rows)
+}
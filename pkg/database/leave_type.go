@@ -0,0 +1,462 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+const leaveBalanceColumns = `
+				lt.leave_type_id,
+				lt.leave_name,
+				lt.annual_quota,
+				lt.accrual_frequency,
+				lt.allow_negative_balance,
+				COALESCE(lb.balance,0),
+				COALESCE((
+					SELECT SUM(lh.leave_days) FROM users_leave_history lh
+					WHERE lh.user_id=u.user_id AND lh.leave_type_id=lt.leave_type_id AND lh.status='pending'
+				),0)`
+
+func scanLeaveBalance(row pgx.Row) (*models.LeaveBalanceResponse, error) {
+	var balance models.LeaveBalanceResponse
+
+	if err := row.Scan(
+		&balance.LeaveTypeId,
+		&balance.LeaveName,
+		&balance.AnnualQuota,
+		&balance.AccrualFrequency,
+		&balance.AllowNegativeBalance,
+		&balance.Balance,
+		&balance.PendingDays,
+	); err != nil {
+		return nil, err
+	}
+
+	balance.AvailableDays = balance.Balance - balance.PendingDays
+
+	return &balance, nil
+}
+
+func (repo *PostgresRepo) CheckLeaveTypeExists(categoryId string, leaveName string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM leave_types WHERE category_id=$1 AND leave_name=$2 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, categoryId, leaveName).Scan(&exists)
+	return exists, err
+}
+
+func (repo *PostgresRepo) CreateLeaveType(leaveType *models.LeaveType) error {
+	query := `INSERT INTO leave_types (
+				leave_type_id,
+				admin_id,
+				category_id,
+				leave_name,
+				annual_quota,
+				accrual_frequency,
+				carry_forward_limit,
+				allow_negative_balance
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		leaveType.LeaveTypeId,
+		leaveType.AdminId,
+		leaveType.CategoryId,
+		leaveType.LeaveName,
+		leaveType.AnnualQuota,
+		leaveType.AccrualFrequency,
+		leaveType.CarryForwardLimit,
+		leaveType.AllowNegativeBalance,
+	)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetLeaveTypes(categoryId string) ([]*models.LeaveTypeResponse, error) {
+	query := `SELECT
+				leave_type_id,
+				category_id,
+				leave_name,
+				annual_quota,
+				accrual_frequency,
+				carry_forward_limit,
+				allow_negative_balance,
+				created_at
+			FROM leave_types WHERE category_id=$1 ORDER BY leave_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var leaveTypes []*models.LeaveTypeResponse
+
+	for rows.Next() {
+		var leaveType models.LeaveTypeResponse
+
+		if err := rows.Scan(
+			&leaveType.LeaveTypeId,
+			&leaveType.CategoryId,
+			&leaveType.LeaveName,
+			&leaveType.AnnualQuota,
+			&leaveType.AccrualFrequency,
+			&leaveType.CarryForwardLimit,
+			&leaveType.AllowNegativeBalance,
+			&leaveType.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		leaveTypes = append(leaveTypes, &leaveType)
+	}
+
+	return leaveTypes, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckLeaveTypeIdExists(leaveTypeId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM leave_types WHERE leave_type_id=$1 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, leaveTypeId).Scan(&exists)
+	return exists, err
+}
+
+func (repo *PostgresRepo) DeleteLeaveType(leaveTypeId string) error {
+	query := `DELETE FROM leave_types WHERE leave_type_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, leaveTypeId)
+	return err
+}
+
+// AccrueLeaveBalances credits the quota of the given year or month to every user of a category
+// with leave types. before the first accrual of a new year the balance above the carry forward
+// limit lapses. the accrual period of each ledger entry is unique, so running it again for the
+// same period, or from another replica, credits nothing. the number of credited balances is returned.
+func (repo *PostgresRepo) AccrueLeaveBalances(year string, month string) (int64, error) {
+	query1 := `WITH lapsed AS (
+					INSERT INTO leave_ledger (
+						entry_id,
+						user_id,
+						leave_type_id,
+						entry_type,
+						days,
+						accrual_period,
+						note
+					)
+					SELECT
+						gen_random_uuid()::text,
+						lb.user_id,
+						lb.leave_type_id,
+						'lapse',
+						lt.carry_forward_limit - lb.balance,
+						$1::text || '-lapse',
+						'balance above the carry forward limit lapsed at year end'
+					FROM leave_balances lb
+					JOIN leave_types lt ON lb.leave_type_id=lt.leave_type_id
+					WHERE lb.balance > lt.carry_forward_limit
+					AND EXISTS (
+						SELECT 1 FROM leave_ledger ll
+						WHERE ll.user_id=lb.user_id AND ll.leave_type_id=lb.leave_type_id
+						AND ll.entry_type='accrual' AND ll.accrual_period < $1
+					)
+					AND NOT EXISTS (
+						SELECT 1 FROM leave_ledger ll
+						WHERE ll.user_id=lb.user_id AND ll.leave_type_id=lb.leave_type_id
+						AND ll.entry_type='accrual' AND ll.accrual_period LIKE $1 || '%'
+					)
+					ON CONFLICT DO NOTHING
+					RETURNING user_id, leave_type_id, days
+				)
+				UPDATE leave_balances lb SET balance=lb.balance+lapsed.days
+				FROM lapsed
+				WHERE lb.user_id=lapsed.user_id AND lb.leave_type_id=lapsed.leave_type_id`
+
+	query2 := `WITH credited AS (
+					INSERT INTO leave_ledger (
+						entry_id,
+						user_id,
+						leave_type_id,
+						entry_type,
+						days,
+						accrual_period,
+						note
+					)
+					SELECT
+						gen_random_uuid()::text,
+						u.user_id,
+						lt.leave_type_id,
+						'accrual',
+						CASE WHEN lt.accrual_frequency='monthly' THEN ROUND(lt.annual_quota/12,2) ELSE lt.annual_quota END,
+						CASE WHEN lt.accrual_frequency='monthly' THEN $2 ELSE $1 END,
+						'scheduled accrual'
+					FROM leave_types lt
+					JOIN users u ON u.category_id=lt.category_id
+					ON CONFLICT DO NOTHING
+					RETURNING user_id, leave_type_id, days
+				)
+				INSERT INTO leave_balances (user_id, leave_type_id, balance)
+				SELECT user_id, leave_type_id, days FROM credited
+				ON CONFLICT (user_id, leave_type_id) DO UPDATE SET balance=leave_balances.balance+EXCLUDED.balance`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(context.Background(), query1, year); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	result, err := tx.Exec(context.Background(), query2, year, month)
+
+	if err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetUserLeaveBalance returns nil when the leave type does not belong to the category of the user
+func (repo *PostgresRepo) GetUserLeaveBalance(userId string, leaveTypeId string) (*models.LeaveBalanceResponse, error) {
+	query := `SELECT` + leaveBalanceColumns + `
+			FROM users u
+			JOIN leave_types lt ON u.category_id=lt.category_id
+			LEFT JOIN leave_balances lb ON lb.user_id=u.user_id AND lb.leave_type_id=lt.leave_type_id
+			WHERE u.user_id=$1 AND lt.leave_type_id=$2`
+
+	balance, err := scanLeaveBalance(repo.pool.QueryRow(context.Background(), query, userId, leaveTypeId))
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+
+	return balance, err
+}
+
+func (repo *PostgresRepo) GetUserLeaveBalances(userId string) ([]*models.LeaveBalanceResponse, error) {
+	query := `SELECT` + leaveBalanceColumns + `
+			FROM users u
+			JOIN leave_types lt ON u.category_id=lt.category_id
+			LEFT JOIN leave_balances lb ON lb.user_id=u.user_id AND lb.leave_type_id=lt.leave_type_id
+			WHERE u.user_id=$1
+			ORDER BY lt.leave_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var balances []*models.LeaveBalanceResponse
+
+	for rows.Next() {
+		balance, err := scanLeaveBalance(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+func (repo *PostgresRepo) AdjustLeaveBalance(entry *models.LeaveLedgerEntry) error {
+	query1 := `INSERT INTO leave_balances (user_id, leave_type_id, balance) VALUES ($1,$2,$3)
+				ON CONFLICT (user_id, leave_type_id) DO UPDATE SET balance=leave_balances.balance+EXCLUDED.balance`
+
+	query2 := `INSERT INTO leave_ledger (
+					entry_id,
+					user_id,
+					leave_type_id,
+					entry_type,
+					days,
+					note
+				) VALUES ($1,$2,$3,$4,$5,$6)`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), query1, entry.UserId, entry.LeaveTypeId, entry.Days); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if _, err := tx.Exec(
+		context.Background(),
+		query2,
+		entry.EntryId,
+		entry.UserId,
+		entry.LeaveTypeId,
+		entry.EntryType,
+		entry.Days,
+		entry.Note,
+	); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *PostgresRepo) GetUserLeaveLedgerCount(userId string) (int, error) {
+	query := `SELECT COUNT(*) FROM leave_ledger WHERE user_id=$1`
+	var count int
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&count)
+	return count, err
+}
+
+func (repo *PostgresRepo) GetUserLeaveLedger(userId string, limit uint32, offset uint32) ([]*models.LeaveLedgerResponse, error) {
+	query := `SELECT
+				ll.entry_id,
+				ll.leave_type_id,
+				lt.leave_name,
+				ll.entry_type,
+				ll.days,
+				ll.leave_id,
+				ll.accrual_period,
+				ll.note,
+				ll.created_at
+			FROM leave_ledger ll
+			JOIN leave_types lt ON ll.leave_type_id=lt.leave_type_id
+			WHERE ll.user_id=$1
+			ORDER BY ll.created_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []*models.LeaveLedgerResponse
+
+	for rows.Next() {
+		var entry models.LeaveLedgerResponse
+
+		if err := rows.Scan(
+			&entry.EntryId,
+			&entry.LeaveTypeId,
+			&entry.LeaveName,
+			&entry.EntryType,
+			&entry.Days,
+			&entry.LeaveId,
+			&entry.AccrualPeriod,
+			&entry.Note,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// leave balance movements caused by granting or canceling a leave, both run inside the
+// transaction that changes the leave status
+
+func debitLeaveBalance(tx pgx.Tx, leave *models.UserLeave) (bool, error) {
+	query1 := `INSERT INTO leave_balances (user_id, leave_type_id, balance) VALUES ($1,$2,0)
+				ON CONFLICT (user_id, leave_type_id) DO NOTHING`
+
+	query2 := `UPDATE leave_balances lb SET balance=lb.balance-$3
+				FROM leave_types lt
+				WHERE lb.leave_type_id=lt.leave_type_id
+				AND lb.user_id=$1 AND lb.leave_type_id=$2
+				AND (lb.balance >= $3 OR lt.allow_negative_balance)`
+
+	if _, err := tx.Exec(context.Background(), query1, leave.UserId, leave.LeaveTypeId); err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(context.Background(), query2, leave.UserId, leave.LeaveTypeId, leave.LeaveDays)
+
+	if err != nil {
+		return false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	return true, insertLeaveLedgerEntry(tx, leave, "debit", -leave.LeaveDays, "leave granted")
+}
+
+func creditLeaveBalance(tx pgx.Tx, leave *models.UserLeave) error {
+	query := `INSERT INTO leave_balances (user_id, leave_type_id, balance) VALUES ($1,$2,$3)
+				ON CONFLICT (user_id, leave_type_id) DO UPDATE SET balance=leave_balances.balance+EXCLUDED.balance`
+
+	if _, err := tx.Exec(context.Background(), query, leave.UserId, leave.LeaveTypeId, leave.LeaveDays); err != nil {
+		return err
+	}
+
+	return insertLeaveLedgerEntry(tx, leave, "credit", leave.LeaveDays, "granted leave canceled")
+}
+
+func insertLeaveLedgerEntry(tx pgx.Tx, leave *models.UserLeave, entryType string, days float64, note string) error {
+	query := `INSERT INTO leave_ledger (
+				entry_id,
+				user_id,
+				leave_type_id,
+				entry_type,
+				days,
+				leave_id,
+				note
+			) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+
+	_, err := tx.Exec(
+		context.Background(),
+		query,
+		uuid.NewString(),
+		leave.UserId,
+		leave.LeaveTypeId,
+		entryType,
+		days,
+		leave.LeaveId,
+		note,
+	)
+
+	return err
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// GetLoginAttempt returns the failed attempts of the key, nil when there are none
+func (repo *PostgresRepo) GetLoginAttempt(scope string, key string) (*models.LoginAttempt, error) {
+	query := `SELECT failures, locked_until FROM login_attempts WHERE scope=$1 AND attempt_key=$2`
+
+	var attempt models.LoginAttempt
+
+	if err := repo.pool.QueryRow(context.Background(), query, scope, key).Scan(&attempt.Failures, &attempt.LockedUntil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordLoginFailure counts a failed attempt of the key, the count and the lock start over when
+// the previous failure is older than reset after
+func (repo *PostgresRepo) RecordLoginFailure(scope string, key string, resetAfter time.Duration) (*models.LoginAttempt, error) {
+	query := `INSERT INTO login_attempts (scope, attempt_key, failures, last_failure_at)
+			VALUES ($1, $2, 1, NOW())
+			ON CONFLICT (scope, attempt_key) DO UPDATE SET
+				failures = CASE
+					WHEN login_attempts.last_failure_at < NOW() - $3 * INTERVAL '1 second' THEN 1
+					ELSE login_attempts.failures + 1
+				END,
+				locked_until = CASE
+					WHEN login_attempts.last_failure_at < NOW() - $3 * INTERVAL '1 second' THEN NULL
+					ELSE login_attempts.locked_until
+				END,
+				last_failure_at = NOW()
+			RETURNING failures, locked_until`
+
+	var attempt models.LoginAttempt
+
+	if err := repo.pool.QueryRow(context.Background(), query, scope, key, int64(resetAfter/time.Second)).Scan(&attempt.Failures, &attempt.LockedUntil); err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// LockLoginAttempt locks the key until locked until, a longer lock already in place is kept
+func (repo *PostgresRepo) LockLoginAttempt(scope string, key string, lockedUntil time.Time) error {
+	query := `UPDATE login_attempts SET
+				locked_until=GREATEST(COALESCE(locked_until, $3), $3)
+			WHERE scope=$1 AND attempt_key=$2`
+
+	_, err := repo.pool.Exec(context.Background(), query, scope, key, lockedUntil)
+
+	return err
+}
+
+func (repo *PostgresRepo) ClearLoginAttempt(scope string, key string) error {
+	query := `DELETE FROM login_attempts WHERE scope=$1 AND attempt_key=$2`
+
+	_, err := repo.pool.Exec(context.Background(), query, scope, key)
+
+	return err
+}
+
+// DeleteStaleLoginAttempts removes the counters without a recent failure or a running lock
+func (repo *PostgresRepo) DeleteStaleLoginAttempts(lastFailureBefore time.Time) error {
+	query := `DELETE FROM login_attempts
+			WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < NOW())`
+
+	_, err := repo.pool.Exec(context.Background(), query, lastFailureBefore)
+
+	return err
+}
+
+// GetLoginAlertRecipient returns the email of the admin to alert about the account of the email,
+// the admin itself for an admin account and the admin of the organization for a user account.
+// an empty email is returned when the account does not exist.
+func (repo *PostgresRepo) GetLoginAlertRecipient(scope string, email string) (string, error) {
+	query := `SELECT email FROM admins WHERE email=$1`
+
+	if scope == models.LoginScopeUserAccount || scope == models.LoginScopeUserOtp {
+		query = `SELECT a.email FROM users u JOIN admins a ON a.admin_id=u.admin_id WHERE u.email=$1`
+	}
+
+	var recipient string
+
+	if err := repo.pool.QueryRow(context.Background(), query, email).Scan(&recipient); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return recipient, nil
+}
@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// arbitrary but fixed key, every replica must use the same one so that only
+// a single process runs the migrations at a time
+const migrationAdvisoryLockKey int64 = 7242021893
+
+var migrationFileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	UpSql   string
+	DownSql string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads the embedded migration files and returns them sorted by version.
+// every version must have both an up and a down file.
+func LoadMigrations() ([]*Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := migrationFileNamePattern.FindStringSubmatch(entry.Name())
+
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", entry.Name(), err)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrationsByVersion[version]
+
+		if !ok {
+			migration = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			migrationsByVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %04d has mismatching names: %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.UpSql = string(content)
+		} else {
+			migration.DownSql = string(content)
+		}
+	}
+
+	var migrations []*Migration
+
+	for _, migration := range migrationsByVersion {
+		if migration.UpSql == "" || migration.DownSql == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// withMigrationLock runs fn on a dedicated connection while holding the migration
+// advisory lock, so replicas booting at the same time wait for each other
+func (repo *PostgresRepo) withMigrationLock(fn func(dbConn *pgxpool.Conn) error) error {
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	if _, err := dbConn.Exec(context.Background(), `SELECT pg_advisory_lock($1)`, migrationAdvisoryLockKey); err != nil {
+		return err
+	}
+
+	defer func() {
+		if _, err := dbConn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationAdvisoryLockKey); err != nil {
+			log.Println("error occurred while releasing the migration lock, Error: ", err.Error())
+		}
+	}()
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				applied_at TIMESTAMPTZ DEFAULT NOW()
+			)`
+
+	if _, err := dbConn.Exec(context.Background(), query); err != nil {
+		return err
+	}
+
+	return fn(dbConn)
+}
+
+func getAppliedMigrations(dbConn *pgxpool.Conn) (map[int64]time.Time, error) {
+	query := `SELECT version,applied_at FROM schema_migrations`
+
+	rows, err := dbConn.Query(context.Background(), query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	appliedMigrations := make(map[int64]time.Time)
+
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		appliedMigrations[version] = appliedAt
+	}
+
+	return appliedMigrations, rows.Err()
+}
+
+func runMigrationStep(dbConn *pgxpool.Conn, sql string, recordQuery string, recordArgs ...any) error {
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), sql); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), recordQuery, recordArgs...); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// MigrateUp applies every pending migration in version order and returns the applied ones
+func (repo *PostgresRepo) MigrateUp() ([]*Migration, error) {
+	migrations, err := LoadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var appliedNow []*Migration
+
+	err = repo.withMigrationLock(func(dbConn *pgxpool.Conn) error {
+		appliedMigrations, err := getAppliedMigrations(dbConn)
+
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := appliedMigrations[migration.Version]; ok {
+				continue
+			}
+
+			if err := runMigrationStep(
+				dbConn,
+				migration.UpSql,
+				`INSERT INTO schema_migrations (version,name) VALUES ($1,$2)`,
+				migration.Version,
+				migration.Name,
+			); err != nil {
+				return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			appliedNow = append(appliedNow, migration)
+		}
+
+		return nil
+	})
+
+	return appliedNow, err
+}
+
+// MigrateDown rolls back the latest applied migrations, at most steps of them
+func (repo *PostgresRepo) MigrateDown(steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than zero")
+	}
+
+	migrations, err := LoadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []*Migration
+
+	err = repo.withMigrationLock(func(dbConn *pgxpool.Conn) error {
+		appliedMigrations, err := getAppliedMigrations(dbConn)
+
+		if err != nil {
+			return err
+		}
+
+		for index := len(migrations) - 1; index >= 0 && len(rolledBack) < steps; index-- {
+			migration := migrations[index]
+
+			if _, ok := appliedMigrations[migration.Version]; !ok {
+				continue
+			}
+
+			if err := runMigrationStep(
+				dbConn,
+				migration.DownSql,
+				`DELETE FROM schema_migrations WHERE version=$1`,
+				migration.Version,
+			); err != nil {
+				return fmt.Errorf("rollback of migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+func (repo *PostgresRepo) GetMigrationStatus() ([]*MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*MigrationStatus
+
+	err = repo.withMigrationLock(func(dbConn *pgxpool.Conn) error {
+		appliedMigrations, err := getAppliedMigrations(dbConn)
+
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status := &MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+
+			if appliedAt, ok := appliedMigrations[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS admin_otps;
+DROP TABLE IF EXISTS user_otps;
+DROP TABLE IF EXISTS users_leave_history;
+DROP TABLE IF EXISTS users_history;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS employee_category;
+DROP TABLE IF EXISTS admins;
+DROP FUNCTION IF EXISTS update_timestamp();
+DROP TYPE IF EXISTS user_type;
+DROP TYPE IF EXISTS employee_leave_status;
@@ -0,0 +1,12 @@
+ALTER TABLE users_history
+    DROP COLUMN IF EXISTS logout_outside_geofence,
+    DROP COLUMN IF EXISTS logout_site_distance_meters,
+    DROP COLUMN IF EXISTS logout_site_id,
+    DROP COLUMN IF EXISTS logout_longitude,
+    DROP COLUMN IF EXISTS logout_latitude,
+    DROP COLUMN IF EXISTS outside_geofence,
+    DROP COLUMN IF EXISTS site_distance_meters,
+    DROP COLUMN IF EXISTS site_id;
+
+DROP TABLE IF EXISTS work_site_assignments;
+DROP TABLE IF EXISTS work_sites;
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS work_sites (
+    site_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    site_name VARCHAR(255) NOT NULL,
+    site_type VARCHAR(50) NOT NULL CHECK (site_type IN ('circle', 'polygon')),
+    center_latitude DOUBLE PRECISION,
+    center_longitude DOUBLE PRECISION,
+    radius_meters DOUBLE PRECISION,
+    polygon JSONB,
+    enforcement VARCHAR(50) NOT NULL DEFAULT 'flag' CHECK (enforcement IN ('flag', 'reject')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (admin_id, site_name),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS work_site_assignments (
+    assignment_id VARCHAR(255) PRIMARY KEY,
+    site_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255),
+    user_id VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((category_id IS NULL) <> (user_id IS NULL)),
+    FOREIGN KEY (site_id) REFERENCES work_sites(site_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS work_site_assignments_category_idx ON work_site_assignments (site_id, category_id) WHERE category_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS work_site_assignments_user_idx ON work_site_assignments (site_id, user_id) WHERE user_id IS NOT NULL;
+
+ALTER TABLE users_history
+    ADD COLUMN IF NOT EXISTS site_id VARCHAR(255) REFERENCES work_sites(site_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS site_distance_meters DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS outside_geofence BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS logout_latitude VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS logout_longitude VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS logout_site_id VARCHAR(255) REFERENCES work_sites(site_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS logout_site_distance_meters DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS logout_outside_geofence BOOLEAN NOT NULL DEFAULT false;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_work_sites') THEN
+        CREATE TRIGGER set_timestamp_work_sites
+        BEFORE UPDATE ON work_sites
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
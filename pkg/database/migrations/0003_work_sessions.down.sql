@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_history_user_work_date_idx;
+
+ALTER TABLE users_history DROP CONSTRAINT IF EXISTS users_history_pkey;
+
+ALTER TABLE users_history DROP COLUMN IF EXISTS session_id;
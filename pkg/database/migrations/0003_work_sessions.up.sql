@@ -0,0 +1,9 @@
+ALTER TABLE users_history ADD COLUMN IF NOT EXISTS session_id VARCHAR(255);
+
+UPDATE users_history SET session_id = gen_random_uuid()::text WHERE session_id IS NULL;
+
+ALTER TABLE users_history ALTER COLUMN session_id SET NOT NULL;
+
+ALTER TABLE users_history ADD CONSTRAINT users_history_pkey PRIMARY KEY (session_id);
+
+CREATE INDEX IF NOT EXISTS users_history_user_work_date_idx ON users_history (user_id, work_date);
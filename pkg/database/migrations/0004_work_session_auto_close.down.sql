@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS users_history_needs_review_idx;
+DROP INDEX IF EXISTS users_history_open_sessions_idx;
+
+ALTER TABLE users_history
+    DROP COLUMN IF EXISTS needs_review,
+    DROP COLUMN IF EXISTS auto_closed_at,
+    DROP COLUMN IF EXISTS auto_close_policy,
+    DROP COLUMN IF EXISTS auto_closed,
+    DROP COLUMN IF EXISTS last_activity_at;
+
+DROP TABLE IF EXISTS category_attendance_settings;
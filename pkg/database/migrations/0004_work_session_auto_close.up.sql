@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS category_attendance_settings (
+    category_id VARCHAR(255) PRIMARY KEY,
+    shift_start_time VARCHAR(5) NOT NULL DEFAULT '09:00',
+    shift_end_time VARCHAR(5) NOT NULL DEFAULT '18:00',
+    auto_close_after_minutes INTEGER NOT NULL DEFAULT 720 CHECK (auto_close_after_minutes > 0),
+    auto_close_policy VARCHAR(50) NOT NULL DEFAULT 'end_of_shift' CHECK (auto_close_policy IN ('end_of_shift', 'last_activity', 'admin_review')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+ALTER TABLE users_history
+    ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS auto_closed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS auto_close_policy VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS auto_closed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS needs_review BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS users_history_open_sessions_idx ON users_history (user_id) WHERE logout_time = 'pending';
+
+CREATE INDEX IF NOT EXISTS users_history_needs_review_idx ON users_history (user_id) WHERE needs_review;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_category_attendance_settings') THEN
+        CREATE TRIGGER set_timestamp_category_attendance_settings
+        BEFORE UPDATE ON category_attendance_settings
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,28 @@
+ALTER TABLE users_history
+    ADD COLUMN IF NOT EXISTS login_time VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS logout_time VARCHAR(255);
+
+UPDATE users_history SET
+    login_time = to_char(login_at, 'HH24:MI'),
+    logout_time = COALESCE(to_char(logout_at, 'HH24:MI'), 'pending');
+
+ALTER TABLE users_history
+    ALTER COLUMN login_time SET NOT NULL,
+    ALTER COLUMN logout_time SET NOT NULL;
+
+DROP INDEX IF EXISTS users_history_user_login_at_idx;
+
+DROP INDEX IF EXISTS users_history_open_sessions_idx;
+
+ALTER TABLE users_history ALTER COLUMN work_date TYPE VARCHAR(255) USING to_char(work_date, 'YYYY-MM-DD');
+
+ALTER TABLE users_history
+    DROP COLUMN IF EXISTS login_at,
+    DROP COLUMN IF EXISTS logout_at,
+    DROP COLUMN IF EXISTS client_login_at,
+    DROP COLUMN IF EXISTS client_logout_at,
+    DROP COLUMN IF EXISTS login_clock_skew_seconds,
+    DROP COLUMN IF EXISTS logout_clock_skew_seconds,
+    DROP COLUMN IF EXISTS clock_skew_flagged;
+
+CREATE INDEX IF NOT EXISTS users_history_open_sessions_idx ON users_history (user_id) WHERE logout_time = 'pending';
@@ -0,0 +1,36 @@
+ALTER TABLE users_history
+    ADD COLUMN IF NOT EXISTS login_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS logout_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS client_login_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS client_logout_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS login_clock_skew_seconds INTEGER,
+    ADD COLUMN IF NOT EXISTS logout_clock_skew_seconds INTEGER,
+    ADD COLUMN IF NOT EXISTS clock_skew_flagged BOOLEAN NOT NULL DEFAULT false;
+
+-- the legacy strings are wall clock values reported by the device, the server connects
+-- with the organization timezone as the session timezone so the casts resolve in it.
+-- a logout earlier than the login crossed midnight.
+UPDATE users_history SET
+    login_at = (work_date || ' ' || login_time)::timestamptz,
+    client_login_at = (work_date || ' ' || login_time)::timestamptz,
+    logout_at = CASE
+        WHEN logout_time = 'pending' THEN NULL
+        WHEN logout_time::time >= login_time::time THEN (work_date || ' ' || logout_time)::timestamptz
+        ELSE ((work_date::date + 1) || ' ' || logout_time)::timestamptz
+    END;
+
+UPDATE users_history SET client_logout_at = logout_at;
+
+ALTER TABLE users_history ALTER COLUMN login_at SET NOT NULL;
+
+DROP INDEX IF EXISTS users_history_open_sessions_idx;
+
+ALTER TABLE users_history ALTER COLUMN work_date TYPE DATE USING work_date::date;
+
+ALTER TABLE users_history
+    DROP COLUMN IF EXISTS login_time,
+    DROP COLUMN IF EXISTS logout_time;
+
+CREATE INDEX IF NOT EXISTS users_history_open_sessions_idx ON users_history (user_id) WHERE logout_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_history_user_login_at_idx ON users_history (user_id, login_at);
@@ -0,0 +1,5 @@
+ALTER TABLE users_history DROP COLUMN IF EXISTS corrected;
+
+DROP TABLE IF EXISTS users_history_audit;
+
+DROP TABLE IF EXISTS attendance_corrections;
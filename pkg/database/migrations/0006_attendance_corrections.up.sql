@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS attendance_corrections (
+    correction_id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    session_id VARCHAR(255),
+    correction_type VARCHAR(50) NOT NULL CHECK (correction_type IN ('missed_login', 'missed_logout', 'wrong_time')),
+    requested_login_at TIMESTAMPTZ,
+    requested_logout_at TIMESTAMPTZ,
+    work TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by VARCHAR(255),
+    review_comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES users_history(session_id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES admins(admin_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS attendance_corrections_user_status_idx ON attendance_corrections (user_id, status);
+
+CREATE UNIQUE INDEX IF NOT EXISTS attendance_corrections_pending_session_idx ON attendance_corrections (session_id) WHERE status = 'pending';
+
+-- every rewrite of a users_history row keeps the values it replaced
+CREATE TABLE IF NOT EXISTS users_history_audit (
+    audit_id VARCHAR(255) PRIMARY KEY,
+    session_id VARCHAR(255) NOT NULL,
+    correction_id VARCHAR(255),
+    changed_by VARCHAR(255) NOT NULL,
+    change_reason TEXT NOT NULL DEFAULT '',
+    old_work_date DATE,
+    old_login_at TIMESTAMPTZ,
+    old_logout_at TIMESTAMPTZ,
+    new_work_date DATE NOT NULL,
+    new_login_at TIMESTAMPTZ NOT NULL,
+    new_logout_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (session_id) REFERENCES users_history(session_id) ON DELETE CASCADE,
+    FOREIGN KEY (correction_id) REFERENCES attendance_corrections(correction_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_history_audit_session_idx ON users_history_audit (session_id);
+
+ALTER TABLE users_history ADD COLUMN IF NOT EXISTS corrected BOOLEAN NOT NULL DEFAULT false;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_attendance_corrections') THEN
+        CREATE TRIGGER set_timestamp_attendance_corrections
+        BEFORE UPDATE ON attendance_corrections
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS leave_ledger;
+
+ALTER TABLE users_leave_history
+    DROP COLUMN IF EXISTS leave_days,
+    DROP COLUMN IF EXISTS leave_type_id;
+
+DROP TABLE IF EXISTS leave_balances;
+
+DROP TABLE IF EXISTS leave_types;
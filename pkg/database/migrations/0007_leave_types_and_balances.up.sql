@@ -0,0 +1,73 @@
+CREATE TABLE IF NOT EXISTS leave_types (
+    leave_type_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255) NOT NULL,
+    leave_name VARCHAR(255) NOT NULL,
+    annual_quota NUMERIC(6,2) NOT NULL CHECK (annual_quota >= 0),
+    accrual_frequency VARCHAR(50) NOT NULL DEFAULT 'yearly' CHECK (accrual_frequency IN ('yearly', 'monthly')),
+    carry_forward_limit NUMERIC(6,2) NOT NULL DEFAULT 0 CHECK (carry_forward_limit >= 0),
+    allow_negative_balance BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (category_id, leave_name),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS leave_balances (
+    user_id VARCHAR(255) NOT NULL,
+    leave_type_id VARCHAR(255) NOT NULL,
+    balance NUMERIC(7,2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, leave_type_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(leave_type_id) ON DELETE CASCADE
+);
+
+ALTER TABLE users_leave_history
+    ADD COLUMN IF NOT EXISTS leave_type_id VARCHAR(255) REFERENCES leave_types(leave_type_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS leave_days NUMERIC(6,2);
+
+UPDATE users_leave_history SET leave_days = leave_to::date - leave_from::date + 1 WHERE leave_days IS NULL;
+
+ALTER TABLE users_leave_history ALTER COLUMN leave_days SET NOT NULL;
+
+-- every movement of a balance, accrual_period makes the scheduled entries idempotent
+CREATE TABLE IF NOT EXISTS leave_ledger (
+    entry_id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    leave_type_id VARCHAR(255) NOT NULL,
+    entry_type VARCHAR(50) NOT NULL CHECK (entry_type IN ('accrual', 'lapse', 'debit', 'credit', 'adjustment')),
+    days NUMERIC(7,2) NOT NULL,
+    leave_id VARCHAR(255),
+    accrual_period VARCHAR(50),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(leave_type_id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_id) REFERENCES users_leave_history(leave_id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS leave_ledger_accrual_period_idx ON leave_ledger (user_id, leave_type_id, accrual_period) WHERE accrual_period IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS leave_ledger_user_idx ON leave_ledger (user_id, created_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_leave_types') THEN
+        CREATE TRIGGER set_timestamp_leave_types
+        BEFORE UPDATE ON leave_types
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_leave_balances') THEN
+        CREATE TRIGGER set_timestamp_leave_balances
+        BEFORE UPDATE ON leave_balances
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_leave_history_partial_idx;
+
+ALTER TABLE users_leave_history
+    DROP COLUMN IF EXISTS leave_minutes,
+    DROP COLUMN IF EXISTS leave_end_time,
+    DROP COLUMN IF EXISTS leave_start_time,
+    DROP COLUMN IF EXISTS leave_granularity;
@@ -0,0 +1,7 @@
+ALTER TABLE users_leave_history
+    ADD COLUMN IF NOT EXISTS leave_granularity VARCHAR(20) NOT NULL DEFAULT 'full_day' CHECK (leave_granularity IN ('full_day', 'first_half', 'second_half', 'hours')),
+    ADD COLUMN IF NOT EXISTS leave_start_time VARCHAR(5),
+    ADD COLUMN IF NOT EXISTS leave_end_time VARCHAR(5),
+    ADD COLUMN IF NOT EXISTS leave_minutes INTEGER;
+
+CREATE INDEX IF NOT EXISTS users_leave_history_partial_idx ON users_leave_history (user_id, leave_from) WHERE leave_granularity <> 'full_day';
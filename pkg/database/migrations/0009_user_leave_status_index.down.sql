@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_leave_history_user_status_idx;
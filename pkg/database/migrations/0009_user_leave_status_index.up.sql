@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS users_leave_history_user_status_idx ON users_leave_history (user_id, status);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS weekly_off_rules;
+
+DROP TABLE IF EXISTS holidays;
@@ -0,0 +1,52 @@
+-- holidays without a category apply to every category of the admin
+CREATE TABLE IF NOT EXISTS holidays (
+    holiday_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255),
+    holiday_date DATE NOT NULL,
+    holiday_name VARCHAR(255) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'ics')),
+    ics_uid VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS holidays_admin_category_date_idx ON holidays (admin_id, (COALESCE(category_id, '')), holiday_date);
+
+-- week_numbers holds the occurrences of the weekday within the month, e.g. {2,4} for the
+-- second and fourth saturday. an empty array means every week.
+CREATE TABLE IF NOT EXISTS weekly_off_rules (
+    rule_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255),
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    week_numbers INTEGER[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS weekly_off_rules_admin_category_weekday_idx ON weekly_off_rules (admin_id, (COALESCE(category_id, '')), weekday);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_holidays') THEN
+        CREATE TRIGGER set_timestamp_holidays
+        BEFORE UPDATE ON holidays
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_weekly_off_rules') THEN
+        CREATE TRIGGER set_timestamp_weekly_off_rules
+        BEFORE UPDATE ON weekly_off_rules
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,3 @@
+ALTER TABLE category_attendance_settings
+    DROP COLUMN IF EXISTS break_minutes,
+    DROP COLUMN IF EXISTS grace_minutes;
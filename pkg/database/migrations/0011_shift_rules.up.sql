@@ -0,0 +1,5 @@
+-- a login later than the grace period after the shift start is late by the whole delay, the
+-- break is deducted from the worked hours of the shift
+ALTER TABLE category_attendance_settings
+    ADD COLUMN IF NOT EXISTS grace_minutes INTEGER NOT NULL DEFAULT 0 CHECK (grace_minutes >= 0),
+    ADD COLUMN IF NOT EXISTS break_minutes INTEGER NOT NULL DEFAULT 0 CHECK (break_minutes >= 0);
@@ -0,0 +1,15 @@
+-- one row per user and day, derived from the punches, the granted leaves, the holidays and
+-- the weekly offs. weekend is the status of a weekly off day.
+CREATE TABLE IF NOT EXISTS attendance_days (
+    user_id VARCHAR(255) NOT NULL,
+    attendance_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('present', 'absent', 'on_leave', 'holiday', 'weekend', 'half_day', 'incomplete')),
+    worked_minutes INTEGER NOT NULL DEFAULT 0,
+    leave_minutes INTEGER NOT NULL DEFAULT 0,
+    note VARCHAR(255),
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, attendance_date),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS attendance_days_date_idx ON attendance_days (attendance_date);
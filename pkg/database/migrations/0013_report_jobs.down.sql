@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS report_job_items;
+
+DROP TABLE IF EXISTS report_jobs;
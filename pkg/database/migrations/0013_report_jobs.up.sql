@@ -0,0 +1,49 @@
+-- bulk pdf report jobs, rendered in the background by the report workers. a running job whose
+-- updated_at stops moving belonged to a worker that died and is claimed again.
+CREATE TABLE IF NOT EXISTS report_jobs (
+    job_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'completed_with_errors', 'failed')),
+    total_users INTEGER NOT NULL DEFAULT 0,
+    rendered_users INTEGER NOT NULL DEFAULT 0,
+    failed_users INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    object_key VARCHAR(255),
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS report_jobs_admin_idx ON report_jobs (admin_id, created_at);
+
+CREATE INDEX IF NOT EXISTS report_jobs_status_idx ON report_jobs (status, created_at);
+
+-- one row per user of the job, the users are fixed when the job is created
+CREATE TABLE IF NOT EXISTS report_job_items (
+    job_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    user_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'rendered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    PRIMARY KEY (job_id, user_id),
+    FOREIGN KEY (job_id) REFERENCES report_jobs(job_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_report_jobs') THEN
+        CREATE TRIGGER set_timestamp_report_jobs
+        BEFORE UPDATE ON report_jobs
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
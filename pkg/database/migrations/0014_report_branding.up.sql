@@ -0,0 +1,27 @@
+-- letterhead of the pdf reports of an admin's users. reports of admins without a row keep the
+-- default letterhead. colors are #RRGGBB, logo_key is the file name of the logo in storage.
+CREATE TABLE IF NOT EXISTS report_branding (
+    admin_id VARCHAR(255) PRIMARY KEY,
+    company_name VARCHAR(255) NOT NULL,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    phone_number VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    primary_color CHAR(7) NOT NULL DEFAULT '#000000',
+    accent_color CHAR(7) NOT NULL DEFAULT '#000000',
+    footer_text VARCHAR(255) NOT NULL DEFAULT '',
+    logo_key VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_report_branding') THEN
+        CREATE TRIGGER set_timestamp_report_branding
+        BEFORE UPDATE ON report_branding
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,18 @@
+-- every generated pdf report with the sha256 of its bytes and the server signature, so a copy
+-- can be verified later. the user details are copied, the record outlives the user.
+CREATE TABLE IF NOT EXISTS issued_reports (
+    report_id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255),
+    admin_id VARCHAR(255),
+    user_name VARCHAR(255) NOT NULL,
+    category_name VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE SET NULL,
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS issued_reports_sha256_idx ON issued_reports (sha256);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS attendance_days_leave_type_idx;
+
+ALTER TABLE attendance_days
+    DROP COLUMN IF EXISTS leave_type_id,
+    DROP COLUMN IF EXISTS late_minutes,
+    DROP COLUMN IF EXISTS first_login_minute;
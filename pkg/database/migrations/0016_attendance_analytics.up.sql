@@ -0,0 +1,13 @@
+-- the attendance ledger doubles as the daily rollup of the analytics endpoints. first_login_minute
+-- is the first punch of the day in minutes after midnight, leave_type_id the type of the leave
+-- taken on the day.
+ALTER TABLE attendance_days
+    ADD COLUMN IF NOT EXISTS first_login_minute SMALLINT,
+    ADD COLUMN IF NOT EXISTS late_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS leave_type_id VARCHAR(255) REFERENCES leave_types(leave_type_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS attendance_days_leave_type_idx ON attendance_days (leave_type_id, attendance_date) WHERE leave_type_id IS NOT NULL;
+
+-- the ledger is derived data, clearing it makes the next ledger run backfill every user with
+-- the new columns
+TRUNCATE attendance_days;
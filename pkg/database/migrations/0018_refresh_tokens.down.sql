@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_session_revocations;
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,29 @@
+-- refresh tokens are stored as sha256 hashes. every refresh replaces the token with a new one of
+-- the same family, and presenting a replaced token again revokes the whole family. user_id is
+-- null for the tokens of an admin.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_id VARCHAR(255) PRIMARY KEY,
+    family_id VARCHAR(255) NOT NULL,
+    admin_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    replaced_by VARCHAR(255),
+    revoked_at TIMESTAMPTZ,
+    revoked_reason VARCHAR(30) CHECK (revoked_reason IN ('rotated', 'logout', 'reuse_detected', 'revoked_by_admin')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_idx ON refresh_tokens (user_id) WHERE revoked_at IS NULL;
+
+-- access tokens of the user issued up to revoked_at are refused, set when an admin logs the user
+-- out of every device
+CREATE TABLE IF NOT EXISTS user_session_revocations (
+    user_id VARCHAR(255) PRIMARY KEY,
+    revoked_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_managed_categories;
+
+DROP TABLE IF EXISTS user_roles;
+
+DROP TABLE IF EXISTS roles;
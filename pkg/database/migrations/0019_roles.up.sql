@@ -0,0 +1,44 @@
+-- roles an admin defines for the users of the organization. a category scoped role only grants
+-- its permissions over the users and settings of the categories the user manages.
+CREATE TABLE IF NOT EXISTS roles (
+    role_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    role_name VARCHAR(255) NOT NULL,
+    permissions TEXT[] NOT NULL,
+    category_scoped BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (admin_id, role_name),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id VARCHAR(255) NOT NULL,
+    role_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(role_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_roles_role_idx ON user_roles (role_id);
+
+-- categories a user leads, the scope of the category scoped roles of the user
+CREATE TABLE IF NOT EXISTS user_managed_categories (
+    user_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, category_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_roles') THEN
+        CREATE TRIGGER set_timestamp_roles
+        BEFORE UPDATE ON roles
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
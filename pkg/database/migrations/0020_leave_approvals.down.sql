@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS leave_approval_steps;
+
+DROP TABLE IF EXISTS leave_approval_chains;
+
+DROP TABLE IF EXISTS user_reporting_managers;
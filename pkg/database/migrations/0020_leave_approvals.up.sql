@@ -0,0 +1,70 @@
+-- the reporting manager of a user, kept apart from users so a change is not a profile update
+CREATE TABLE IF NOT EXISTS user_reporting_managers (
+    user_id VARCHAR(255) PRIMARY KEY,
+    manager_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (manager_id <> user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (manager_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_reporting_managers_manager_idx ON user_reporting_managers (manager_id);
+
+-- the approval steps a leave goes through, a chain of a category wins over the chain without
+-- category. leaves of an admin without any chain are approved by the admin alone.
+CREATE TABLE IF NOT EXISTS leave_approval_chains (
+    chain_id VARCHAR(255) PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255),
+    steps TEXT[] NOT NULL CHECK (cardinality(steps) > 0 AND steps <@ ARRAY['reporting_manager', 'admin']),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    FOREIGN KEY (admin_id) REFERENCES admins(admin_id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES employee_category(category_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS leave_approval_chains_admin_category_idx ON leave_approval_chains (admin_id, (COALESCE(category_id, '')));
+
+-- the steps of a leave, copied from the chain when the leave is applied. the pending step with
+-- the lowest number is the one waiting on a decision. a reporting manager step without approver
+-- falls back to the admin.
+CREATE TABLE IF NOT EXISTS leave_approval_steps (
+    leave_id VARCHAR(255) NOT NULL,
+    step_number SMALLINT NOT NULL,
+    approver_type VARCHAR(30) NOT NULL CHECK (approver_type IN ('reporting_manager', 'admin')),
+    approver_user_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'skipped')),
+    decided_by VARCHAR(255),
+    decided_by_type user_type,
+    decided_at TIMESTAMPTZ,
+    comment TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (leave_id, step_number),
+    FOREIGN KEY (leave_id) REFERENCES users_leave_history(leave_id) ON DELETE CASCADE,
+    FOREIGN KEY (approver_user_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS leave_approval_steps_approver_idx ON leave_approval_steps (approver_user_id) WHERE status = 'pending';
+
+-- leaves pending from before the chains wait on the admin as they did
+INSERT INTO leave_approval_steps (leave_id, step_number, approver_type)
+SELECT leave_id, 1, 'admin' FROM users_leave_history WHERE status = 'pending'
+ON CONFLICT (leave_id, step_number) DO NOTHING;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_user_reporting_managers') THEN
+        CREATE TRIGGER set_timestamp_user_reporting_managers
+        BEFORE UPDATE ON user_reporting_managers
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_leave_approval_chains') THEN
+        CREATE TRIGGER set_timestamp_leave_approval_chains
+        BEFORE UPDATE ON leave_approval_chains
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
@@ -0,0 +1,25 @@
+-- failed login and otp attempts, shared by the replicas. scope tells what attempt_key counts:
+-- the email of an admin or user account, the email an otp was sent to, or a client ip.
+-- failures older than the reset window of the scope start counting from zero again.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope VARCHAR(30) NOT NULL CHECK (scope IN ('admin_account', 'user_account', 'admin_otp', 'user_otp', 'ip')),
+    attempt_key VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (scope, attempt_key)
+);
+
+CREATE INDEX IF NOT EXISTS login_attempts_last_failure_idx ON login_attempts (last_failure_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'set_timestamp_login_attempts') THEN
+        CREATE TRIGGER set_timestamp_login_attempts
+        BEFORE UPDATE ON login_attempts
+        FOR EACH ROW
+        EXECUTE FUNCTION update_timestamp();
+    END IF;
+END $$;
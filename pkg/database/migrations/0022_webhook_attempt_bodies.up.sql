@@ -0,0 +1,3 @@
+-- the responses of the endpoints are no longer kept, a webhook pointed at an internal service
+-- would otherwise expose its responses through the attempt log
+ALTER TABLE webhook_delivery_attempts DROP COLUMN IF EXISTS response_body;
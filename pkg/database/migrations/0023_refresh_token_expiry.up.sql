@@ -0,0 +1,2 @@
+-- expired refresh tokens are purged periodically
+CREATE INDEX IF NOT EXISTS refresh_tokens_expires_at_idx ON refresh_tokens (expires_at);
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+// GetMusterRollEmployees returns the users of the admin, only of the category when given
+func (repo *PostgresRepo) GetMusterRollEmployees(adminId string, categoryId string) ([]*models.MusterRollEmployee, error) {
+	query := `SELECT
+				u.user_id,
+				u.name,
+				ec.category_name,
+				to_char(u.created_at,'YYYY-MM-DD'),
+				COALESCE(cas.shift_start_time,'09:00'),
+				COALESCE(cas.shift_end_time,'18:00'),
+				COALESCE(cas.break_minutes,0)
+			FROM users u
+			JOIN employee_category ec ON u.category_id=ec.category_id
+			LEFT JOIN category_attendance_settings cas ON u.category_id=cas.category_id
+			WHERE u.admin_id=$1 AND ($2='' OR u.category_id=$2)
+			ORDER BY u.name, u.user_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var employees []*models.MusterRollEmployee
+
+	for rows.Next() {
+		var employee models.MusterRollEmployee
+
+		if err := rows.Scan(
+			&employee.UserId,
+			&employee.Name,
+			&employee.CategoryName,
+			&employee.CreatedDate,
+			&employee.ShiftStartTime,
+			&employee.ShiftEndTime,
+			&employee.BreakMinutes,
+		); err != nil {
+			return nil, err
+		}
+
+		employees = append(employees, &employee)
+	}
+
+	return employees, rows.Err()
+}
+
+// GetMusterRollOvertime sums the overtime minutes of every user between the two dates, keyed by user id
+func (repo *PostgresRepo) GetMusterRollOvertime(adminId string, categoryId string, fromDate string, toDate string) (map[string]int, error) {
+	query := `SELECT
+				uh.user_id,` + shiftSessionColumns + `
+			FROM users u
+			JOIN users_history uh ON u.user_id=uh.user_id` + shiftMetricsJoin + `
+			WHERE u.admin_id=$1 AND ($2='' OR u.category_id=$2)
+			AND uh.work_date BETWEEN $3::date AND $4::date`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, categoryId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	overtime := make(map[string]int)
+
+	for rows.Next() {
+		var userId string
+		var shiftSession models.ShiftSession
+
+		if err := rows.Scan(
+			&userId,
+			&shiftSession.LoginAt,
+			&shiftSession.LogoutAt,
+			&shiftSession.ShiftStartAt,
+			&shiftSession.ShiftMinutes,
+			&shiftSession.GraceMinutes,
+			&shiftSession.BreakMinutes,
+			&shiftSession.FirstSession,
+			&shiftSession.LastSession,
+		); err != nil {
+			return nil, err
+		}
+
+		if overtimeMinutes := utils.MeasureShiftSession(&shiftSession).OvertimeMinutes; overtimeMinutes != nil {
+			overtime[userId] += *overtimeMinutes
+		}
+	}
+
+	return overtime, rows.Err()
+}
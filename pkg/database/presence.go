@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+)
+
+// presence events are delivered with postgres notifications, so every replica listening on the
+// channel receives the events published by any replica
+const presenceChannel = "presence_events"
+
+// PublishPresenceEvent notifies the listeners with the json event completed by the admin id and
+// the name of its user
+func (repo *PostgresRepo) PublishPresenceEvent(event []byte) error {
+	query := `SELECT pg_notify($1, ($2::jsonb || jsonb_build_object('admin_id', u.admin_id, 'user_name', u.name))::text)
+			FROM users u
+			WHERE u.user_id=$2::jsonb->>'user_id'`
+
+	_, err := repo.pool.Exec(context.Background(), query, presenceChannel, string(event))
+
+	return err
+}
+
+// ListenPresenceEvents takes a connection out of the pool, listens on the presence channel and
+// hands every payload to the handler until the connection fails
+func (repo *PostgresRepo) ListenPresenceEvents(handler func(payload []byte)) error {
+	poolConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	conn := poolConn.Hijack()
+
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(context.Background(), "LISTEN presence_events"); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(context.Background())
+
+		if err != nil {
+			return err
+		}
+
+		handler([]byte(notification.Payload))
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// UpsertReportBranding stores the letterhead of the admin, the logo is kept
+func (repo *PostgresRepo) UpsertReportBranding(branding *models.ReportBranding) error {
+	query := `INSERT INTO report_branding (
+				admin_id,
+				company_name,
+				address,
+				phone_number,
+				email,
+				website,
+				primary_color,
+				accent_color,
+				footer_text
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			ON CONFLICT (admin_id) DO UPDATE SET
+				company_name=EXCLUDED.company_name,
+				address=EXCLUDED.address,
+				phone_number=EXCLUDED.phone_number,
+				email=EXCLUDED.email,
+				website=EXCLUDED.website,
+				primary_color=EXCLUDED.primary_color,
+				accent_color=EXCLUDED.accent_color,
+				footer_text=EXCLUDED.footer_text`
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		branding.AdminId,
+		branding.CompanyName,
+		branding.Address,
+		branding.PhoneNumber,
+		branding.Email,
+		branding.Website,
+		branding.PrimaryColor,
+		branding.AccentColor,
+		branding.FooterText,
+	)
+
+	return err
+}
+
+// GetReportBranding returns the letterhead of the admin with the logo key, nil when the admin
+// did not configure one
+func (repo *PostgresRepo) GetReportBranding(adminId string) (*models.ReportBrandingResponse, *string, error) {
+	query := `SELECT
+				company_name,
+				address,
+				phone_number,
+				email,
+				website,
+				primary_color,
+				accent_color,
+				footer_text,
+				logo_key,
+				updated_at
+			FROM report_branding
+			WHERE admin_id=$1`
+
+	var branding models.ReportBrandingResponse
+	var logoKey *string
+
+	if err := repo.pool.QueryRow(context.Background(), query, adminId).Scan(
+		&branding.CompanyName,
+		&branding.Address,
+		&branding.PhoneNumber,
+		&branding.Email,
+		&branding.Website,
+		&branding.PrimaryColor,
+		&branding.AccentColor,
+		&branding.FooterText,
+		&logoKey,
+		&branding.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	branding.HasLogo = logoKey != nil
+
+	return &branding, logoKey, nil
+}
+
+// GetUserReportBranding returns the letterhead of the user's admin, nil when the admin did not
+// configure one
+func (repo *PostgresRepo) GetUserReportBranding(userId string) (*models.ReportBranding, error) {
+	query := `SELECT
+				rb.admin_id,
+				rb.company_name,
+				rb.address,
+				rb.phone_number,
+				rb.email,
+				rb.website,
+				rb.primary_color,
+				rb.accent_color,
+				rb.footer_text,
+				rb.logo_key
+			FROM users u
+			JOIN report_branding rb ON rb.admin_id=u.admin_id
+			WHERE u.user_id=$1`
+
+	var branding models.ReportBranding
+
+	if err := repo.pool.QueryRow(context.Background(), query, userId).Scan(
+		&branding.AdminId,
+		&branding.CompanyName,
+		&branding.Address,
+		&branding.PhoneNumber,
+		&branding.Email,
+		&branding.Website,
+		&branding.PrimaryColor,
+		&branding.AccentColor,
+		&branding.FooterText,
+		&branding.LogoKey,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &branding, nil
+}
+
+func (repo *PostgresRepo) UpdateReportBrandingLogo(adminId string, logoKey *string) error {
+	query := `UPDATE report_branding SET logo_key=$2 WHERE admin_id=$1`
+
+	_, err := repo.pool.Exec(context.Background(), query, adminId, logoKey)
+
+	return err
+}
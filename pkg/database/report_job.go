@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// CreateReportJob stores the job with one item per user of the admin, or of the category when
+// it is set, and returns the number of users
+func (repo *PostgresRepo) CreateReportJob(job *models.ReportJob) (int, error) {
+	jobQuery := `INSERT INTO report_jobs (
+					job_id,
+					admin_id,
+					category_id,
+					start_date,
+					end_date
+				) VALUES ($1,$2,$3,$4::date,$5::date)`
+
+	itemsQuery := `INSERT INTO report_job_items (job_id, user_id, user_name)
+				SELECT $1, user_id, name
+				FROM users
+				WHERE admin_id=$2 AND ($3::varchar IS NULL OR category_id=$3)`
+
+	totalQuery := `UPDATE report_jobs SET total_users=$2 WHERE job_id=$1`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(context.Background(), jobQuery, job.JobId, job.AdminId, job.CategoryId, job.StartDate, job.EndDate); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	tag, err := tx.Exec(context.Background(), itemsQuery, job.JobId, job.AdminId, job.CategoryId)
+
+	if err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	totalUsers := int(tag.RowsAffected())
+
+	if totalUsers == 0 {
+		tx.Rollback(context.Background())
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(context.Background(), totalQuery, job.JobId, totalUsers); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return 0, err
+	}
+
+	return totalUsers, nil
+}
+
+// ClaimReportJob marks the oldest queued job, or a running job not updated within staleAfter,
+// as running for the caller and resets its items. stale jobs that already used maxAttempts are
+// failed instead. nil is returned when there is nothing to do.
+func (repo *PostgresRepo) ClaimReportJob(staleAfter time.Duration, maxAttempts int) (*models.ReportJob, error) {
+	failQuery := `UPDATE report_jobs SET
+					status='failed',
+					error='the job was interrupted too many times',
+					completed_at=NOW()
+				WHERE status='running'
+				AND updated_at < NOW() - make_interval(secs => $1)
+				AND attempts >= $2`
+
+	claimQuery := `UPDATE report_jobs SET
+					status='running',
+					attempts=attempts+1,
+					rendered_users=0,
+					failed_users=0,
+					error=NULL,
+					started_at=NOW()
+				WHERE job_id=(
+					SELECT job_id FROM report_jobs
+					WHERE status='queued'
+					OR (status='running' AND updated_at < NOW() - make_interval(secs => $1))
+					ORDER BY created_at
+					LIMIT 1
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING
+					job_id,
+					admin_id,
+					category_id,
+					to_char(start_date,'YYYY-MM-DD'),
+					to_char(end_date,'YYYY-MM-DD'),
+					attempts`
+
+	resetItemsQuery := `UPDATE report_job_items SET
+					status='pending',
+					attempts=0,
+					last_error=NULL
+				WHERE job_id=$1`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(context.Background(), failQuery, staleAfter.Seconds(), maxAttempts); err != nil {
+		tx.Rollback(context.Background())
+		return nil, err
+	}
+
+	var job models.ReportJob
+
+	if err := tx.QueryRow(context.Background(), claimQuery, staleAfter.Seconds()).Scan(
+		&job.JobId,
+		&job.AdminId,
+		&job.CategoryId,
+		&job.StartDate,
+		&job.EndDate,
+		&job.Attempts,
+	); err != nil {
+		tx.Rollback(context.Background())
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if _, err := tx.Exec(context.Background(), resetItemsQuery, job.JobId); err != nil {
+		tx.Rollback(context.Background())
+		return nil, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (repo *PostgresRepo) GetReportJobItems(jobId string) ([]*models.ReportJobItem, error) {
+	query := `SELECT user_id, user_name FROM report_job_items WHERE job_id=$1 ORDER BY user_name, user_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, jobId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []*models.ReportJobItem
+
+	for rows.Next() {
+		var item models.ReportJobItem
+
+		if err := rows.Scan(&item.UserId, &item.UserName); err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// UpdateReportJobItem records the outcome of one user and counts it on the job, which also
+// keeps the job from looking stale while it is rendered
+func (repo *PostgresRepo) UpdateReportJobItem(jobId string, userId string, status string, attempts int, lastError *string) error {
+	itemQuery := `UPDATE report_job_items SET
+					status=$3,
+					attempts=$4,
+					last_error=$5
+				WHERE job_id=$1 AND user_id=$2`
+
+	jobQuery := `UPDATE report_jobs SET
+					rendered_users=rendered_users + CASE WHEN $2='rendered' THEN 1 ELSE 0 END,
+					failed_users=failed_users + CASE WHEN $2='failed' THEN 1 ELSE 0 END
+				WHERE job_id=$1`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), itemQuery, jobId, userId, status, attempts, lastError); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), jobQuery, jobId, status); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *PostgresRepo) CompleteReportJob(jobId string, status string, objectKey *string, jobError *string) error {
+	query := `UPDATE report_jobs SET
+				status=$2,
+				object_key=$3,
+				error=$4,
+				completed_at=NOW()
+			WHERE job_id=$1`
+
+	_, err := repo.pool.Exec(context.Background(), query, jobId, status, objectKey, jobError)
+
+	return err
+}
+
+const reportJobColumns = `job_id,
+				category_id,
+				to_char(start_date,'YYYY-MM-DD'),
+				to_char(end_date,'YYYY-MM-DD'),
+				status,
+				total_users,
+				rendered_users,
+				failed_users,
+				error,
+				created_at,
+				started_at,
+				completed_at,
+				object_key`
+
+func scanReportJob(row pgx.Row) (*models.ReportJobResponse, error) {
+	var job models.ReportJobResponse
+
+	if err := row.Scan(
+		&job.JobId,
+		&job.CategoryId,
+		&job.StartDate,
+		&job.EndDate,
+		&job.Status,
+		&job.TotalUsers,
+		&job.RenderedUsers,
+		&job.FailedUsers,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.ObjectKey,
+	); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (repo *PostgresRepo) GetReportJob(jobId string) (*models.ReportJobResponse, error) {
+	query := `SELECT ` + reportJobColumns + ` FROM report_jobs WHERE job_id=$1`
+
+	job, err := scanReportJob(repo.pool.QueryRow(context.Background(), query, jobId))
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return job, err
+}
+
+func (repo *PostgresRepo) GetReportJobFailures(jobId string) ([]*models.ReportJobFailure, error) {
+	query := `SELECT
+				user_id,
+				user_name,
+				attempts,
+				last_error
+			FROM report_job_items
+			WHERE job_id=$1 AND status='failed'
+			ORDER BY user_name, user_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, jobId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var failures []*models.ReportJobFailure
+
+	for rows.Next() {
+		var failure models.ReportJobFailure
+
+		if err := rows.Scan(
+			&failure.UserId,
+			&failure.UserName,
+			&failure.Attempts,
+			&failure.LastError,
+		); err != nil {
+			return nil, err
+		}
+
+		failures = append(failures, &failure)
+	}
+
+	return failures, rows.Err()
+}
+
+func (repo *PostgresRepo) GetReportJobsCount(adminId string) (int32, error) {
+	query := `SELECT COUNT(*) FROM report_jobs WHERE admin_id=$1`
+
+	var count int32
+
+	err := repo.pool.QueryRow(context.Background(), query, adminId).Scan(&count)
+
+	return count, err
+}
+
+func (repo *PostgresRepo) GetReportJobs(adminId string, limit uint32, offset uint32) ([]*models.ReportJobResponse, error) {
+	query := `SELECT ` + reportJobColumns + `
+			FROM report_jobs
+			WHERE admin_id=$1
+			ORDER BY created_at DESC
+			LIMIT $2 OFFSET $3`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var jobs []*models.ReportJobResponse
+
+	for rows.Next() {
+		job, err := scanReportJob(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
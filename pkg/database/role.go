@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// CheckRoleNameExists reports whether another role of the admin than role id has the name, role
+// id is empty for a new role
+func (repo *PostgresRepo) CheckRoleNameExists(adminId string, roleName string, roleId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM roles WHERE admin_id=$1 AND role_name=$2 AND role_id<>$3 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, adminId, roleName, roleId).Scan(&exists)
+	return exists, err
+}
+
+func (repo *PostgresRepo) CreateRole(role *models.Role) error {
+	query := `INSERT INTO roles (
+				role_id,
+				admin_id,
+				role_name,
+				permissions,
+				category_scoped
+			) VALUES ($1,$2,$3,$4,$5)`
+
+	_, err := repo.pool.Exec(context.Background(), query, role.RoleId, role.AdminId, role.RoleName, role.Permissions, role.CategoryScoped)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetRoles(adminId string) ([]*models.RoleResponse, error) {
+	query := `SELECT role_id, role_name, permissions, category_scoped, created_at FROM roles WHERE admin_id=$1 ORDER BY role_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRoles(rows)
+}
+
+// GetRole returns nil when the role does not exist
+func (repo *PostgresRepo) GetRole(roleId string) (*models.Role, error) {
+	query := `SELECT role_id, admin_id, role_name, permissions, category_scoped FROM roles WHERE role_id=$1`
+
+	var role models.Role
+
+	if err := repo.pool.QueryRow(context.Background(), query, roleId).Scan(
+		&role.RoleId,
+		&role.AdminId,
+		&role.RoleName,
+		&role.Permissions,
+		&role.CategoryScoped,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (repo *PostgresRepo) UpdateRole(role *models.Role) error {
+	query := `UPDATE roles SET role_name=$2, permissions=$3, category_scoped=$4 WHERE role_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, role.RoleId, role.RoleName, role.Permissions, role.CategoryScoped)
+	return err
+}
+
+func (repo *PostgresRepo) DeleteRole(roleId string) error {
+	query := `DELETE FROM roles WHERE role_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, roleId)
+	return err
+}
+
+func (repo *PostgresRepo) AssignUserRole(userId string, roleId string) error {
+	query := `INSERT INTO user_roles (user_id, role_id) VALUES ($1,$2) ON CONFLICT (user_id, role_id) DO NOTHING`
+	_, err := repo.pool.Exec(context.Background(), query, userId, roleId)
+	return err
+}
+
+// RemoveUserRole returns false when the user did not have the role
+func (repo *PostgresRepo) RemoveUserRole(userId string, roleId string) (bool, error) {
+	query := `DELETE FROM user_roles WHERE user_id=$1 AND role_id=$2`
+
+	result, err := repo.pool.Exec(context.Background(), query, userId, roleId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// CheckAdminCategoriesExist reports whether every category id is a category of the admin
+func (repo *PostgresRepo) CheckAdminCategoriesExist(adminId string, categoryIds []string) (bool, error) {
+	query := `SELECT COUNT(*) FROM employee_category WHERE admin_id=$1 AND category_id=ANY($2)`
+	var count int
+	err := repo.pool.QueryRow(context.Background(), query, adminId, categoryIds).Scan(&count)
+	return count == len(categoryIds), err
+}
+
+// SetUserManagedCategories replaces the categories the user manages
+func (repo *PostgresRepo) SetUserManagedCategories(userId string, categoryIds []string) error {
+	conn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(context.Background(), `DELETE FROM user_managed_categories WHERE user_id=$1`, userId); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	query := `INSERT INTO user_managed_categories (user_id, category_id) SELECT $1, unnest($2::varchar[])`
+
+	if _, err := tx.Exec(context.Background(), query, userId, categoryIds); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func (repo *PostgresRepo) GetUserRoles(userId string) ([]*models.RoleResponse, error) {
+	query := `SELECT r.role_id, r.role_name, r.permissions, r.category_scoped, r.created_at
+			FROM user_roles ur
+			JOIN roles r ON r.role_id=ur.role_id
+			WHERE ur.user_id=$1
+			ORDER BY r.role_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRoles(rows)
+}
+
+func (repo *PostgresRepo) GetUserManagedCategoryIds(userId string) ([]string, error) {
+	query := `SELECT category_id FROM user_managed_categories WHERE user_id=$1 ORDER BY category_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var categoryIds []string
+
+	for rows.Next() {
+		var categoryId string
+
+		if err := rows.Scan(&categoryId); err != nil {
+			return nil, err
+		}
+
+		categoryIds = append(categoryIds, categoryId)
+	}
+
+	return categoryIds, rows.Err()
+}
+
+func scanRoles(rows pgx.Rows) ([]*models.RoleResponse, error) {
+	defer rows.Close()
+
+	var roles []*models.RoleResponse
+
+	for rows.Next() {
+		var role models.RoleResponse
+
+		if err := rows.Scan(
+			&role.RoleId,
+			&role.RoleName,
+			&role.Permissions,
+			&role.CategoryScoped,
+			&role.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, &role)
+	}
+
+	return roles, rows.Err()
+}
@@ -2,11 +2,115 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
 )
 
+// partialLeaveJoin adds the granted partial day leaves of the session's work date, every
+// session of the day carries the same leave next to the day's worked hours
+const partialLeaveJoin = `
+			LEFT JOIN LATERAL (
+				SELECT
+					string_agg(
+						CASE lh.leave_granularity WHEN 'hours' THEN 'hourly leave' ELSE replace(lh.leave_granularity,'_',' ') || ' leave' END
+						|| ' ' || lh.leave_start_time || '-' || lh.leave_end_time,
+						', ' ORDER BY lh.leave_start_time
+					) AS partial_leave,
+					to_char(make_interval(mins => SUM(lh.leave_minutes)::int),'HH24:MI') AS partial_leave_hours
+				FROM users_leave_history lh
+				WHERE lh.user_id=uh.user_id
+				AND lh.status='granted'
+				AND lh.leave_granularity<>'full_day'
+				AND lh.leave_from=to_char(uh.work_date,'YYYY-MM-DD')
+			) pl ON true`
+
+// shiftMetricsJoin places the session on the shift of the user's category. a session belongs to
+// the shift whose start is nearest within half of the off duty gap, so a login after midnight
+// stays on the night shift that started the evening before. timestamps are read in the
+// connection timezone, the organization timezone.
+const shiftMetricsJoin = `
+			JOIN LATERAL (
+				SELECT
+					COALESCE(cas.shift_start_time,'09:00')::time AS start_time,
+					COALESCE(cas.shift_end_time,'18:00')::time AS end_time,
+					COALESCE(cas.grace_minutes,0) AS grace_minutes,
+					COALESCE(cas.break_minutes,0) AS break_minutes
+				FROM users su
+				LEFT JOIN category_attendance_settings cas ON su.category_id=cas.category_id
+				WHERE su.user_id=uh.user_id
+			) sc ON true
+			CROSS JOIN LATERAL (
+				SELECT
+					CASE WHEN sc.end_time > sc.start_time
+						THEN sc.end_time - sc.start_time
+						ELSE sc.end_time - sc.start_time + interval '24 hours'
+					END AS shift_length
+			) sl
+			CROSS JOIN LATERAL (
+				SELECT
+					(interval '24 hours' - sl.shift_length) / 2 AS half_gap,
+					uh.login_at::date + sc.start_time - (interval '24 hours' - sl.shift_length) / 2 AS window_start
+			) sw
+			CROSS JOIN LATERAL (
+				SELECT
+					(CASE
+						WHEN uh.login_at::timestamp < sw.window_start THEN uh.login_at::date - 1 + sc.start_time
+						WHEN uh.login_at::timestamp >= sw.window_start + interval '24 hours' THEN uh.login_at::date + 1 + sc.start_time
+						ELSE uh.login_at::date + sc.start_time
+					END)::timestamptz AS shift_start_at
+			) ss
+			CROSS JOIN LATERAL (
+				SELECT
+					NOT EXISTS (
+						SELECT 1 FROM users_history p
+						WHERE p.user_id=uh.user_id
+						AND p.login_at < uh.login_at
+						AND p.login_at >= ss.shift_start_at - sw.half_gap
+					) AS first_session,
+					NOT EXISTS (
+						SELECT 1 FROM users_history n
+						WHERE n.user_id=uh.user_id
+						AND n.login_at > uh.login_at
+						AND n.login_at < ss.shift_start_at + sl.shift_length
+					) AS last_session
+			) sp`
+
+// shiftSessionColumns selects the session placed on its shift by shiftMetricsJoin, the scanned
+// models.ShiftSession is measured by utils.MeasureShiftSession
+const shiftSessionColumns = `
+				uh.login_at,
+				uh.logout_at,
+				ss.shift_start_at,
+				EXTRACT(EPOCH FROM sl.shift_length)::int / 60,
+				sc.grace_minutes,
+				sc.break_minutes,
+				sp.first_session,
+				sp.last_session`
+
+// setShiftMetrics fills the shift figures of the history entry from its session
+func setShiftMetrics(history *models.UserWorkHistoryResponse, shiftSession *models.ShiftSession) {
+	metrics := utils.MeasureShiftSession(shiftSession)
+
+	history.ShiftStartAt = shiftSession.ShiftStartAt
+	history.LateArrivalMinutes = int32(metrics.LateMinutes)
+
+	if metrics.NetWorkedMinutes == nil {
+		return
+	}
+
+	earlyDepartureMinutes := int32(*metrics.EarlyDepartureMinutes)
+	overtimeMinutes := int32(*metrics.OvertimeMinutes)
+	netWorkedHours := fmt.Sprintf("%02d:%02d", *metrics.NetWorkedMinutes/60, *metrics.NetWorkedMinutes%60)
+
+	history.EarlyDepartureMinutes = &earlyDepartureMinutes
+	history.OvertimeMinutes = &overtimeMinutes
+	history.NetWorkedHours = &netWorkedHours
+}
+
 func (repo *PostgresRepo) CheckUserEmailExists(email string) (bool, error) {
 	query := `SELECT EXISTS ( SELECT 1 FROM users WHERE email = $1 )`
 	var userEmailExists bool
@@ -57,9 +161,11 @@ func (repo *PostgresRepo) GetUserProfileDetails(userId string) (*models.UserProf
 				u.login_status,
 				u.latitude,
 				u.longitude,
-				u.updated_at
+				u.updated_at,
+				rm.manager_id
 			FROM users u
 			JOIN employee_category ec ON u.category_id=ec.category_id
+			LEFT JOIN user_reporting_managers rm ON rm.user_id=u.user_id
 			WHERE u.user_id=$1
 			 `
 
@@ -77,6 +183,7 @@ func (repo *PostgresRepo) GetUserProfileDetails(userId string) (*models.UserProf
 		&details.Latitude,
 		&details.Longitude,
 		&details.UpdatedAt,
+		&details.ReportingManagerId,
 	)
 
 	return &details, err
@@ -164,39 +271,67 @@ func (repo *PostgresRepo) GetAdminIdByUserId(userId string) (string, error) {
 	return adminId, err
 }
 
-func (repo *PostgresRepo) CheckUserWorkEntryExists(userId string, date string) (bool, error) {
-	query := `SELECT EXISTS ( SELECT 1 FROM users_history WHERE user_id = $1 AND work_date = $2 )`
-	var entryExists bool
-	err := repo.pool.QueryRow(context.Background(), query, userId, date).Scan(&entryExists)
-	return entryExists, err
+func (repo *PostgresRepo) CheckUserOpenWorkSessionExists(userId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM users_history WHERE user_id = $1 AND logout_at IS NULL )`
+	var sessionExists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&sessionExists)
+	return sessionExists, err
 }
 
-func (repo *PostgresRepo) UserWorkLogin(userWorkHistory *models.UserWorkHistory) error {
+// CheckUserWorkSessionOverlap reports whether a session starting at loginAt would overlap
+// an existing session, a new session has to start after every earlier one ended
+func (repo *PostgresRepo) CheckUserWorkSessionOverlap(userId string, loginAt time.Time) (bool, error) {
+	query := `SELECT EXISTS (
+				SELECT 1 FROM users_history
+				WHERE user_id = $1 AND (logout_at IS NULL OR login_at >= $2 OR logout_at > $2)
+			)`
+	var overlapExists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId, loginAt).Scan(&overlapExists)
+	return overlapExists, err
+}
+
+// UserWorkLogin opens the session, false is returned without opening it when the session would
+// overlap another one. the user row is locked so concurrent logins of the user are checked in turn.
+func (repo *PostgresRepo) UserWorkLogin(userWorkHistory *models.UserWorkHistory) (bool, error) {
+	lockQuery := `SELECT 1 FROM users WHERE user_id=$1 FOR UPDATE`
+
+	overlapQuery := `SELECT EXISTS (
+				SELECT 1 FROM users_history
+				WHERE user_id = $1 AND (logout_at IS NULL OR login_at >= $2 OR logout_at > $2)
+			)`
+
 	query1 := `INSERT INTO users_history (
+					session_id,
 					user_id,
 					work_date,
-					login_time,
-					logout_time,
+					login_at,
+					client_login_at,
+					login_clock_skew_seconds,
+					clock_skew_flagged,
 					latitude,
 					longitude,
-					uploaded_work
-				) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+					uploaded_work,
+					site_id,
+					site_distance_meters,
+					outside_geofence,
+					last_activity_at
+				) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$4)`
 
 	query2 := `UPDATE users SET 
 					work_date=$2,
-					login_time=$3,
-					logout_time=$4,
-					login_status=$5,
-					latitude=$6,
-					longitude=$7,
-					uploaded_work=$8
+					login_time=to_char($3::timestamptz,'HH24:MI'),
+					logout_time='pending',
+					login_status=$4,
+					latitude=$5,
+					longitude=$6,
+					uploaded_work=$7
 				WHERE user_id=$1
 			   `
 
 	dbConn, err := repo.pool.Acquire(context.Background())
 
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	defer dbConn.Release()
@@ -204,22 +339,45 @@ func (repo *PostgresRepo) UserWorkLogin(userWorkHistory *models.UserWorkHistory)
 	tx, err := dbConn.Begin(context.Background())
 
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	if _, err := tx.Exec(context.Background(), lockQuery, userWorkHistory.UserId); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	var overlapExists bool
+
+	if err := tx.QueryRow(context.Background(), overlapQuery, userWorkHistory.UserId, userWorkHistory.Punch.ServerAt).Scan(&overlapExists); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if overlapExists {
+		tx.Rollback(context.Background())
+		return false, nil
 	}
 
 	if _, err := tx.Exec(
 		context.Background(),
 		query1,
+		userWorkHistory.SessionId,
 		userWorkHistory.UserId,
 		userWorkHistory.WorkDate,
-		userWorkHistory.LoginTime,
-		userWorkHistory.LogoutTime,
+		userWorkHistory.Punch.ServerAt,
+		userWorkHistory.Punch.ClientAt,
+		userWorkHistory.Punch.ClockSkewSeconds,
+		userWorkHistory.Punch.ClockSkewFlagged,
 		userWorkHistory.Latitude,
 		userWorkHistory.Longitude,
 		userWorkHistory.UploadedWork,
+		userWorkHistory.Geofence.SiteId,
+		userWorkHistory.Geofence.DistanceMeters,
+		userWorkHistory.Geofence.OutsideGeofence,
 	); err != nil {
 		tx.Rollback(context.Background())
-		return err
+		return false, err
 	}
 
 	if _, err := tx.Exec(
@@ -227,35 +385,56 @@ func (repo *PostgresRepo) UserWorkLogin(userWorkHistory *models.UserWorkHistory)
 		query2,
 		userWorkHistory.UserId,
 		userWorkHistory.WorkDate,
-		userWorkHistory.LoginTime,
-		userWorkHistory.LogoutTime,
+		userWorkHistory.Punch.ServerAt,
 		true,
 		userWorkHistory.Latitude,
 		userWorkHistory.Longitude,
 		userWorkHistory.UploadedWork,
 	); err != nil {
 		tx.Rollback(context.Background())
-		return err
+		return false, err
 	}
 
 	if err := tx.Commit(context.Background()); err != nil {
 		tx.Rollback(context.Background())
-		return err
+		return false, err
 	}
 
-	return nil
+	return true, nil
+}
+
+func (repo *PostgresRepo) GetUserOpenWorkSessionId(userId string) (string, error) {
+	query := `SELECT COALESCE((
+				SELECT session_id FROM users_history
+				WHERE user_id=$1 AND logout_at IS NULL
+				ORDER BY login_at DESC LIMIT 1
+			), '')`
+	var sessionId string
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&sessionId)
+	return sessionId, err
 }
 
-func (repo *PostgresRepo) CheckUserWorkLoginEntryExists(userId string, date string) (bool, error) {
-	query := `SELECT EXISTS ( SELECT 1 FROM users_history WHERE user_id=$1 AND work_date=$2 AND logout_time=$3 ) `
-	var entryExists bool
-	err := repo.pool.QueryRow(context.Background(), query, userId, date, "pending").Scan(&entryExists)
-	return entryExists, err
+func (repo *PostgresRepo) CheckUserOpenWorkSessionIdExists(userId string, sessionId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM users_history WHERE user_id=$1 AND session_id=$2 AND logout_at IS NULL )`
+	var sessionExists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId, sessionId).Scan(&sessionExists)
+	return sessionExists, err
 }
 
-func (repo *PostgresRepo) UserWorkLogout(userWorkLogoutRequest *models.UserWorkLogoutRequest) error {
-	query1 := `UPDATE users_history  SET logout_time=$4,uploaded_work=$5 WHERE user_id = $1 AND work_date=$2 AND logout_time=$3`
-	query2 := `UPDATE users SET logout_time=$2,login_status=$3,uploaded_work=$4 WHERE user_id=$1`
+func (repo *PostgresRepo) UserWorkLogout(userWorkLogoutRequest *models.UserWorkLogoutRequest, punch *models.WorkPunch, geofence *models.GeofenceResult) error {
+	query1 := `UPDATE users_history SET
+					logout_at=$3,
+					client_logout_at=$4,
+					logout_clock_skew_seconds=$5,
+					clock_skew_flagged=clock_skew_flagged OR $6,
+					uploaded_work=$7,
+					logout_latitude=NULLIF($8,''),
+					logout_longitude=NULLIF($9,''),
+					logout_site_id=$10,
+					logout_site_distance_meters=$11,
+					logout_outside_geofence=$12
+				WHERE user_id = $1 AND session_id=$2 AND logout_at IS NULL`
+	query2 := `UPDATE users SET logout_time=to_char($2::timestamptz,'HH24:MI'),login_status=$3,uploaded_work=$4 WHERE user_id=$1`
 
 	dbConn, err := repo.pool.Acquire(context.Background())
 
@@ -275,10 +454,17 @@ func (repo *PostgresRepo) UserWorkLogout(userWorkLogoutRequest *models.UserWorkL
 		context.Background(),
 		query1,
 		userWorkLogoutRequest.UserId,
-		userWorkLogoutRequest.LogoutDate,
-		"pending",
-		userWorkLogoutRequest.LogoutTime,
+		userWorkLogoutRequest.SessionId,
+		punch.ServerAt,
+		punch.ClientAt,
+		punch.ClockSkewSeconds,
+		punch.ClockSkewFlagged,
 		userWorkLogoutRequest.Work,
+		userWorkLogoutRequest.Latitude,
+		userWorkLogoutRequest.Longitude,
+		geofence.SiteId,
+		geofence.DistanceMeters,
+		geofence.OutsideGeofence,
 	); err != nil {
 		tx.Rollback(context.Background())
 		return err
@@ -288,7 +474,7 @@ func (repo *PostgresRepo) UserWorkLogout(userWorkLogoutRequest *models.UserWorkL
 		context.Background(),
 		query2,
 		userWorkLogoutRequest.UserId,
-		userWorkLogoutRequest.LogoutTime,
+		punch.ServerAt,
 		false,
 		userWorkLogoutRequest.Work,
 	); err != nil {
@@ -304,11 +490,53 @@ func (repo *PostgresRepo) UserWorkLogout(userWorkLogoutRequest *models.UserWorkL
 	return nil
 }
 
-func (repo *PostgresRepo) CheckUserPendingLeaveExists(userId string) (bool, error) {
-	query := `SELECT EXISTS ( SELECT 1 FROM users_leave_history WHERE user_id = $1 AND status=$2 )`
-	var leaveExists bool
-	err := repo.pool.QueryRow(context.Background(), query, userId, "pending").Scan(&leaveExists)
-	return leaveExists, err
+// GetUserActiveLeavesBetween returns the pending and granted leaves of the user sharing at
+// least one date with the given range
+func (repo *PostgresRepo) GetUserActiveLeavesBetween(userId string, leaveFrom string, leaveTo string) ([]*models.UserLeave, error) {
+	query := `SELECT
+				leave_id,
+				leave_from,
+				leave_to,
+				leave_granularity,
+				leave_start_time,
+				leave_end_time,
+				status
+			FROM users_leave_history
+			WHERE user_id=$1
+			AND status IN ('pending','granted')
+			AND leave_from::date <= $3::date
+			AND leave_to::date >= $2::date
+			ORDER BY leave_from::date, leave_start_time`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId, leaveFrom, leaveTo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var userLeaves []*models.UserLeave
+
+	for rows.Next() {
+		userLeave := models.UserLeave{UserId: userId}
+
+		if err := rows.Scan(
+			&userLeave.LeaveId,
+			&userLeave.LeaveFrom,
+			&userLeave.LeaveTo,
+			&userLeave.LeaveGranularity,
+			&userLeave.LeaveStartTime,
+			&userLeave.LeaveEndTime,
+			&userLeave.LeaveStatus,
+		); err != nil {
+			return nil, err
+		}
+
+		userLeaves = append(userLeaves, &userLeave)
+	}
+
+	return userLeaves, rows.Err()
 }
 
 func (repo *PostgresRepo) CheckLeaveIdExists(leaveId string) (bool, error) {
@@ -318,38 +546,177 @@ func (repo *PostgresRepo) CheckLeaveIdExists(leaveId string) (bool, error) {
 	return leaveExists, err
 }
 
-func (repo *PostgresRepo) ApplyUserLeave(userLeave *models.UserLeave) error {
+func (repo *PostgresRepo) CheckUserHasLeaveTypes(userId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM users u JOIN leave_types lt ON u.category_id=lt.category_id WHERE u.user_id=$1 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&exists)
+	return exists, err
+}
+
+// GetUserShift returns the shift start and end time of the user's category, categories
+// without attendance settings use the column defaults
+func (repo *PostgresRepo) GetUserShift(userId string) (string, string, error) {
+	query := `SELECT
+				COALESCE(cas.shift_start_time,'09:00'),
+				COALESCE(cas.shift_end_time,'18:00')
+			FROM users u
+			LEFT JOIN category_attendance_settings cas ON u.category_id=cas.category_id
+			WHERE u.user_id=$1`
+
+	var shiftStartTime, shiftEndTime string
+
+	err := repo.pool.QueryRow(context.Background(), query, userId).Scan(&shiftStartTime, &shiftEndTime)
+
+	return shiftStartTime, shiftEndTime, err
+}
+
+// ApplyUserLeave stores the pending leave together with its approval steps. it is not stored when
+// a pending or granted leave other than the checked leaves overlaps its dates, or when the balance
+// of its leave type minus the pending leaves does not cover it. the user row and the balance row
+// are locked so concurrent applications and grants of the user are checked in turn.
+func (repo *PostgresRepo) ApplyUserLeave(userLeave *models.UserLeave, approvalSteps []*models.LeaveApprovalStep, checkedLeaveIds []string) (string, error) {
+	lockQuery := `SELECT 1 FROM users WHERE user_id=$1 FOR UPDATE`
+
+	balanceRowQuery := `INSERT INTO leave_balances (user_id, leave_type_id, balance) VALUES ($1,$2,0)
+				ON CONFLICT (user_id, leave_type_id) DO NOTHING`
+
+	balanceQuery := `SELECT
+				lt.allow_negative_balance OR lb.balance - COALESCE((
+					SELECT SUM(lh.leave_days) FROM users_leave_history lh
+					WHERE lh.user_id=lb.user_id AND lh.leave_type_id=lb.leave_type_id AND lh.status='pending'
+				),0) >= $3
+			FROM leave_balances lb
+			JOIN leave_types lt ON lt.leave_type_id=lb.leave_type_id
+			WHERE lb.user_id=$1 AND lb.leave_type_id=$2
+			FOR UPDATE OF lb`
+
+	newLeaveQuery := `SELECT EXISTS (
+				SELECT 1 FROM users_leave_history
+				WHERE user_id=$1
+				AND status IN ('pending','granted')
+				AND leave_from::date <= $3::date
+				AND leave_to::date >= $2::date
+				AND leave_id <> ALL($4)
+			)`
+
 	query := `INSERT INTO users_leave_history (
 			 	leave_id,
 				user_id,
+				leave_type_id,
 				leave_from,
 				leave_to,
+				leave_granularity,
+				leave_start_time,
+				leave_end_time,
+				leave_minutes,
+				leave_days,
 				leave_reason,
 				status,
 				status_updated_by
-			 ) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+			 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`
 
-	_, err := repo.pool.Exec(
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return "", err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(context.Background(), lockQuery, userLeave.UserId); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	var newLeaveExists bool
+
+	if err := tx.QueryRow(context.Background(), newLeaveQuery, userLeave.UserId, userLeave.LeaveFrom, userLeave.LeaveTo, checkedLeaveIds).Scan(&newLeaveExists); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	if newLeaveExists {
+		tx.Rollback(context.Background())
+		return models.LeaveApplyConflict, nil
+	}
+
+	if userLeave.LeaveTypeId != nil {
+		if _, err := tx.Exec(context.Background(), balanceRowQuery, userLeave.UserId, userLeave.LeaveTypeId); err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+
+		var balanceCovers bool
+
+		if err := tx.QueryRow(context.Background(), balanceQuery, userLeave.UserId, userLeave.LeaveTypeId, userLeave.LeaveDays).Scan(&balanceCovers); err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+
+		if !balanceCovers {
+			tx.Rollback(context.Background())
+			return models.LeaveApplyInsufficientBalance, nil
+		}
+	}
+
+	if _, err := tx.Exec(
 		context.Background(),
 		query,
 		userLeave.LeaveId,
 		userLeave.UserId,
+		userLeave.LeaveTypeId,
 		userLeave.LeaveFrom,
 		userLeave.LeaveTo,
+		userLeave.LeaveGranularity,
+		userLeave.LeaveStartTime,
+		userLeave.LeaveEndTime,
+		userLeave.LeaveMinutes,
+		userLeave.LeaveDays,
 		userLeave.LeaveReason,
 		userLeave.LeaveStatus,
 		userLeave.LeaveStatusUpdatedBy,
-	)
+	); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
 
-	return err
+	stepQuery := `INSERT INTO leave_approval_steps (leave_id, step_number, approver_type, approver_user_id) VALUES ($1,$2,$3,$4)`
+
+	for _, step := range approvalSteps {
+		if _, err := tx.Exec(context.Background(), stepQuery, step.LeaveId, step.StepNumber, step.ApproverType, step.ApproverUserId); err != nil {
+			tx.Rollback(context.Background())
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return "", err
+	}
+
+	return models.LeaveApplyApplied, nil
 }
 
+// GetAllUsersPendingLeavesCount counts the pending leaves of the admin waiting on an admin step
 func (repo *PostgresRepo) GetAllUsersPendingLeavesCount(adminId string) (int, error) {
 	query := `SELECT 
 				COUNT(*) 
 			  FROM users u 
 			  JOIN users_leave_history uh ON u.user_id=uh.user_id
-			  WHERE u.admin_id=$1 AND uh.status='pending'`
+			  JOIN LATERAL (
+				SELECT s.approver_type, s.approver_user_id
+				FROM leave_approval_steps s
+				WHERE s.leave_id=uh.leave_id AND s.status='pending'
+				ORDER BY s.step_number
+				LIMIT 1
+			  ) cs ON TRUE
+			  WHERE u.admin_id=$1 AND uh.status='pending' AND (cs.approver_type='admin' OR cs.approver_user_id IS NULL)`
 
 	var count int
 
@@ -362,6 +729,8 @@ func (repo *PostgresRepo) GetAllUsersPendingLeavesCount(adminId string) (int, er
 	return count, err
 }
 
+// GetAllUsersPendingLeaves returns the pending leaves of the admin waiting on an admin step, a
+// reporting manager step without manager waits on the admin as well
 func (repo *PostgresRepo) GetAllUsersPendingLeaves(adminId string, limit uint32, offset uint32) ([]*models.UserPendingLeaveResponse, error) {
 
 	query := `SELECT 
@@ -370,14 +739,31 @@ func (repo *PostgresRepo) GetAllUsersPendingLeaves(adminId string, limit uint32,
 				u.email,
 				uc.category_name,
 				uh.leave_id,
+				uh.leave_type_id,
+				lt.leave_name,
 				uh.leave_from,
 				uh.leave_to,
+				uh.leave_granularity,
+				uh.leave_start_time,
+				uh.leave_end_time,
+				uh.leave_days,
 				uh.leave_reason,
-				uh.created_at
+				uh.created_at,
+				cs.step_number,
+				(SELECT COUNT(*) FROM leave_approval_steps WHERE leave_id=uh.leave_id)::smallint
 			FROM users u
 			JOIN users_leave_history uh ON u.user_id=uh.user_id
 			JOIN employee_category uc ON u.category_id=uc.category_id
-			WHERE u.admin_id=$1 AND uh.status = 'pending' ORDER BY uh.created_at DESC LIMIT $2 OFFSET $3`
+			LEFT JOIN leave_types lt ON uh.leave_type_id=lt.leave_type_id
+			JOIN LATERAL (
+				SELECT s.step_number, s.approver_type, s.approver_user_id
+				FROM leave_approval_steps s
+				WHERE s.leave_id=uh.leave_id AND s.status='pending'
+				ORDER BY s.step_number
+				LIMIT 1
+			) cs ON TRUE
+			WHERE u.admin_id=$1 AND uh.status = 'pending' AND (cs.approver_type='admin' OR cs.approver_user_id IS NULL)
+			ORDER BY uh.created_at DESC LIMIT $2 OFFSET $3`
 
 	rows, err := repo.pool.Query(
 		context.Background(),
@@ -391,6 +777,10 @@ func (repo *PostgresRepo) GetAllUsersPendingLeaves(adminId string, limit uint32,
 		return nil, err
 	}
 
+	return scanPendingLeaves(rows)
+}
+
+func scanPendingLeaves(rows pgx.Rows) ([]*models.UserPendingLeaveResponse, error) {
 	defer rows.Close()
 
 	var pendingLeaves []*models.UserPendingLeaveResponse
@@ -404,10 +794,18 @@ func (repo *PostgresRepo) GetAllUsersPendingLeaves(adminId string, limit uint32,
 			&pendingLeave.UserEmail,
 			&pendingLeave.UserCategory,
 			&pendingLeave.LeaveId,
+			&pendingLeave.LeaveTypeId,
+			&pendingLeave.LeaveTypeName,
 			&pendingLeave.LeaveFrom,
 			&pendingLeave.LeaveTo,
+			&pendingLeave.LeaveGranularity,
+			&pendingLeave.LeaveStartTime,
+			&pendingLeave.LeaveEndTime,
+			&pendingLeave.LeaveDays,
 			&pendingLeave.LeaveReason,
 			&pendingLeave.LeaveCreatedAt,
+			&pendingLeave.ApprovalStep,
+			&pendingLeave.ApprovalSteps,
 		); err != nil {
 			return nil, err
 		}
@@ -415,23 +813,42 @@ func (repo *PostgresRepo) GetAllUsersPendingLeaves(adminId string, limit uint32,
 		pendingLeaves = append(pendingLeaves, &pendingLeave)
 	}
 
-	return pendingLeaves, nil
-
+	return pendingLeaves, rows.Err()
 }
+
 func (repo *PostgresRepo) GetAllUsersWorkHistory(adminId string, limit, offset uint32) ([]*models.UserWorkHistoryResponse, error) {
 	query := `SELECT
+		uh.session_id,
 		u.name,
-		uh.work_date,
-		uh.login_time,
-		uh.logout_time,
+		to_char(uh.work_date,'YYYY-MM-DD'),
+		to_char(uh.login_at,'HH24:MI'),
+		COALESCE(to_char(uh.logout_at,'HH24:MI'),'pending'),
+		uh.login_at,
+		uh.logout_at,
+		uh.client_login_at,
+		uh.client_logout_at,
+		uh.clock_skew_flagged,
+		COALESCE(SUM(EXTRACT(EPOCH FROM (uh.logout_at - uh.login_at))) OVER (PARTITION BY uh.user_id, uh.work_date), 0)::bigint / 60 AS day_total_minutes,` + shiftSessionColumns + `,
 		uh.latitude,
 		uh.longitude,
+		uh.site_id,
+		ws.site_name,
+		uh.site_distance_meters,
+		uh.outside_geofence,
+		uh.logout_latitude,
+		uh.logout_longitude,
+		uh.logout_site_id,
+		uh.logout_site_distance_meters,
+		uh.logout_outside_geofence,
 		uh.uploaded_work,
+		pl.partial_leave,
+		pl.partial_leave_hours,
 		uh.created_at
 	FROM users u
 	JOIN users_history uh ON u.user_id = uh.user_id
+	LEFT JOIN work_sites ws ON uh.site_id = ws.site_id` + partialLeaveJoin + shiftMetricsJoin + `
 	WHERE u.admin_id = $1
-	ORDER BY uh.work_date DESC
+	ORDER BY uh.login_at DESC
 	LIMIT $2 OFFSET $3;`
 
 	rows, err := repo.pool.Query(context.Background(), query, adminId, limit, offset)
@@ -444,18 +861,50 @@ func (repo *PostgresRepo) GetAllUsersWorkHistory(adminId string, limit, offset u
 
 	for rows.Next() {
 		var history models.UserWorkHistoryResponse
+		var dayTotalMinutes int
+		var shiftSession models.ShiftSession
 		if err := rows.Scan(
+			&history.SessionId,
 			&history.Name,
 			&history.WorkDate,
 			&history.LoginTime,
 			&history.LogoutTime,
+			&history.LoginAt,
+			&history.LogoutAt,
+			&history.ClientLoginAt,
+			&history.ClientLogoutAt,
+			&history.ClockSkewFlagged,
+			&dayTotalMinutes,
+			&shiftSession.LoginAt,
+			&shiftSession.LogoutAt,
+			&shiftSession.ShiftStartAt,
+			&shiftSession.ShiftMinutes,
+			&shiftSession.GraceMinutes,
+			&shiftSession.BreakMinutes,
+			&shiftSession.FirstSession,
+			&shiftSession.LastSession,
 			&history.Latitude,
 			&history.Longitude,
+			&history.SiteId,
+			&history.SiteName,
+			&history.SiteDistanceMeters,
+			&history.OutsideGeofence,
+			&history.LogoutLatitude,
+			&history.LogoutLongitude,
+			&history.LogoutSiteId,
+			&history.LogoutSiteDistanceMeters,
+			&history.LogoutOutsideGeofence,
 			&history.UploadedWork,
+			&history.PartialLeave,
+			&history.PartialLeaveHours,
 			&history.TimeStamp,
 		); err != nil {
 			return nil, err
 		}
+		history.DayTotalHours = fmt.Sprintf("%02d:%02d", dayTotalMinutes/60, dayTotalMinutes%60)
+
+		setShiftMetrics(&history, &shiftSession)
+
 		workHistory = append(workHistory, &history)
 	}
 
@@ -468,7 +917,7 @@ func (repo *PostgresRepo) GetUsersLeavesCount(userId string, leaveStatus string)
 	if leaveStatus == "pending" {
 		query = `SELECT COUNT(*) FROM users_leave_history WHERE user_id=$1 AND status='pending'`
 	} else if leaveStatus == "granted" {
-		query = `SELECR COUNT(*) FROM users_leave_history WHERE user_id=$1 AND status='granted'`
+		query = `SELECT COUNT(*) FROM users_leave_history WHERE user_id=$1 AND status='granted'`
 	} else if leaveStatus == "canceled" {
 		query = `SELECT COUNT(*) FROM users_leave_history WHERE user_id=$1 AND status='canceled'`
 	} else {
@@ -486,45 +935,76 @@ func (repo *PostgresRepo) GetUserLeaves(userId string, leaveStatus string, limit
 	query := ""
 	if leaveStatus == "pending" {
 		query = `SELECT 
-					leave_id,
-					leave_from,
-					leave_to,
-					leave_reason,
-					status,
-					status_updated_by,
-					updated_at
-				FROM users_leave_history WHERE user_id=$1 AND status='pending ORDER BY updated_at DESC LIMIT $2 OFFSET $3'
-				`
+					lh.leave_id,
+					lh.leave_type_id,
+					lt.leave_name,
+					lh.leave_from,
+					lh.leave_to,
+					lh.leave_granularity,
+					lh.leave_start_time,
+					lh.leave_end_time,
+					lh.leave_days,
+					lh.leave_reason,
+					lh.status,
+					lh.status_updated_by,
+					lh.updated_at
+				FROM users_leave_history lh
+				LEFT JOIN leave_types lt ON lh.leave_type_id=lt.leave_type_id
+				WHERE lh.user_id=$1 AND lh.status='pending' ORDER BY lh.updated_at DESC LIMIT $2 OFFSET $3`
 	} else if leaveStatus == "granted" {
 		query = `SELECT 
-					leave_id,
-					leave_from,
-					leave_to,
-					leave_reason,
-					status,
-					status_updated_by,
-					updated_at
-				FROM users_leave_history WHERE user_id=$1 AND status='granted' ORDER BY updated_at DESC LIMIT $2 OFFSET $3`
+					lh.leave_id,
+					lh.leave_type_id,
+					lt.leave_name,
+					lh.leave_from,
+					lh.leave_to,
+					lh.leave_granularity,
+					lh.leave_start_time,
+					lh.leave_end_time,
+					lh.leave_days,
+					lh.leave_reason,
+					lh.status,
+					lh.status_updated_by,
+					lh.updated_at
+				FROM users_leave_history lh
+				LEFT JOIN leave_types lt ON lh.leave_type_id=lt.leave_type_id
+				WHERE lh.user_id=$1 AND lh.status='granted' ORDER BY lh.updated_at DESC LIMIT $2 OFFSET $3`
 	} else if leaveStatus == "canceled" {
 		query = `SELECT 
-					leave_id,
-					leave_from,
-					leave_to,
-					leave_reason,
-					status,
-					status_updated_by,
-					updated_at
-				FROM users_leave_history WHERE user_id=$1 AND status='canceled' ORDER BY updated_at DESC LIMIT $2 OFFSET $3`
+					lh.leave_id,
+					lh.leave_type_id,
+					lt.leave_name,
+					lh.leave_from,
+					lh.leave_to,
+					lh.leave_granularity,
+					lh.leave_start_time,
+					lh.leave_end_time,
+					lh.leave_days,
+					lh.leave_reason,
+					lh.status,
+					lh.status_updated_by,
+					lh.updated_at
+				FROM users_leave_history lh
+				LEFT JOIN leave_types lt ON lh.leave_type_id=lt.leave_type_id
+				WHERE lh.user_id=$1 AND lh.status='canceled' ORDER BY lh.updated_at DESC LIMIT $2 OFFSET $3`
 	} else {
 		query = `SELECT 
-					leave_id,
-					leave_from,
-					leave_to,
-					leave_reason,
-					status,
-					status_updated_by,
-					updated_at
-				FROM users_leave_history WHERE user_id=$1 ORDER BY updated_at DESC LIMIT $2 OFFSET $3`
+					lh.leave_id,
+					lh.leave_type_id,
+					lt.leave_name,
+					lh.leave_from,
+					lh.leave_to,
+					lh.leave_granularity,
+					lh.leave_start_time,
+					lh.leave_end_time,
+					lh.leave_days,
+					lh.leave_reason,
+					lh.status,
+					lh.status_updated_by,
+					lh.updated_at
+				FROM users_leave_history lh
+				LEFT JOIN leave_types lt ON lh.leave_type_id=lt.leave_type_id
+				WHERE lh.user_id=$1 ORDER BY lh.updated_at DESC LIMIT $2 OFFSET $3`
 	}
 
 	rows, err := repo.pool.Query(context.Background(), query, userId, limit, offset)
@@ -543,8 +1023,14 @@ func (repo *PostgresRepo) GetUserLeaves(userId string, leaveStatus string, limit
 
 		if err := rows.Scan(
 			&userLeaveResponse.LeaveId,
+			&userLeaveResponse.LeaveTypeId,
+			&userLeaveResponse.LeaveTypeName,
 			&userLeaveResponse.LeaveFrom,
 			&userLeaveResponse.LeaveTo,
+			&userLeaveResponse.LeaveGranularity,
+			&userLeaveResponse.LeaveStartTime,
+			&userLeaveResponse.LeaveEndTime,
+			&userLeaveResponse.LeaveDays,
 			&userLeaveResponse.LeaveReason,
 			&userLeaveResponse.LeaveStatus,
 			&userLeaveResponse.LeaveStatusUpdatedBy,
@@ -562,23 +1048,102 @@ func (repo *PostgresRepo) GetUserLeaves(userId string, leaveStatus string, limit
 	return userLeaveResponses, nil
 }
 
-func (repo *PostgresRepo) CheckPendingLeaveExistsByLeaveId(leaveId string) (bool, error) {
-	query := `SELECT EXISTS ( SELECT 1 FROM users_leave_history WHERE leave_id=$1 AND status='pending' )`
-	var leaveExists bool
-	err := repo.pool.QueryRow(context.Background(), query, leaveId).Scan(&leaveExists)
-	return leaveExists, err
-}
+func (repo *PostgresRepo) GetUserLeave(leaveId string) (*models.UserLeave, error) {
+	query := `SELECT
+				leave_id,
+				user_id,
+				leave_type_id,
+				leave_from,
+				leave_to,
+				leave_granularity,
+				leave_start_time,
+				leave_end_time,
+				leave_minutes,
+				leave_days,
+				leave_reason,
+				status,
+				status_updated_by
+			FROM users_leave_history WHERE leave_id=$1`
+
+	var userLeave models.UserLeave
+
+	if err := repo.pool.QueryRow(context.Background(), query, leaveId).Scan(
+		&userLeave.LeaveId,
+		&userLeave.UserId,
+		&userLeave.LeaveTypeId,
+		&userLeave.LeaveFrom,
+		&userLeave.LeaveTo,
+		&userLeave.LeaveGranularity,
+		&userLeave.LeaveStartTime,
+		&userLeave.LeaveEndTime,
+		&userLeave.LeaveMinutes,
+		&userLeave.LeaveDays,
+		&userLeave.LeaveReason,
+		&userLeave.LeaveStatus,
+		&userLeave.LeaveStatusUpdatedBy,
+	); err != nil {
+		return nil, err
+	}
 
-func (repo *PostgresRepo) CancelUserLeave(leaveId string, userType string) error {
-	query := `UPDATE users_leave_history SET status='canceled',status_updated_by=$2 WHERE leave_id=$1`
-	_, err := repo.pool.Exec(context.Background(), query, leaveId, userType)
-	return err
+	return &userLeave, nil
 }
 
-func (repo *PostgresRepo) GrantUserLeave(leaveId string) error {
-	query := `UPDATE users_leave_history SET status='granted',status_updated_by='admin' WHERE leave_id=$1`
-	_, err := repo.pool.Exec(context.Background(), query, leaveId)
-	return err
+// CancelUserLeave cancels the leave only while it is still in the given status, a granted
+// leave of a leave type is credited back to the balance. false is returned when the status
+// was changed in the meantime.
+func (repo *PostgresRepo) CancelUserLeave(leaveId string, userType string, leaveStatus string) (bool, error) {
+	query := `UPDATE users_leave_history SET status='canceled',status_updated_by=$2
+				WHERE leave_id=$1 AND status=$3
+				RETURNING user_id,leave_type_id,leave_days`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	userLeave := models.UserLeave{LeaveId: leaveId}
+
+	if err := tx.QueryRow(context.Background(), query, leaveId, userType, leaveStatus).Scan(
+		&userLeave.UserId,
+		&userLeave.LeaveTypeId,
+		&userLeave.LeaveDays,
+	); err != nil {
+		tx.Rollback(context.Background())
+
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if _, err := tx.Exec(context.Background(), `UPDATE leave_approval_steps SET status='skipped' WHERE leave_id=$1 AND status='pending'`, leaveId); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if leaveStatus == "granted" && userLeave.LeaveTypeId != nil {
+		if err := creditLeaveBalance(tx, &userLeave); err != nil {
+			tx.Rollback(context.Background())
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (repo *PostgresRepo) UpdateUserProfileInfo(userId string, userProfileUpdateRequest *models.UserProfileInfoUpdateRequest) error {
@@ -646,8 +1211,14 @@ func (repo *PostgresRepo) ClearOtp(email string, otp string) error {
 	return err
 }
 
+func (repo *PostgresRepo) ClearUserOtps(email string) error {
+	query := `DELETE FROM user_otps WHERE email=$1`
+	_, err := repo.pool.Exec(context.Background(), query, email)
+	return err
+}
+
 func (repo *PostgresRepo) CheckOtpExists(email string, otp string) (bool, error) {
-	query := `SELECT EXISTS ( SELECT 1 FROM user_otps WHERE email=$1 AND otp=$2 )`
+	query := `SELECT EXISTS ( SELECT 1 FROM user_otps WHERE email=$1 AND otp=$2 AND expire_time > NOW() )`
 	var otpExists bool
 	err := repo.pool.QueryRow(context.Background(), query, email, otp).Scan(&otpExists)
 	return otpExists, err
@@ -688,14 +1259,34 @@ func (repo *PostgresRepo) GetAllUsersWorkHistoryCount(adminId string) (int, erro
 
 func (repo *PostgresRepo) GetUserWorkHistory(userId string, limit uint32, offset uint32) ([]*models.UserWorkHistoryResponse, error) {
 	query := `SELECT 
-					work_date,
-					login_time,
-					logout_time,
-					latitude,
-					longitude,
-					uploaded_work,
-					created_at
-			 FROM users_history WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+					uh.session_id,
+					to_char(uh.work_date,'YYYY-MM-DD'),
+					to_char(uh.login_at,'HH24:MI'),
+					COALESCE(to_char(uh.logout_at,'HH24:MI'),'pending'),
+					uh.login_at,
+					uh.logout_at,
+					uh.client_login_at,
+					uh.client_logout_at,
+					uh.clock_skew_flagged,
+					COALESCE(SUM(EXTRACT(EPOCH FROM (uh.logout_at - uh.login_at))) OVER (PARTITION BY uh.work_date), 0)::bigint / 60 AS day_total_minutes,` + shiftSessionColumns + `,
+					uh.latitude,
+					uh.longitude,
+					uh.site_id,
+					ws.site_name,
+					uh.site_distance_meters,
+					uh.outside_geofence,
+					uh.logout_latitude,
+					uh.logout_longitude,
+					uh.logout_site_id,
+					uh.logout_site_distance_meters,
+					uh.logout_outside_geofence,
+					uh.uploaded_work,
+					pl.partial_leave,
+					pl.partial_leave_hours,
+					uh.created_at
+			 FROM users_history uh
+			 LEFT JOIN work_sites ws ON uh.site_id=ws.site_id` + partialLeaveJoin + shiftMetricsJoin + `
+			 WHERE uh.user_id=$1 ORDER BY uh.login_at DESC LIMIT $2 OFFSET $3`
 
 	var usersWorkHistory []*models.UserWorkHistoryResponse
 
@@ -705,21 +1296,55 @@ func (repo *PostgresRepo) GetUserWorkHistory(userId string, limit uint32, offset
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userWorkHistory models.UserWorkHistoryResponse
+		var dayTotalMinutes int
+		var shiftSession models.ShiftSession
 
 		if err := rows.Scan(
+			&userWorkHistory.SessionId,
 			&userWorkHistory.WorkDate,
 			&userWorkHistory.LoginTime,
 			&userWorkHistory.LogoutTime,
+			&userWorkHistory.LoginAt,
+			&userWorkHistory.LogoutAt,
+			&userWorkHistory.ClientLoginAt,
+			&userWorkHistory.ClientLogoutAt,
+			&userWorkHistory.ClockSkewFlagged,
+			&dayTotalMinutes,
+			&shiftSession.LoginAt,
+			&shiftSession.LogoutAt,
+			&shiftSession.ShiftStartAt,
+			&shiftSession.ShiftMinutes,
+			&shiftSession.GraceMinutes,
+			&shiftSession.BreakMinutes,
+			&shiftSession.FirstSession,
+			&shiftSession.LastSession,
 			&userWorkHistory.Latitude,
 			&userWorkHistory.Longitude,
+			&userWorkHistory.SiteId,
+			&userWorkHistory.SiteName,
+			&userWorkHistory.SiteDistanceMeters,
+			&userWorkHistory.OutsideGeofence,
+			&userWorkHistory.LogoutLatitude,
+			&userWorkHistory.LogoutLongitude,
+			&userWorkHistory.LogoutSiteId,
+			&userWorkHistory.LogoutSiteDistanceMeters,
+			&userWorkHistory.LogoutOutsideGeofence,
 			&userWorkHistory.UploadedWork,
+			&userWorkHistory.PartialLeave,
+			&userWorkHistory.PartialLeaveHours,
 			&userWorkHistory.TimeStamp,
 		); err != nil {
 			return nil, err
 		}
 
+		userWorkHistory.DayTotalHours = fmt.Sprintf("%02d:%02d", dayTotalMinutes/60, dayTotalMinutes%60)
+
+		setShiftMetrics(&userWorkHistory, &shiftSession)
+
 		usersWorkHistory = append(usersWorkHistory, &userWorkHistory)
 	}
 
@@ -753,13 +1378,17 @@ func (repo *PostgresRepo) GetUserInfoForPdf(userId string) (string, string, erro
 
 func (repo *PostgresRepo) GetWorkHistoryForPdf(userId, startDate, endDate string) ([]*models.UserWorkHistoryForPdf, error) {
 	query := `SELECT 
-				work_date,
-				login_time,
-				logout_time,
-				uploaded_work
+				to_char(uh.work_date,'YYYY-MM-DD'),
+				to_char(uh.login_at,'HH24:MI'),
+				COALESCE(to_char(uh.logout_at,'HH24:MI'),'pending'),
+				uh.uploaded_work,
+				COALESCE(EXTRACT(EPOCH FROM (uh.logout_at - uh.login_at))::int / 60, 0),
+				COALESCE(pl.partial_leave,''),
+				COALESCE(pl.partial_leave_hours,''),` + shiftSessionColumns + `
 			 FROM 
-			 	users_history
-			 WHERE user_id=$1 AND work_date BETWEEN $2 AND $3;
+			 	users_history uh` + partialLeaveJoin + shiftMetricsJoin + `
+			 WHERE uh.user_id=$1 AND uh.work_date BETWEEN $2::date AND $3::date
+			 ORDER BY uh.login_at;
 			`
 	rows, err := repo.pool.Query(
 		context.Background(),
@@ -773,18 +1402,43 @@ func (repo *PostgresRepo) GetWorkHistoryForPdf(userId, startDate, endDate string
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var usersWorkHistory []*models.UserWorkHistoryForPdf
 	for rows.Next() {
 		var userWorkHistory models.UserWorkHistoryForPdf
+		var shiftSession models.ShiftSession
 		if err := rows.Scan(
 			&userWorkHistory.Date,
 			&userWorkHistory.LoginTime,
 			&userWorkHistory.LogoutTime,
 			&userWorkHistory.WorkSummary,
+			&userWorkHistory.WorkedMinutes,
+			&userWorkHistory.PartialLeave,
+			&userWorkHistory.PartialLeaveHours,
+			&shiftSession.LoginAt,
+			&shiftSession.LogoutAt,
+			&shiftSession.ShiftStartAt,
+			&shiftSession.ShiftMinutes,
+			&shiftSession.GraceMinutes,
+			&shiftSession.BreakMinutes,
+			&shiftSession.FirstSession,
+			&shiftSession.LastSession,
 		); err != nil {
 			return nil, err
 		}
 
+		metrics := utils.MeasureShiftSession(&shiftSession)
+
+		userWorkHistory.LateMinutes = metrics.LateMinutes
+
+		//an open session has nothing to measure after the login yet
+		if metrics.NetWorkedMinutes != nil {
+			userWorkHistory.EarlyDepartureMinutes = *metrics.EarlyDepartureMinutes
+			userWorkHistory.OvertimeMinutes = *metrics.OvertimeMinutes
+			userWorkHistory.NetWorkedMinutes = *metrics.NetWorkedMinutes
+		}
+
 		usersWorkHistory = append(usersWorkHistory, &userWorkHistory)
 	}
 
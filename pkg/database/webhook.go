@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func (repo *PostgresRepo) CreateWebhook(webhook *models.Webhook) error {
+	query := `INSERT INTO webhooks (
+				webhook_id,
+				admin_id,
+				url,
+				secret,
+				event_types
+			) VALUES ($1,$2,$3,$4,$5)`
+
+	_, err := repo.pool.Exec(context.Background(), query, webhook.WebhookId, webhook.AdminId, webhook.Url, webhook.Secret, webhook.EventTypes)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetWebhooks(adminId string) ([]*models.WebhookResponse, error) {
+	query := `SELECT webhook_id, url, event_types, created_at FROM webhooks WHERE admin_id=$1 ORDER BY created_at`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var webhooks []*models.WebhookResponse
+
+	for rows.Next() {
+		var webhook models.WebhookResponse
+
+		if err := rows.Scan(
+			&webhook.WebhookId,
+			&webhook.Url,
+			&webhook.EventTypes,
+			&webhook.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (repo *PostgresRepo) CheckWebhookIdExists(webhookId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM webhooks WHERE webhook_id=$1 )`
+	var exists bool
+	err := repo.pool.QueryRow(context.Background(), query, webhookId).Scan(&exists)
+	return exists, err
+}
+
+func (repo *PostgresRepo) DeleteWebhook(webhookId string) error {
+	query := `DELETE FROM webhooks WHERE webhook_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, webhookId)
+	return err
+}
+
+// EnqueueWebhookDeliveries queues one delivery per webhook of the admin of the user that
+// subscribed to the event type and returns the number queued. the payload is built here, as the
+// delivery id and admin id differ per webhook.
+func (repo *PostgresRepo) EnqueueWebhookDeliveries(userId string, eventType string, data []byte) (int, error) {
+	query := `WITH targets AS (
+				SELECT
+					gen_random_uuid()::text AS delivery_id,
+					w.webhook_id,
+					w.admin_id
+				FROM webhooks w
+				JOIN users u ON u.admin_id=w.admin_id
+				WHERE u.user_id=$1 AND $2::text=ANY(w.event_types)
+			)
+			INSERT INTO webhook_deliveries (delivery_id, webhook_id, event_type, payload)
+			SELECT
+				delivery_id,
+				webhook_id,
+				$2::text,
+				jsonb_build_object(
+					'delivery_id', delivery_id,
+					'event_type', $2::text,
+					'admin_id', admin_id,
+					'occurred_at', NOW(),
+					'data', $3::jsonb
+				)
+			FROM targets`
+
+	tag, err := repo.pool.Exec(context.Background(), query, userId, eventType, string(data))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimWebhookDelivery takes the pending delivery that is due the longest, counts the attempt
+// and leases it to the caller by moving its next attempt past the lease. nil is returned when no
+// delivery is due.
+func (repo *PostgresRepo) ClaimWebhookDelivery(lease time.Duration) (*models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries d SET
+				attempts=d.attempts+1,
+				next_attempt_at=NOW() + make_interval(secs => $1)
+			FROM webhooks w
+			WHERE w.webhook_id=d.webhook_id
+			AND d.delivery_id=(
+				SELECT delivery_id FROM webhook_deliveries
+				WHERE status='pending' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING
+				d.delivery_id,
+				d.webhook_id,
+				d.event_type,
+				d.payload::text,
+				d.attempts,
+				w.url,
+				w.secret`
+
+	var delivery models.WebhookDelivery
+	var payload string
+
+	if err := repo.pool.QueryRow(context.Background(), query, lease.Seconds()).Scan(
+		&delivery.DeliveryId,
+		&delivery.WebhookId,
+		&delivery.EventType,
+		&payload,
+		&delivery.Attempts,
+		&delivery.Url,
+		&delivery.Secret,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+
+	return &delivery, nil
+}
+
+// RecordWebhookAttempt logs the attempt and moves the delivery to the given status. a pending
+// delivery is tried again at nextAttemptAt.
+func (repo *PostgresRepo) RecordWebhookAttempt(deliveryId string, attempt *models.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	attemptQuery := `INSERT INTO webhook_delivery_attempts (
+					delivery_id,
+					status_code,
+					error,
+					duration_ms
+				) VALUES ($1,$2,$3,$4)`
+
+	deliveryQuery := `UPDATE webhook_deliveries SET
+					status=$2,
+					next_attempt_at=COALESCE($3, next_attempt_at),
+					last_status_code=$4,
+					last_error=$5,
+					delivered_at=CASE WHEN $2='succeeded' THEN NOW() ELSE delivered_at END
+				WHERE delivery_id=$1`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		context.Background(),
+		attemptQuery,
+		deliveryId,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.DurationMs,
+	); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if _, err := tx.Exec(
+		context.Background(),
+		deliveryQuery,
+		deliveryId,
+		status,
+		nextAttemptAt,
+		attempt.StatusCode,
+		attempt.Error,
+	); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *PostgresRepo) GetWebhookDeliveriesCount(webhookId string, status string) (int32, error) {
+	query := `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id=$1 AND ($2='' OR status=$2)`
+
+	var count int32
+
+	err := repo.pool.QueryRow(context.Background(), query, webhookId, status).Scan(&count)
+
+	return count, err
+}
+
+func (repo *PostgresRepo) GetWebhookDeliveries(webhookId string, status string, limit uint32, offset uint32) ([]*models.WebhookDeliveryResponse, error) {
+	query := `SELECT
+				delivery_id,
+				webhook_id,
+				event_type,
+				status,
+				attempts,
+				CASE WHEN status='pending' THEN next_attempt_at END,
+				last_status_code,
+				last_error,
+				delivered_at,
+				created_at
+			FROM webhook_deliveries
+			WHERE webhook_id=$1 AND ($2='' OR status=$2)
+			ORDER BY created_at DESC
+			LIMIT $3 OFFSET $4`
+
+	rows, err := repo.pool.Query(context.Background(), query, webhookId, status, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDeliveryResponse
+
+	for rows.Next() {
+		var delivery models.WebhookDeliveryResponse
+
+		if err := rows.Scan(
+			&delivery.DeliveryId,
+			&delivery.WebhookId,
+			&delivery.EventType,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (repo *PostgresRepo) GetWebhookDelivery(deliveryId string) (*models.WebhookDeliveryResponse, error) {
+	query := `SELECT
+				delivery_id,
+				webhook_id,
+				event_type,
+				status,
+				attempts,
+				CASE WHEN status='pending' THEN next_attempt_at END,
+				last_status_code,
+				last_error,
+				delivered_at,
+				created_at,
+				payload
+			FROM webhook_deliveries
+			WHERE delivery_id=$1`
+
+	var delivery models.WebhookDeliveryResponse
+	var payload map[string]interface{}
+
+	if err := repo.pool.QueryRow(context.Background(), query, deliveryId).Scan(
+		&delivery.DeliveryId,
+		&delivery.WebhookId,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&payload,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	delivery.Payload = payload
+
+	return &delivery, nil
+}
+
+func (repo *PostgresRepo) GetWebhookDeliveryAttempts(deliveryId string) ([]*models.WebhookDeliveryAttempt, error) {
+	query := `SELECT
+				status_code,
+				error,
+				duration_ms,
+				attempted_at
+			FROM webhook_delivery_attempts
+			WHERE delivery_id=$1
+			ORDER BY attempted_at, attempt_id`
+
+	rows, err := repo.pool.Query(context.Background(), query, deliveryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var attempts []*models.WebhookDeliveryAttempt
+
+	for rows.Next() {
+		var attempt models.WebhookDeliveryAttempt
+
+		if err := rows.Scan(
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, &attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// RedeliverWebhookDelivery queues a finished delivery again with a fresh set of attempts, false
+// is returned when the delivery is still pending
+func (repo *PostgresRepo) RedeliverWebhookDelivery(deliveryId string) (bool, error) {
+	query := `UPDATE webhook_deliveries SET
+				status='pending',
+				attempts=0,
+				next_attempt_at=NOW()
+			WHERE delivery_id=$1 AND status<>'pending'`
+
+	tag, err := repo.pool.Exec(context.Background(), query, deliveryId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
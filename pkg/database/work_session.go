@@ -0,0 +1,261 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// GetOverdueOpenWorkSessions returns the sessions still open after the auto close cutoff of
+// the user's category, categories without settings use the column defaults
+func (repo *PostgresRepo) GetOverdueOpenWorkSessions(limit int) ([]*models.OpenWorkSession, error) {
+	query := `SELECT
+				uh.session_id,
+				uh.user_id,
+				u.name,
+				u.email,
+				a.email,
+				to_char(uh.work_date,'YYYY-MM-DD'),
+				uh.login_at,
+				uh.last_activity_at,
+				COALESCE(cas.shift_start_time,'09:00'),
+				COALESCE(cas.shift_end_time,'18:00'),
+				COALESCE(cas.auto_close_policy,'end_of_shift')
+			FROM users_history uh
+			JOIN users u ON uh.user_id=u.user_id
+			JOIN admins a ON u.admin_id=a.admin_id
+			LEFT JOIN category_attendance_settings cas ON u.category_id=cas.category_id
+			WHERE uh.logout_at IS NULL
+			AND uh.login_at + make_interval(mins => COALESCE(cas.auto_close_after_minutes,720)) < NOW()
+			ORDER BY uh.login_at
+			LIMIT $1`
+
+	rows, err := repo.pool.Query(context.Background(), query, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessions []*models.OpenWorkSession
+
+	for rows.Next() {
+		var session models.OpenWorkSession
+
+		if err := rows.Scan(
+			&session.SessionId,
+			&session.UserId,
+			&session.UserName,
+			&session.UserEmail,
+			&session.AdminEmail,
+			&session.WorkDate,
+			&session.LoginAt,
+			&session.LastActivityAt,
+			&session.ShiftStartTime,
+			&session.ShiftEndTime,
+			&session.AutoClosePolicy,
+		); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// AutoCloseWorkSession closes the session only while it is still pending, so a sweep running on
+// another replica or a late logout from the user wins the race and false is returned
+func (repo *PostgresRepo) AutoCloseWorkSession(autoClose *models.WorkSessionAutoClose) (bool, error) {
+	query1 := `UPDATE users_history SET
+					logout_at=$2,
+					auto_closed=true,
+					auto_close_policy=$3,
+					auto_closed_at=NOW(),
+					needs_review=$4
+				WHERE session_id=$1 AND logout_at IS NULL`
+
+	query2 := `UPDATE users SET logout_time=to_char($2::timestamptz,'HH24:MI'),login_status=false
+				WHERE user_id=$1
+				AND NOT EXISTS ( SELECT 1 FROM users_history WHERE user_id=$1 AND logout_at IS NULL )`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(
+		context.Background(),
+		query1,
+		autoClose.SessionId,
+		autoClose.LogoutAt,
+		autoClose.Policy,
+		autoClose.NeedsReview,
+	)
+
+	if err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		tx.Rollback(context.Background())
+		return false, nil
+	}
+
+	if _, err := tx.Exec(context.Background(), query2, autoClose.UserId, autoClose.LogoutAt); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *PostgresRepo) UpdateUserWorkActivity(userId string) (bool, error) {
+	query := `UPDATE users_history SET last_activity_at=NOW() WHERE user_id=$1 AND logout_at IS NULL`
+	result, err := repo.pool.Exec(context.Background(), query, userId)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (repo *PostgresRepo) GetWorkSessionsForReview(adminId string) ([]*models.WorkSessionForReviewResponse, error) {
+	query := `SELECT
+				uh.session_id,
+				uh.user_id,
+				u.name,
+				to_char(uh.work_date,'YYYY-MM-DD'),
+				to_char(uh.login_at,'HH24:MI'),
+				COALESCE(to_char(uh.logout_at,'HH24:MI'),'pending'),
+				uh.login_at,
+				uh.logout_at,
+				uh.last_activity_at,
+				COALESCE(uh.auto_close_policy,''),
+				uh.auto_closed_at
+			FROM users_history uh
+			JOIN users u ON uh.user_id=u.user_id
+			WHERE u.admin_id=$1 AND uh.needs_review
+			ORDER BY uh.login_at DESC`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessions []*models.WorkSessionForReviewResponse
+
+	for rows.Next() {
+		var session models.WorkSessionForReviewResponse
+
+		if err := rows.Scan(
+			&session.SessionId,
+			&session.UserId,
+			&session.UserName,
+			&session.WorkDate,
+			&session.LoginTime,
+			&session.LogoutTime,
+			&session.LoginAt,
+			&session.LogoutAt,
+			&session.LastActivityAt,
+			&session.AutoClosePolicy,
+			&session.AutoClosedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetWorkSessionForReviewLoginAt returns nil when the session is not waiting for review
+func (repo *PostgresRepo) GetWorkSessionForReviewLoginAt(sessionId string) (*time.Time, error) {
+	query := `SELECT ( SELECT login_at FROM users_history WHERE session_id=$1 AND needs_review )`
+	var loginAt *time.Time
+	err := repo.pool.QueryRow(context.Background(), query, sessionId).Scan(&loginAt)
+	return loginAt, err
+}
+
+// ResolveWorkSessionReview closes the session at logout at, false is returned without closing it
+// when the session would then overlap another session of the user. the user row is locked like
+// UserWorkLogin does so a concurrent login is checked in turn.
+func (repo *PostgresRepo) ResolveWorkSessionReview(sessionId string, logoutAt time.Time, work string) (bool, error) {
+	lockQuery := `SELECT 1 FROM users WHERE user_id=( SELECT user_id FROM users_history WHERE session_id=$1 ) FOR UPDATE`
+
+	overlapQuery := `SELECT EXISTS (
+				SELECT 1 FROM users_history other
+				JOIN users_history uh ON uh.user_id=other.user_id
+				WHERE uh.session_id=$1 AND other.session_id<>$1
+				AND other.login_at < $2 AND (other.logout_at IS NULL OR other.logout_at > uh.login_at)
+			)`
+
+	query := `UPDATE users_history SET
+				logout_at=$2,
+				uploaded_work=COALESCE(NULLIF($3,''),uploaded_work),
+				needs_review=false
+			WHERE session_id=$1 AND needs_review`
+
+	dbConn, err := repo.pool.Acquire(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(context.Background())
+
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(context.Background(), lockQuery, sessionId); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	var overlapExists bool
+
+	if err := tx.QueryRow(context.Background(), overlapQuery, sessionId, logoutAt).Scan(&overlapExists); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if overlapExists {
+		tx.Rollback(context.Background())
+		return false, nil
+	}
+
+	if _, err := tx.Exec(context.Background(), query, sessionId, logoutAt, work); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		tx.Rollback(context.Background())
+		return false, err
+	}
+
+	return true, nil
+}
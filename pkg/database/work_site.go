@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func (repo *PostgresRepo) CheckWorkSiteExists(adminId string, siteName string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM work_sites WHERE admin_id=$1 AND site_name=$2 )`
+	var siteExists bool
+	err := repo.pool.QueryRow(context.Background(), query, adminId, siteName).Scan(&siteExists)
+	return siteExists, err
+}
+
+func (repo *PostgresRepo) CreateWorkSite(site *models.WorkSite) error {
+	query := `INSERT INTO work_sites (
+				site_id,
+				admin_id,
+				site_name,
+				site_type,
+				center_latitude,
+				center_longitude,
+				radius_meters,
+				polygon,
+				enforcement
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+
+	var polygon []byte
+
+	if site.Polygon != nil {
+		encodedPolygon, err := json.Marshal(site.Polygon)
+		if err != nil {
+			return err
+		}
+		polygon = encodedPolygon
+	}
+
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		site.SiteId,
+		site.AdminId,
+		site.SiteName,
+		site.SiteType,
+		site.CenterLatitude,
+		site.CenterLongitude,
+		site.RadiusMeters,
+		polygon,
+		site.Enforcement,
+	)
+
+	return err
+}
+
+func (repo *PostgresRepo) GetWorkSites(adminId string) ([]*models.WorkSiteResponse, error) {
+	query := `SELECT
+				site_id,
+				site_name,
+				site_type,
+				center_latitude,
+				center_longitude,
+				radius_meters,
+				polygon,
+				enforcement,
+				created_at
+			FROM work_sites WHERE admin_id=$1 ORDER BY site_name`
+
+	rows, err := repo.pool.Query(context.Background(), query, adminId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var workSites []*models.WorkSiteResponse
+
+	for rows.Next() {
+		var workSite models.WorkSiteResponse
+		var polygon []byte
+
+		if err := rows.Scan(
+			&workSite.SiteId,
+			&workSite.SiteName,
+			&workSite.SiteType,
+			&workSite.CenterLatitude,
+			&workSite.CenterLongitude,
+			&workSite.RadiusMeters,
+			&polygon,
+			&workSite.Enforcement,
+			&workSite.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if polygon != nil {
+			if err := json.Unmarshal(polygon, &workSite.Polygon); err != nil {
+				return nil, err
+			}
+		}
+
+		workSites = append(workSites, &workSite)
+	}
+
+	return workSites, nil
+}
+
+func (repo *PostgresRepo) CheckWorkSiteIdExists(siteId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM work_sites WHERE site_id=$1 )`
+	var siteExists bool
+	err := repo.pool.QueryRow(context.Background(), query, siteId).Scan(&siteExists)
+	return siteExists, err
+}
+
+func (repo *PostgresRepo) DeleteWorkSite(siteId string) error {
+	query := `DELETE FROM work_sites WHERE site_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, siteId)
+	return err
+}
+
+func (repo *PostgresRepo) CheckWorkSiteAssignmentExists(siteId string, categoryId string, userId string) (bool, error) {
+	query := `SELECT EXISTS (
+				SELECT 1 FROM work_site_assignments
+				WHERE site_id=$1 AND (category_id=NULLIF($2,'') OR user_id=NULLIF($3,''))
+			)`
+	var assignmentExists bool
+	err := repo.pool.QueryRow(context.Background(), query, siteId, categoryId, userId).Scan(&assignmentExists)
+	return assignmentExists, err
+}
+
+func (repo *PostgresRepo) CreateWorkSiteAssignment(assignment *models.WorkSiteAssignment) error {
+	query := `INSERT INTO work_site_assignments (assignment_id,site_id,category_id,user_id) VALUES ($1,$2,$3,$4)`
+	_, err := repo.pool.Exec(
+		context.Background(),
+		query,
+		assignment.AssignmentId,
+		assignment.SiteId,
+		assignment.CategoryId,
+		assignment.UserId,
+	)
+	return err
+}
+
+func (repo *PostgresRepo) GetWorkSiteAssignments(siteId string) ([]*models.WorkSiteAssignmentResponse, error) {
+	query := `SELECT
+				wsa.assignment_id,
+				wsa.site_id,
+				wsa.category_id,
+				ec.category_name,
+				wsa.user_id,
+				u.name,
+				wsa.created_at
+			FROM work_site_assignments wsa
+			LEFT JOIN employee_category ec ON wsa.category_id=ec.category_id
+			LEFT JOIN users u ON wsa.user_id=u.user_id
+			WHERE wsa.site_id=$1
+			ORDER BY wsa.created_at`
+
+	rows, err := repo.pool.Query(context.Background(), query, siteId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var assignments []*models.WorkSiteAssignmentResponse
+
+	for rows.Next() {
+		var assignment models.WorkSiteAssignmentResponse
+
+		if err := rows.Scan(
+			&assignment.AssignmentId,
+			&assignment.SiteId,
+			&assignment.CategoryId,
+			&assignment.CategoryName,
+			&assignment.UserId,
+			&assignment.UserName,
+			&assignment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, &assignment)
+	}
+
+	return assignments, nil
+}
+
+func (repo *PostgresRepo) CheckWorkSiteAssignmentIdExists(assignmentId string) (bool, error) {
+	query := `SELECT EXISTS ( SELECT 1 FROM work_site_assignments WHERE assignment_id=$1 )`
+	var assignmentExists bool
+	err := repo.pool.QueryRow(context.Background(), query, assignmentId).Scan(&assignmentExists)
+	return assignmentExists, err
+}
+
+func (repo *PostgresRepo) DeleteWorkSiteAssignment(assignmentId string) error {
+	query := `DELETE FROM work_site_assignments WHERE assignment_id=$1`
+	_, err := repo.pool.Exec(context.Background(), query, assignmentId)
+	return err
+}
+
+// GetUserWorkSites returns the sites assigned to the user directly or through the user's category
+func (repo *PostgresRepo) GetUserWorkSites(userId string) ([]*models.WorkSite, error) {
+	query := `SELECT DISTINCT
+				ws.site_id,
+				ws.admin_id,
+				ws.site_name,
+				ws.site_type,
+				ws.center_latitude,
+				ws.center_longitude,
+				ws.radius_meters,
+				ws.polygon,
+				ws.enforcement
+			FROM users u
+			JOIN work_site_assignments wsa ON wsa.user_id=u.user_id OR wsa.category_id=u.category_id
+			JOIN work_sites ws ON ws.site_id=wsa.site_id
+			WHERE u.user_id=$1`
+
+	rows, err := repo.pool.Query(context.Background(), query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var workSites []*models.WorkSite
+
+	for rows.Next() {
+		var workSite models.WorkSite
+		var polygon []byte
+
+		if err := rows.Scan(
+			&workSite.SiteId,
+			&workSite.AdminId,
+			&workSite.SiteName,
+			&workSite.SiteType,
+			&workSite.CenterLatitude,
+			&workSite.CenterLongitude,
+			&workSite.RadiusMeters,
+			&polygon,
+			&workSite.Enforcement,
+		); err != nil {
+			return nil, err
+		}
+
+		if polygon != nil {
+			if err := json.Unmarshal(polygon, &workSite.Polygon); err != nil {
+				return nil, err
+			}
+		}
+
+		workSites = append(workSites, &workSite)
+	}
+
+	return workSites, nil
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// share of the net shift, shift minus break, that worked time and partial day leave must cover
+// for a working day to count as present or as half day. anything less is absent.
+const (
+	presentShiftShare = 0.75
+	halfDayShiftShare = 0.5
+)
+
+// BuildAttendanceDays derives the attendance status of every day between two "2006-01-02" dates,
+// both inclusive. an open session makes the day incomplete, then holidays and weekly offs win
+// over leaves, and a full day leave wins over the worked time of a working day.
+func BuildAttendanceDays(
+	userId string,
+	fromDate string,
+	toDate string,
+	sessionDays []*models.AttendanceSessionDay,
+	leaves []*models.UserLeave,
+	calendar *models.WorkCalendar,
+	netShiftMinutes int,
+) ([]*models.AttendanceDay, error) {
+	from, err := time.Parse("2006-01-02", fromDate)
+
+	if err != nil {
+		return nil, errors.New("invalid date formates")
+	}
+
+	to, err := time.Parse("2006-01-02", toDate)
+
+	if err != nil {
+		return nil, errors.New("invalid date formates")
+	}
+
+	sessionsByDate := make(map[string]*models.AttendanceSessionDay, len(sessionDays))
+
+	for _, sessionDay := range sessionDays {
+		sessionsByDate[sessionDay.Date] = sessionDay
+	}
+
+	var days []*models.AttendanceDay
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dateString := date.Format("2006-01-02")
+
+		day := &models.AttendanceDay{
+			UserId:         userId,
+			AttendanceDate: dateString,
+		}
+
+		var hasOpenSession bool
+
+		if sessionDay, ok := sessionsByDate[dateString]; ok {
+			day.WorkedMinutes = sessionDay.WorkedMinutes
+			day.FirstLoginMinute = sessionDay.FirstLoginMinute
+			day.LateMinutes = sessionDay.LateMinutes
+			hasOpenSession = sessionDay.HasOpenSession
+		}
+
+		var fullDayLeave bool
+
+		for _, leave := range leaves {
+			if leave.LeaveFrom > dateString || leave.LeaveTo < dateString {
+				continue
+			}
+
+			day.LeaveTypeId = leave.LeaveTypeId
+
+			if leave.LeaveGranularity == "full_day" {
+				fullDayLeave = true
+				continue
+			}
+
+			if leave.LeaveMinutes != nil {
+				day.LeaveMinutes += int(*leave.LeaveMinutes)
+			}
+		}
+
+		nonWorkingDayReason := NonWorkingDayReason(date, calendar)
+		isHoliday := calendar != nil && calendar.Holidays[dateString] != ""
+
+		switch {
+		case hasOpenSession:
+			day.Status = "incomplete"
+		case isHoliday:
+			day.Status = "holiday"
+			day.Note = &nonWorkingDayReason
+		case nonWorkingDayReason != "":
+			day.Status = "weekend"
+		case fullDayLeave:
+			day.Status = "on_leave"
+		default:
+			day.Status = workingDayStatus(day.WorkedMinutes+day.LeaveMinutes, netShiftMinutes)
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func workingDayStatus(coveredMinutes int, netShiftMinutes int) string {
+	if netShiftMinutes <= 0 {
+		netShiftMinutes = 1
+	}
+
+	share := float64(coveredMinutes) / float64(netShiftMinutes)
+
+	switch {
+	case share >= presentShiftShare:
+		return "present"
+	case share >= halfDayShiftShare:
+		return "half_day"
+	default:
+		return "absent"
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"errors"
+	"time"
+)
+
+// CountLeaveDays returns the calendar days between two "2006-01-02" dates, both inclusive
+func CountLeaveDays(leaveFrom string, leaveTo string) (float64, error) {
+	fromDate, err := time.Parse("2006-01-02", leaveFrom)
+
+	if err != nil {
+		return 0, errors.New("invalid date formates")
+	}
+
+	toDate, err := time.Parse("2006-01-02", leaveTo)
+
+	if err != nil {
+		return 0, errors.New("invalid date formates")
+	}
+
+	if toDate.Before(fromDate) {
+		return 0, errors.New("dates order mismatch")
+	}
+
+	return toDate.Sub(fromDate).Hours()/24 + 1, nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestCountLeaveDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		leaveFrom string
+		leaveTo   string
+		leaveDays float64
+		wantErr   bool
+	}{
+		{name: "single day", leaveFrom: "2025-03-10", leaveTo: "2025-03-10", leaveDays: 1},
+		{name: "across a month end", leaveFrom: "2025-01-30", leaveTo: "2025-02-02", leaveDays: 4},
+		{name: "across a leap day", leaveFrom: "2024-02-28", leaveTo: "2024-03-01", leaveDays: 3},
+		{name: "across a year end", leaveFrom: "2024-12-31", leaveTo: "2025-01-01", leaveDays: 2},
+		{name: "dates in reverse order", leaveFrom: "2025-03-11", leaveTo: "2025-03-10", wantErr: true},
+		{name: "invalid date", leaveFrom: "2025-02-30", leaveTo: "2025-03-01", wantErr: true},
+		{name: "date with time", leaveFrom: "2025-03-10 09:00", leaveTo: "2025-03-10", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leaveDays, err := CountLeaveDays(test.leaveFrom, test.leaveTo)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			if leaveDays != test.leaveDays {
+				t.Errorf("expected %g days, got %g", test.leaveDays, leaveDays)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"math"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+const earthRadiusMeters = 6371000.0
+
+func degreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// HaversineDistance returns the great-circle distance between two points in meters
+func HaversineDistance(a, b models.GeoPoint) float64 {
+	lat1 := degreesToRadians(a.Latitude)
+	lat2 := degreesToRadians(b.Latitude)
+	deltaLat := degreesToRadians(b.Latitude - a.Latitude)
+	deltaLng := degreesToRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PointInPolygon uses ray casting, the polygon is treated as closed
+func PointInPolygon(point models.GeoPoint, polygon []models.GeoPoint) bool {
+	inside := false
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) {
+			crossLng := (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude) + a.Longitude
+			if point.Longitude < crossLng {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// DistanceToPolygon returns 0 when the point is inside the polygon, otherwise the
+// distance in meters to the closest edge. the edges are projected onto a local plane
+// around the point, which is accurate enough at the size of an office campus.
+func DistanceToPolygon(point models.GeoPoint, polygon []models.GeoPoint) float64 {
+	if PointInPolygon(point, polygon) {
+		return 0
+	}
+
+	metersPerDegreeLat := earthRadiusMeters * math.Pi / 180
+	metersPerDegreeLng := metersPerDegreeLat * math.Cos(degreesToRadians(point.Latitude))
+
+	project := func(p models.GeoPoint) (float64, float64) {
+		return (p.Longitude - point.Longitude) * metersPerDegreeLng, (p.Latitude - point.Latitude) * metersPerDegreeLat
+	}
+
+	minDistance := math.MaxFloat64
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		ax, ay := project(polygon[j])
+		bx, by := project(polygon[i])
+
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+		}
+
+		cx, cy := ax+t*dx, ay+t*dy
+		minDistance = math.Min(minDistance, math.Hypot(cx, cy))
+	}
+
+	return minDistance
+}
+
+// EvaluateGeofence matches a punch location against the given work sites. for circle
+// sites the recorded distance is measured from the center, for polygon sites it is the
+// distance to the boundary. when nothing matches the nearest site is reported and the
+// punch is rejected if any of the sites enforces rejection.
+func EvaluateGeofence(sites []*models.WorkSite, point models.GeoPoint) *models.GeofenceResult {
+	result := new(models.GeofenceResult)
+
+	if len(sites) == 0 {
+		return result
+	}
+
+	var matchedSite, nearestSite *models.WorkSite
+	var matchedDistance, nearestDistance, nearestOutsideBy float64
+
+	for _, site := range sites {
+		var distance, outsideBy float64
+
+		switch site.SiteType {
+		case "circle":
+			if site.CenterLatitude == nil || site.CenterLongitude == nil || site.RadiusMeters == nil {
+				continue
+			}
+			distance = HaversineDistance(point, models.GeoPoint{
+				Latitude:  *site.CenterLatitude,
+				Longitude: *site.CenterLongitude,
+			})
+			outsideBy = distance - *site.RadiusMeters
+		case "polygon":
+			if len(site.Polygon) < 3 {
+				continue
+			}
+			distance = DistanceToPolygon(point, site.Polygon)
+			outsideBy = distance
+		default:
+			continue
+		}
+
+		if site.Enforcement == "reject" {
+			result.Reject = true
+		}
+
+		if outsideBy <= 0 {
+			if matchedSite == nil || distance < matchedDistance {
+				matchedSite, matchedDistance = site, distance
+			}
+			continue
+		}
+
+		if nearestSite == nil || outsideBy < nearestOutsideBy {
+			nearestSite, nearestDistance, nearestOutsideBy = site, distance, outsideBy
+		}
+	}
+
+	if matchedSite != nil {
+		result.SiteId = &matchedSite.SiteId
+		result.DistanceMeters = &matchedDistance
+		result.Reject = false
+		return result
+	}
+
+	if nearestSite == nil {
+		// every assigned site had incomplete geometry, nothing to enforce against
+		result.Reject = false
+		return result
+	}
+
+	result.SiteId = &nearestSite.SiteId
+	result.DistanceMeters = &nearestDistance
+	result.OutsideGeofence = true
+
+	return result
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+// a square of about 111 meters a side around 12.9700,77.5900
+var squareSite = []models.GeoPoint{
+	{Latitude: 12.9700, Longitude: 77.5900},
+	{Latitude: 12.9700, Longitude: 77.5910},
+	{Latitude: 12.9710, Longitude: 77.5910},
+	{Latitude: 12.9710, Longitude: 77.5900},
+}
+
+func TestHaversineDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        models.GeoPoint
+		b        models.GeoPoint
+		distance float64
+	}{
+		{name: "same point", a: models.GeoPoint{Latitude: 12.97, Longitude: 77.59}, b: models.GeoPoint{Latitude: 12.97, Longitude: 77.59}, distance: 0},
+		{name: "one degree of latitude", a: models.GeoPoint{Latitude: 0, Longitude: 0}, b: models.GeoPoint{Latitude: 1, Longitude: 0}, distance: 111195},
+		{name: "one degree of longitude at the equator", a: models.GeoPoint{Latitude: 0, Longitude: 0}, b: models.GeoPoint{Latitude: 0, Longitude: 1}, distance: 111195},
+		{name: "across the antimeridian", a: models.GeoPoint{Latitude: 0, Longitude: 179.9995}, b: models.GeoPoint{Latitude: 0, Longitude: -179.9995}, distance: 111},
+		{name: "antipodes", a: models.GeoPoint{Latitude: 0, Longitude: 0}, b: models.GeoPoint{Latitude: 0, Longitude: 180}, distance: math.Pi * earthRadiusMeters},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if distance := HaversineDistance(test.a, test.b); math.Abs(distance-test.distance) > 1 {
+				t.Errorf("expected %.0f meters, got %.1f", test.distance, distance)
+			}
+		})
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	tests := []struct {
+		name   string
+		point  models.GeoPoint
+		inside bool
+	}{
+		{name: "center", point: models.GeoPoint{Latitude: 12.9705, Longitude: 77.5905}, inside: true},
+		{name: "north of the square", point: models.GeoPoint{Latitude: 12.9712, Longitude: 77.5905}, inside: false},
+		{name: "west of the square", point: models.GeoPoint{Latitude: 12.9705, Longitude: 77.5899}, inside: false},
+		{name: "level with a corner", point: models.GeoPoint{Latitude: 12.9710, Longitude: 77.5920}, inside: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if inside := PointInPolygon(test.point, squareSite); inside != test.inside {
+				t.Errorf("expected inside %v, got %v", test.inside, inside)
+			}
+		})
+	}
+}
+
+func TestDistanceToPolygon(t *testing.T) {
+	tests := []struct {
+		name     string
+		point    models.GeoPoint
+		distance float64
+	}{
+		{name: "inside", point: models.GeoPoint{Latitude: 12.9705, Longitude: 77.5905}, distance: 0},
+		{name: "north of an edge", point: models.GeoPoint{Latitude: 12.9711, Longitude: 77.5905}, distance: 11.1},
+		{name: "east of an edge", point: models.GeoPoint{Latitude: 12.9705, Longitude: 77.5911}, distance: 10.8},
+		{name: "off a corner", point: models.GeoPoint{Latitude: 12.9711, Longitude: 77.5911}, distance: 15.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if distance := DistanceToPolygon(test.point, squareSite); math.Abs(distance-test.distance) > 0.2 {
+				t.Errorf("expected %.1f meters, got %.2f", test.distance, distance)
+			}
+		})
+	}
+}
+
+func TestEvaluateGeofence(t *testing.T) {
+	office := &models.WorkSite{
+		SiteId:          "office",
+		SiteType:        "circle",
+		CenterLatitude:  floatPointer(12.9705),
+		CenterLongitude: floatPointer(77.5905),
+		RadiusMeters:    floatPointer(100),
+		Enforcement:     "flag",
+	}
+
+	warehouse := &models.WorkSite{
+		SiteId:      "warehouse",
+		SiteType:    "polygon",
+		Polygon:     squareSite,
+		Enforcement: "reject",
+	}
+
+	farSite := &models.WorkSite{
+		SiteId:          "far",
+		SiteType:        "circle",
+		CenterLatitude:  floatPointer(13.0500),
+		CenterLongitude: floatPointer(77.5905),
+		RadiusMeters:    floatPointer(100),
+		Enforcement:     "flag",
+	}
+
+	incompleteSite := &models.WorkSite{
+		SiteId:      "incomplete",
+		SiteType:    "polygon",
+		Polygon:     squareSite[:2],
+		Enforcement: "reject",
+	}
+
+	tests := []struct {
+		name            string
+		sites           []*models.WorkSite
+		point           models.GeoPoint
+		siteId          string
+		outsideGeofence bool
+		reject          bool
+	}{
+		{
+			name:  "no sites assigned",
+			point: models.GeoPoint{Latitude: 12.9705, Longitude: 77.5905},
+		},
+		{
+			name:   "inside a circle",
+			sites:  []*models.WorkSite{office},
+			point:  models.GeoPoint{Latitude: 12.9710, Longitude: 77.5905},
+			siteId: "office",
+		},
+		{
+			name:            "outside a flagging circle",
+			sites:           []*models.WorkSite{office},
+			point:           models.GeoPoint{Latitude: 12.9800, Longitude: 77.5905},
+			siteId:          "office",
+			outsideGeofence: true,
+		},
+		{
+			name:   "inside a rejecting polygon",
+			sites:  []*models.WorkSite{warehouse},
+			point:  models.GeoPoint{Latitude: 12.9705, Longitude: 77.5905},
+			siteId: "warehouse",
+		},
+		{
+			name:            "outside a rejecting polygon",
+			sites:           []*models.WorkSite{warehouse},
+			point:           models.GeoPoint{Latitude: 12.9800, Longitude: 77.5905},
+			siteId:          "warehouse",
+			outsideGeofence: true,
+			reject:          true,
+		},
+		{
+			name:   "inside one site and outside a rejecting one",
+			sites:  []*models.WorkSite{warehouse, farSite},
+			point:  models.GeoPoint{Latitude: 13.0500, Longitude: 77.5905},
+			siteId: "far",
+		},
+		{
+			name:            "nearest site is reported when outside every site",
+			sites:           []*models.WorkSite{farSite, office},
+			point:           models.GeoPoint{Latitude: 12.9800, Longitude: 77.5905},
+			siteId:          "office",
+			outsideGeofence: true,
+		},
+		{
+			name:  "incomplete geometry is not enforced",
+			sites: []*models.WorkSite{incompleteSite},
+			point: models.GeoPoint{Latitude: 12.9800, Longitude: 77.5905},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := EvaluateGeofence(test.sites, test.point)
+
+			siteId := ""
+
+			if result.SiteId != nil {
+				siteId = *result.SiteId
+			}
+
+			if siteId != test.siteId {
+				t.Errorf("expected site %q, got %q", test.siteId, siteId)
+			}
+
+			if (result.DistanceMeters != nil) != (test.siteId != "") {
+				t.Errorf("expected a distance only with a site, got %v", result.DistanceMeters)
+			}
+
+			if result.OutsideGeofence != test.outsideGeofence {
+				t.Errorf("expected outside geofence %v, got %v", test.outsideGeofence, result.OutsideGeofence)
+			}
+
+			if result.Reject != test.reject {
+				t.Errorf("expected reject %v, got %v", test.reject, result.Reject)
+			}
+		})
+	}
+}
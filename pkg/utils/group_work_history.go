@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// FormatMinutes formats a duration in minutes as "HH:MM", hours may exceed 24
+func FormatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// GroupWorkHistoryByDay merges the work sessions of each day into a single entry. the
+// sessions must be ordered by date, open sessions are not counted in the day's hours.
+func GroupWorkHistoryByDay(sessions []*models.UserWorkHistoryForPdf) ([]*models.UserWorkHistoryForPdf, error) {
+	var days []*models.UserWorkHistoryForPdf
+	var daySummaries []string
+
+	closeDay := func() {
+		if len(days) == 0 {
+			return
+		}
+
+		day := days[len(days)-1]
+		day.WorkHours = FormatMinutes(day.WorkedMinutes)
+		day.WorkSummary = strings.Join(daySummaries, "; ")
+	}
+
+	for _, session := range sessions {
+		if len(days) == 0 || days[len(days)-1].Date != session.Date {
+			closeDay()
+
+			days = append(days, &models.UserWorkHistoryForPdf{
+				Date:              session.Date,
+				LoginTime:         session.LoginTime,
+				PartialLeave:      session.PartialLeave,
+				PartialLeaveHours: session.PartialLeaveHours,
+			})
+			daySummaries = nil
+		}
+
+		day := days[len(days)-1]
+		day.LogoutTime = session.LogoutTime
+		day.LateMinutes += session.LateMinutes
+
+		if session.LogoutTime == "pending" {
+			continue
+		}
+
+		day.WorkedMinutes += session.WorkedMinutes
+		day.EarlyDepartureMinutes += session.EarlyDepartureMinutes
+		day.OvertimeMinutes += session.OvertimeMinutes
+		day.NetWorkedMinutes += session.NetWorkedMinutes
+
+		if session.WorkSummary != "" && session.WorkSummary != "pending" {
+			daySummaries = append(daySummaries, session.WorkSummary)
+		}
+	}
+
+	closeDay()
+
+	return days, nil
+}
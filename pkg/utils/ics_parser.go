@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// events longer than this are not holidays, they are skipped instead of flooding the calendar
+const maxIcsEventDays = 31
+
+var icsTextUnescaper = strings.NewReplacer(`\\`, `\`, `\,`, `,`, `\;`, `;`, `\n`, " ", `\N`, " ")
+
+// ParseIcsHolidays reads the events of an iCalendar file, an event spanning several days gives
+// one holiday per date. the end of an all day event is exclusive as defined by RFC 5545.
+// recurrence rules are not expanded, published holiday calendars list every year's dates.
+func ParseIcsHolidays(reader io.Reader) ([]*models.IcsHoliday, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	//long lines are folded into several lines starting with a space or a tab
+	var lines []string
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("unable to read the ics file")
+	}
+
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, errors.New("invalid ics file")
+	}
+
+	var holidays []*models.IcsHoliday
+	var inEvent bool
+	var uid, summary, dtStart, dtEnd string
+
+	for _, line := range lines {
+		property, value, found := strings.Cut(line, ":")
+
+		if !found {
+			continue
+		}
+
+		name, _, _ := strings.Cut(property, ";")
+		name = strings.ToUpper(name)
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			uid, summary, dtStart, dtEnd = "", "", "", ""
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+
+			eventHolidays, err := icsEventHolidays(uid, summary, dtStart, dtEnd)
+
+			if err != nil {
+				return nil, err
+			}
+
+			holidays = append(holidays, eventHolidays...)
+		case !inEvent:
+		case name == "UID":
+			uid = value
+		case name == "SUMMARY":
+			summary = strings.TrimSpace(icsTextUnescaper.Replace(value))
+		case name == "DTSTART":
+			dtStart = value
+		case name == "DTEND":
+			dtEnd = value
+		}
+	}
+
+	return holidays, nil
+}
+
+func icsEventHolidays(uid, summary, dtStart, dtEnd string) ([]*models.IcsHoliday, error) {
+	if dtStart == "" || summary == "" {
+		return nil, nil
+	}
+
+	startDate, err := parseIcsDate(dtStart)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lastDate := startDate
+
+	if dtEnd != "" {
+		endDate, err := parseIcsDate(dtEnd)
+
+		if err != nil {
+			return nil, err
+		}
+
+		//an all day end, or a timed end at midnight, is the first day after the event
+		if len(dtEnd) == 8 || strings.HasPrefix(dtEnd[8:], "T000000") {
+			endDate = endDate.AddDate(0, 0, -1)
+		}
+
+		if endDate.After(startDate) {
+			lastDate = endDate
+		}
+	}
+
+	if lastDate.Sub(startDate) >= maxIcsEventDays*24*time.Hour {
+		return nil, nil
+	}
+
+	var holidays []*models.IcsHoliday
+
+	for date := startDate; !date.After(lastDate); date = date.AddDate(0, 0, 1) {
+		holidays = append(holidays, &models.IcsHoliday{
+			Uid:  uid,
+			Date: date.Format("2006-01-02"),
+			Name: summary,
+		})
+	}
+
+	return holidays, nil
+}
+
+// parseIcsDate reads the date part of a DATE or DATE-TIME value
+func parseIcsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("invalid date in the ics file")
+	}
+
+	date, err := time.Parse("20060102", value[:8])
+
+	if err != nil {
+		return time.Time{}, errors.New("invalid date in the ics file")
+	}
+
+	return date, nil
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -8,27 +12,72 @@ import (
 	jwt_token "github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateToken(userId string, email string, userName string, adminId string, firebasePassword string) (string, error) {
+// audience of the access tokens, tokens of other services signed with the same key are refused
+const AccessTokenAudience = "ca_project_http_server"
 
+// GenerateAccessToken signs a short lived access token of an admin or user. subject is the admin
+// id or user id, admin id is the organization of the subject.
+func GenerateAccessToken(role string, subject string, adminId string, email string, userName string, ttl time.Duration) (string, error) {
 	secretKey := os.Getenv("JWT_TOKEN_SCRETE_KEY")
 
 	if secretKey == "" {
 		return "", errors.New("missing JWT_TOKEN_SCRETE_KEY env variable")
 	}
 
+	issuedAt := time.Now()
+
 	token := jwt_token.NewWithClaims(
 		jwt_token.SigningMethodHS256,
 		jwt_token.MapClaims{
-			"admin_id":         adminId,
-			"id":               userId,
-			"user_name":        userName,
-			"email":            email,
-			"firebasepassword": firebasePassword,
-			"expiry":           time.Now().Add(time.Minute * 2).Unix(),
+			"sub":       subject,
+			"aud":       AccessTokenAudience,
+			"iat":       issuedAt.Unix(),
+			"exp":       issuedAt.Add(ttl).Unix(),
+			"role":      role,
+			"admin_id":  adminId,
+			"user_name": userName,
+			"email":     email,
 		},
 	)
 
-	tokenString, err := token.SignedString([]byte(secretKey))
+	return token.SignedString([]byte(secretKey))
+}
+
+// ParseAccessToken verifies the signature, audience, issue time and expiry of an access token,
+// tokens without an expiry are refused
+func ParseAccessToken(tokenString string) (*jwt_token.Token, error) {
+	secretKey := os.Getenv("JWT_TOKEN_SCRETE_KEY")
+
+	if secretKey == "" {
+		return nil, errors.New("missing JWT_TOKEN_SCRETE_KEY env variable")
+	}
+
+	return jwt_token.Parse(
+		tokenString,
+		func(token *jwt_token.Token) (interface{}, error) {
+			return []byte(secretKey), nil
+		},
+		jwt_token.WithValidMethods([]string{jwt_token.SigningMethodHS256.Alg()}),
+		jwt_token.WithAudience(AccessTokenAudience),
+		jwt_token.WithExpirationRequired(),
+		jwt_token.WithIssuedAt(),
+	)
+}
+
+// GenerateRefreshToken returns a random refresh token and the hash it is stored under
+func GenerateRefreshToken() (string, string, error) {
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	return refreshToken, HashRefreshToken(refreshToken), nil
+}
 
-	return tokenString, err
+func HashRefreshToken(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
 }
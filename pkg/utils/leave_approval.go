@@ -0,0 +1,38 @@
+package utils
+
+import "github.com/vithsutra/ca_project_http_server/internals/models"
+
+// BuildLeaveApprovalSteps turns the chain of a leave into its steps. a chain missing means the
+// admin alone approves, a reporting manager step is left out for a user without manager, and an
+// admin step is used when no step is left.
+func BuildLeaveApprovalSteps(leaveId string, chain []string, managerId *string) []*models.LeaveApprovalStep {
+	var steps []*models.LeaveApprovalStep
+
+	for _, approverType := range chain {
+		step := &models.LeaveApprovalStep{
+			LeaveId:      leaveId,
+			StepNumber:   int16(len(steps) + 1),
+			ApproverType: approverType,
+		}
+
+		if approverType == models.ApproverReportingManager {
+			if managerId == nil {
+				continue
+			}
+
+			step.ApproverUserId = managerId
+		}
+
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		steps = append(steps, &models.LeaveApprovalStep{
+			LeaveId:      leaveId,
+			StepNumber:   1,
+			ApproverType: models.ApproverAdmin,
+		})
+	}
+
+	return steps
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/xuri/excelize/v2"
+)
+
+var musterRollStatusCodes = map[string]string{
+	"present":    "P",
+	"absent":     "A",
+	"on_leave":   "L",
+	"holiday":    "H",
+	"weekend":    "WO",
+	"half_day":   "HD",
+	"incomplete": "I",
+}
+
+var musterRollLegend = [][]string{
+	{"P", "present"},
+	{"A", "absent"},
+	{"L", "on leave"},
+	{"H", "holiday"},
+	{"WO", "weekly off"},
+	{"HD", "half day, counted half present and half absent"},
+	{"I", "incomplete, a session is open or waiting for review"},
+}
+
+// BuildMusterRollRow turns the attendance days of one employee within the month into status
+// codes and totals
+func BuildMusterRollRow(employee *models.MusterRollEmployee, days []*models.AttendanceDay, daysInMonth int, overtimeMinutes int) (*models.MusterRollRow, error) {
+	row := &models.MusterRollRow{
+		Name:            employee.Name,
+		CategoryName:    employee.CategoryName,
+		Codes:           make([]string, daysInMonth),
+		OvertimeMinutes: overtimeMinutes,
+	}
+
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.AttendanceDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		row.Codes[date.Day()-1] = musterRollStatusCodes[day.Status]
+
+		switch day.Status {
+		case "present":
+			row.PresentDays++
+		case "absent":
+			row.AbsentDays++
+		case "on_leave":
+			row.LeaveDays++
+		case "half_day":
+			row.PresentDays += 0.5
+			row.AbsentDays += 0.5
+		}
+	}
+
+	return row, nil
+}
+
+func musterRollTable(roll *models.MusterRoll) [][]interface{} {
+	header := []interface{}{"SN", "Employee", "Category"}
+
+	for day := 1; day <= roll.Days; day++ {
+		header = append(header, strconv.Itoa(day))
+	}
+
+	header = append(header, "Present", "Absent", "Leave", "Overtime Hrs")
+
+	table := [][]interface{}{header}
+
+	for index, row := range roll.Rows {
+		record := []interface{}{index + 1, row.Name, row.CategoryName}
+
+		for _, code := range row.Codes {
+			record = append(record, code)
+		}
+
+		record = append(record, row.PresentDays, row.AbsentDays, row.LeaveDays, FormatMinutes(row.OvertimeMinutes))
+
+		table = append(table, record)
+	}
+
+	return table
+}
+
+// WriteMusterRollCsv writes the header and one record per employee, without the legend so the
+// file imports cleanly into payroll sheets
+func WriteMusterRollCsv(writer io.Writer, roll *models.MusterRoll) error {
+	csvWriter := csv.NewWriter(writer)
+
+	for _, row := range musterRollTable(roll) {
+		record := make([]string, len(row))
+
+		for index, value := range row {
+			record[index] = fmt.Sprint(value)
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// WriteMusterRollXlsx writes the muster roll to a single sheet with the legend below the table
+func WriteMusterRollXlsx(writer io.Writer, roll *models.MusterRoll) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheetName := "Muster Roll " + roll.Month
+
+	if err := file.SetSheetName("Sheet1", sheetName); err != nil {
+		return err
+	}
+
+	table := musterRollTable(roll)
+
+	for index, row := range table {
+		cell, err := excelize.CoordinatesToCellName(1, index+1)
+
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(sheetName, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	legendStartRow := len(table) + 2
+
+	for index, legend := range musterRollLegend {
+		cell, err := excelize.CoordinatesToCellName(2, legendStartRow+index)
+
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(sheetName, cell, &legend); err != nil {
+			return err
+		}
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	lastHeaderCell, err := excelize.CoordinatesToCellName(len(table[0]), 1)
+
+	if err != nil {
+		return err
+	}
+
+	if err := file.SetCellStyle(sheetName, "A1", lastHeaderCell, headerStyle); err != nil {
+		return err
+	}
+
+	if err := file.SetColWidth(sheetName, "B", "C", 24); err != nil {
+		return err
+	}
+
+	firstDayColumn, err := excelize.ColumnNumberToName(4)
+
+	if err != nil {
+		return err
+	}
+
+	lastDayColumn, err := excelize.ColumnNumberToName(3 + roll.Days)
+
+	if err != nil {
+		return err
+	}
+
+	if err := file.SetColWidth(sheetName, firstDayColumn, lastDayColumn, 4.5); err != nil {
+		return err
+	}
+
+	//keep the employee columns and the day numbers visible while scrolling
+	if err := file.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      3,
+		YSplit:      1,
+		TopLeftCell: "D2",
+		ActivePane:  "bottomRight",
+	}); err != nil {
+		return err
+	}
+
+	_, err = file.WriteTo(writer)
+
+	return err
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{address: "93.184.216.34", public: true},
+		{address: "8.8.8.8", public: true},
+		{address: "2606:4700:4700::1111", public: true},
+		{address: "127.0.0.1", public: false},
+		{address: "::1", public: false},
+		{address: "10.1.2.3", public: false},
+		{address: "172.16.0.1", public: false},
+		{address: "192.168.1.1", public: false},
+		{address: "fd00::1", public: false},
+		{address: "169.254.169.254", public: false},
+		{address: "fe80::1", public: false},
+		{address: "0.0.0.0", public: false},
+		{address: "::", public: false},
+		{address: "100.64.0.1", public: false},
+		{address: "198.18.0.1", public: false},
+		{address: "224.0.0.1", public: false},
+		{address: "255.255.255.255", public: false},
+		{address: "::ffff:127.0.0.1", public: false},
+		{address: "::ffff:10.0.0.1", public: false},
+		{address: "::ffff:8.8.8.8", public: true},
+		{address: "64:ff9b::a00:1", public: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			if public := IsPublicAddress(netip.MustParseAddr(test.address)); public != test.public {
+				t.Errorf("expected public %v, got %v", test.public, public)
+			}
+		})
+	}
+
+	if IsPublicAddress(netip.Addr{}) {
+		t.Error("expected the zero address not to be public")
+	}
+}
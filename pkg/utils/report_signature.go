@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ParseReportSigningKey reads the base64 encoded 32 byte ed25519 seed of the report signing key
+func ParseReportSigningKey(encodedSeed string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.New("report signing key must be a 32 byte seed")
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ReportSignedPayload joins the fields of an issued report that the signature covers
+func ReportSignedPayload(reportId, userName, categoryName, startDate, endDate, sha256 string) string {
+	return strings.Join([]string{reportId, userName, categoryName, startDate, endDate, sha256}, "|")
+}
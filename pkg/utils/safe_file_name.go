@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SafeFileName replaces everything but letters and digits with an underscore, for names used
+// in file names and Content-Disposition headers
+func SafeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
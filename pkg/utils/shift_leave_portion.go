@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+func minutesOfDay(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+
+	if err != nil {
+		return 0, errors.New("invalid time formate")
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func clockOfMinutes(minutes int) string {
+	minutes = minutes % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// ShiftMinutes returns the length of a shift, a shift ending at or before its start time
+// crosses midnight
+func ShiftMinutes(shiftStartTime, shiftEndTime string) (int, error) {
+	shiftStart, err := minutesOfDay(shiftStartTime)
+
+	if err != nil {
+		return 0, err
+	}
+
+	shiftEnd, err := minutesOfDay(shiftEndTime)
+
+	if err != nil {
+		return 0, err
+	}
+
+	shiftMinutes := (shiftEnd - shiftStart + minutesPerDay) % minutesPerDay
+
+	if shiftMinutes == 0 {
+		shiftMinutes = minutesPerDay
+	}
+
+	return shiftMinutes, nil
+}
+
+// ShiftLeavePortion returns the start and end time and the length in minutes of a partial day
+// leave together with the length of the shift. the halves split the shift at its midpoint, an
+// hour range must lie inside the shift. shifts crossing midnight are supported.
+func ShiftLeavePortion(granularity, startTime, endTime, shiftStartTime, shiftEndTime string) (string, string, int, int, error) {
+	shiftStart, err := minutesOfDay(shiftStartTime)
+
+	if err != nil {
+		return "", "", 0, 0, err
+	}
+
+	shiftMinutes, err := ShiftMinutes(shiftStartTime, shiftEndTime)
+
+	if err != nil {
+		return "", "", 0, 0, err
+	}
+
+	switch granularity {
+	case "first_half":
+		return shiftStartTime, clockOfMinutes(shiftStart + shiftMinutes/2), shiftMinutes / 2, shiftMinutes, nil
+	case "second_half":
+		return clockOfMinutes(shiftStart + shiftMinutes/2), shiftEndTime, shiftMinutes - shiftMinutes/2, shiftMinutes, nil
+	case "hours":
+	default:
+		return "", "", 0, 0, errors.New("invalid leave granularity")
+	}
+
+	leaveStart, err := minutesOfDay(startTime)
+
+	if err != nil {
+		return "", "", 0, 0, err
+	}
+
+	leaveEnd, err := minutesOfDay(endTime)
+
+	if err != nil {
+		return "", "", 0, 0, err
+	}
+
+	//offsets from the shift start, so a range after midnight of a night shift stays ordered
+	startOffset := (leaveStart - shiftStart + minutesPerDay) % minutesPerDay
+	endOffset := (leaveEnd - shiftStart + minutesPerDay) % minutesPerDay
+
+	if endOffset == 0 {
+		endOffset = minutesPerDay
+	}
+
+	if startOffset >= shiftMinutes || endOffset > shiftMinutes {
+		return "", "", 0, 0, fmt.Errorf("leave hours must be within the shift %s-%s", shiftStartTime, shiftEndTime)
+	}
+
+	if endOffset <= startOffset {
+		return "", "", 0, 0, errors.New("leave end time must be after the start time")
+	}
+
+	if endOffset-startOffset >= shiftMinutes {
+		return "", "", 0, 0, errors.New("leave hours cover the whole shift, please apply a full day leave")
+	}
+
+	return startTime, endTime, endOffset - startOffset, shiftMinutes, nil
+}
+
+// LeavePortionsOverlap reports whether two partial day leaves of the same date clash, the times
+// are compared as offsets from the shift start so ranges of a night shift compare correctly
+func LeavePortionsOverlap(startTime1, endTime1, startTime2, endTime2, shiftStartTime string) (bool, error) {
+	shiftStart, err := minutesOfDay(shiftStartTime)
+
+	if err != nil {
+		return false, err
+	}
+
+	var offsets [4]int
+
+	for index, clock := range []string{startTime1, endTime1, startTime2, endTime2} {
+		minutes, err := minutesOfDay(clock)
+
+		if err != nil {
+			return false, err
+		}
+
+		offsets[index] = (minutes - shiftStart + minutesPerDay) % minutesPerDay
+	}
+
+	//a range ending at the shift start wraps to the end of the day
+	for _, index := range []int{1, 3} {
+		if offsets[index] == 0 {
+			offsets[index] = minutesPerDay
+		}
+	}
+
+	return offsets[0] < offsets[3] && offsets[2] < offsets[1], nil
+}
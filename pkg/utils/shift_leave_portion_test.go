@@ -0,0 +1,245 @@
+package utils
+
+import "testing"
+
+func TestShiftMinutes(t *testing.T) {
+	tests := []struct {
+		name           string
+		shiftStartTime string
+		shiftEndTime   string
+		shiftMinutes   int
+		wantErr        bool
+	}{
+		{name: "day shift", shiftStartTime: "09:00", shiftEndTime: "18:00", shiftMinutes: 540},
+		{name: "night shift crossing midnight", shiftStartTime: "22:00", shiftEndTime: "06:00", shiftMinutes: 480},
+		{name: "shift ending at midnight", shiftStartTime: "16:00", shiftEndTime: "00:00", shiftMinutes: 480},
+		{name: "shift starting at midnight", shiftStartTime: "00:00", shiftEndTime: "08:00", shiftMinutes: 480},
+		{name: "round the clock shift", shiftStartTime: "07:00", shiftEndTime: "07:00", shiftMinutes: 1440},
+		{name: "invalid time", shiftStartTime: "25:00", shiftEndTime: "06:00", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shiftMinutes, err := ShiftMinutes(test.shiftStartTime, test.shiftEndTime)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			if shiftMinutes != test.shiftMinutes {
+				t.Errorf("expected %d minutes, got %d", test.shiftMinutes, shiftMinutes)
+			}
+		})
+	}
+}
+
+func TestShiftLeavePortion(t *testing.T) {
+	tests := []struct {
+		name           string
+		granularity    string
+		startTime      string
+		endTime        string
+		shiftStartTime string
+		shiftEndTime   string
+		leaveStartTime string
+		leaveEndTime   string
+		leaveMinutes   int
+		shiftMinutes   int
+		wantErr        bool
+	}{
+		{
+			name:           "first half of a day shift",
+			granularity:    "first_half",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			leaveStartTime: "09:00",
+			leaveEndTime:   "13:30",
+			leaveMinutes:   270,
+			shiftMinutes:   540,
+		},
+		{
+			name:           "second half of a day shift",
+			granularity:    "second_half",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			leaveStartTime: "13:30",
+			leaveEndTime:   "18:00",
+			leaveMinutes:   270,
+			shiftMinutes:   540,
+		},
+		{
+			name:           "first half of a night shift ends at midnight",
+			granularity:    "first_half",
+			shiftStartTime: "20:00",
+			shiftEndTime:   "04:00",
+			leaveStartTime: "20:00",
+			leaveEndTime:   "00:00",
+			leaveMinutes:   240,
+			shiftMinutes:   480,
+		},
+		{
+			name:           "second half of a night shift lies after midnight",
+			granularity:    "second_half",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "07:00",
+			leaveStartTime: "02:30",
+			leaveEndTime:   "07:00",
+			leaveMinutes:   270,
+			shiftMinutes:   540,
+		},
+		{
+			name:           "hours of a day shift",
+			granularity:    "hours",
+			startTime:      "10:00",
+			endTime:        "12:15",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			leaveStartTime: "10:00",
+			leaveEndTime:   "12:15",
+			leaveMinutes:   135,
+			shiftMinutes:   540,
+		},
+		{
+			name:           "hours of a night shift across midnight",
+			granularity:    "hours",
+			startTime:      "23:00",
+			endTime:        "01:30",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "06:00",
+			leaveStartTime: "23:00",
+			leaveEndTime:   "01:30",
+			leaveMinutes:   150,
+			shiftMinutes:   480,
+		},
+		{
+			name:           "hours of a night shift up to the shift end",
+			granularity:    "hours",
+			startTime:      "03:00",
+			endTime:        "06:00",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "06:00",
+			leaveStartTime: "03:00",
+			leaveEndTime:   "06:00",
+			leaveMinutes:   180,
+			shiftMinutes:   480,
+		},
+		{
+			name:           "hours starting before a day shift",
+			granularity:    "hours",
+			startTime:      "08:00",
+			endTime:        "10:00",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			wantErr:        true,
+		},
+		{
+			name:           "hours running past a night shift",
+			granularity:    "hours",
+			startTime:      "05:00",
+			endTime:        "07:00",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "06:00",
+			wantErr:        true,
+		},
+		{
+			name:           "hours in the off duty gap of a night shift",
+			granularity:    "hours",
+			startTime:      "12:00",
+			endTime:        "13:00",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "06:00",
+			wantErr:        true,
+		},
+		{
+			name:           "hours ending before they start",
+			granularity:    "hours",
+			startTime:      "12:00",
+			endTime:        "11:00",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			wantErr:        true,
+		},
+		{
+			name:           "hours covering the whole shift",
+			granularity:    "hours",
+			startTime:      "22:00",
+			endTime:        "06:00",
+			shiftStartTime: "22:00",
+			shiftEndTime:   "06:00",
+			wantErr:        true,
+		},
+		{
+			name:           "unknown granularity",
+			granularity:    "quarter",
+			shiftStartTime: "09:00",
+			shiftEndTime:   "18:00",
+			wantErr:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leaveStartTime, leaveEndTime, leaveMinutes, shiftMinutes, err := ShiftLeavePortion(test.granularity, test.startTime, test.endTime, test.shiftStartTime, test.shiftEndTime)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			if leaveStartTime != test.leaveStartTime || leaveEndTime != test.leaveEndTime {
+				t.Errorf("expected %s-%s, got %s-%s", test.leaveStartTime, test.leaveEndTime, leaveStartTime, leaveEndTime)
+			}
+
+			if leaveMinutes != test.leaveMinutes || shiftMinutes != test.shiftMinutes {
+				t.Errorf("expected %d of %d minutes, got %d of %d", test.leaveMinutes, test.shiftMinutes, leaveMinutes, shiftMinutes)
+			}
+		})
+	}
+}
+
+func TestLeavePortionsOverlap(t *testing.T) {
+	tests := []struct {
+		name           string
+		startTime1     string
+		endTime1       string
+		startTime2     string
+		endTime2       string
+		shiftStartTime string
+		overlap        bool
+	}{
+		{name: "halves of a day shift", startTime1: "09:00", endTime1: "13:30", startTime2: "13:30", endTime2: "18:00", shiftStartTime: "09:00", overlap: false},
+		{name: "hours inside a half", startTime1: "09:00", endTime1: "13:30", startTime2: "11:00", endTime2: "12:00", shiftStartTime: "09:00", overlap: true},
+		{name: "night shift ranges across midnight", startTime1: "23:00", endTime1: "01:00", startTime2: "00:30", endTime2: "02:00", shiftStartTime: "22:00", overlap: true},
+		{name: "night shift ranges meeting at midnight", startTime1: "22:00", endTime1: "00:00", startTime2: "00:00", endTime2: "02:00", shiftStartTime: "22:00", overlap: false},
+		{name: "night shift range before and after midnight", startTime1: "22:30", endTime1: "23:30", startTime2: "01:00", endTime2: "03:00", shiftStartTime: "22:00", overlap: false},
+		{name: "round the clock range ending at the shift start", startTime1: "20:00", endTime1: "07:00", startTime2: "06:00", endTime2: "06:30", shiftStartTime: "07:00", overlap: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			overlap, err := LeavePortionsOverlap(test.startTime1, test.endTime1, test.startTime2, test.endTime2, test.shiftStartTime)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if overlap != test.overlap {
+				t.Errorf("expected overlap %v, got %v", test.overlap, overlap)
+			}
+
+			//the check does not depend on the order of the two leaves
+			reversed, err := LeavePortionsOverlap(test.startTime2, test.endTime2, test.startTime1, test.endTime1, test.shiftStartTime)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reversed != test.overlap {
+				t.Errorf("expected reversed overlap %v, got %v", test.overlap, reversed)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func wholeMinutes(duration time.Duration) int {
+	return int(duration / time.Minute)
+}
+
+// MeasureShiftSession measures the session against its shift. lateness counts on the first
+// session of the shift once the login is past the grace period, early departure counts on the
+// last one and the break is taken from the first one.
+func MeasureShiftSession(session *models.ShiftSession) *models.ShiftSessionMetrics {
+	shiftLength := time.Duration(session.ShiftMinutes) * time.Minute
+	shiftEndAt := session.ShiftStartAt.Add(shiftLength)
+	graceEndAt := session.ShiftStartAt.Add(time.Duration(session.GraceMinutes) * time.Minute)
+
+	metrics := new(models.ShiftSessionMetrics)
+
+	if session.FirstSession && session.LoginAt.After(graceEndAt) {
+		metrics.LateMinutes = wholeMinutes(min(session.LoginAt.Sub(session.ShiftStartAt), shiftLength))
+	}
+
+	if session.LogoutAt == nil {
+		return metrics
+	}
+
+	logoutAt := *session.LogoutAt
+
+	earlyDepartureMinutes := 0
+
+	if session.LastSession && logoutAt.Before(shiftEndAt) {
+		leftAt := logoutAt
+
+		if leftAt.Before(session.ShiftStartAt) {
+			leftAt = session.ShiftStartAt
+		}
+
+		earlyDepartureMinutes = wholeMinutes(shiftEndAt.Sub(leftAt))
+	}
+
+	//only the time past the shift end counts, a session starting after the end counts fully
+	overtimeFrom := shiftEndAt
+
+	if session.LoginAt.After(overtimeFrom) {
+		overtimeFrom = session.LoginAt
+	}
+
+	overtimeMinutes := wholeMinutes(max(logoutAt.Sub(overtimeFrom), 0))
+
+	netWorkedMinutes := wholeMinutes(logoutAt.Sub(session.LoginAt))
+
+	if session.FirstSession {
+		netWorkedMinutes -= session.BreakMinutes
+	}
+
+	netWorkedMinutes = max(netWorkedMinutes, 0)
+
+	metrics.EarlyDepartureMinutes = &earlyDepartureMinutes
+	metrics.OvertimeMinutes = &overtimeMinutes
+	metrics.NetWorkedMinutes = &netWorkedMinutes
+
+	return metrics
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestMeasureShiftSession(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Kolkata")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(clock string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", clock, location)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	atPointer := func(clock string) *time.Time {
+		parsed := at(clock)
+		return &parsed
+	}
+
+	//a day shift from 09:00 to 18:00 and a night shift from 22:00 to 06:00 of the next day
+	dayShiftStart := at("2025-03-10 09:00")
+	nightShiftStart := at("2025-03-10 22:00")
+
+	tests := []struct {
+		name    string
+		session models.ShiftSession
+		metrics models.ShiftSessionMetrics
+	}{
+		{
+			name: "day shift on time",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 09:00"),
+				LogoutAt:     atPointer("2025-03-10 18:00"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				BreakMinutes: 60,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(480),
+			},
+		},
+		{
+			name: "login exactly on the grace boundary",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 09:10"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				GraceMinutes: 10,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{LateMinutes: 0},
+		},
+		{
+			name: "login a minute past the grace period counts from the shift start",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 09:11"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				GraceMinutes: 10,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{LateMinutes: 11},
+		},
+		{
+			name: "later session of the shift is never late",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 14:00"),
+				LogoutAt:     atPointer("2025-03-10 18:00"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				BreakMinutes: 60,
+				FirstSession: false,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(240),
+			},
+		},
+		{
+			name: "early departure only counts on the last session",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 09:00"),
+				LogoutAt:     atPointer("2025-03-10 13:00"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				FirstSession: true,
+				LastSession:  false,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(240),
+			},
+		},
+		{
+			name: "night shift late login before midnight",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 22:20"),
+				LogoutAt:     atPointer("2025-03-11 06:00"),
+				ShiftStartAt: nightShiftStart,
+				ShiftMinutes: 480,
+				GraceMinutes: 15,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           20,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(460),
+			},
+		},
+		{
+			name: "night shift login after midnight stays late on the shift of the evening before",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-11 00:30"),
+				LogoutAt:     atPointer("2025-03-11 06:00"),
+				ShiftStartAt: nightShiftStart,
+				ShiftMinutes: 480,
+				GraceMinutes: 15,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           150,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(330),
+			},
+		},
+		{
+			name: "night shift early departure after midnight",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 22:00"),
+				LogoutAt:     atPointer("2025-03-11 05:15"),
+				ShiftStartAt: nightShiftStart,
+				ShiftMinutes: 480,
+				BreakMinutes: 30,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(45),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(405),
+			},
+		},
+		{
+			name: "night shift overtime past the shift end of the next morning",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 21:50"),
+				LogoutAt:     atPointer("2025-03-11 07:30"),
+				ShiftStartAt: nightShiftStart,
+				ShiftMinutes: 480,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(90),
+				NetWorkedMinutes:      intPointer(580),
+			},
+		},
+		{
+			name: "session after the shift end is overtime in full",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 19:00"),
+				LogoutAt:     atPointer("2025-03-10 20:30"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				BreakMinutes: 60,
+				FirstSession: false,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(90),
+				NetWorkedMinutes:      intPointer(90),
+			},
+		},
+		{
+			name: "lateness is capped at the shift length",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 19:00"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				FirstSession: true,
+				LastSession:  true,
+			},
+			metrics: models.ShiftSessionMetrics{LateMinutes: 540},
+		},
+		{
+			name: "break longer than the session leaves no negative net time",
+			session: models.ShiftSession{
+				LoginAt:      at("2025-03-10 09:00"),
+				LogoutAt:     atPointer("2025-03-10 09:20"),
+				ShiftStartAt: dayShiftStart,
+				ShiftMinutes: 540,
+				BreakMinutes: 60,
+				FirstSession: true,
+				LastSession:  false,
+			},
+			metrics: models.ShiftSessionMetrics{
+				LateMinutes:           0,
+				EarlyDepartureMinutes: intPointer(0),
+				OvertimeMinutes:       intPointer(0),
+				NetWorkedMinutes:      intPointer(0),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := MeasureShiftSession(&test.session)
+
+			if metrics.LateMinutes != test.metrics.LateMinutes {
+				t.Errorf("late minutes: expected %d, got %d", test.metrics.LateMinutes, metrics.LateMinutes)
+			}
+
+			compareMinutes(t, "early departure minutes", test.metrics.EarlyDepartureMinutes, metrics.EarlyDepartureMinutes)
+			compareMinutes(t, "overtime minutes", test.metrics.OvertimeMinutes, metrics.OvertimeMinutes)
+			compareMinutes(t, "net worked minutes", test.metrics.NetWorkedMinutes, metrics.NetWorkedMinutes)
+		})
+	}
+}
+
+func compareMinutes(t *testing.T, name string, expected *int, got *int) {
+	t.Helper()
+
+	switch {
+	case expected == nil && got == nil:
+	case expected == nil:
+		t.Errorf("%s: expected none, got %d", name, *got)
+	case got == nil:
+		t.Errorf("%s: expected %d, got none", name, *expected)
+	case *expected != *got:
+		t.Errorf("%s: expected %d, got %d", name, *expected, *got)
+	}
+}
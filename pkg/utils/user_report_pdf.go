@@ -1,21 +1,63 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/signintech/gopdf"
+	"github.com/skip2/go-qrcode"
 	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/assets"
 )
 
-const LOGO_IMAGE_PATH = "./assets/vithsutra_logo.png"
+// letterhead of the reports of admins that did not configure a branding
+var defaultReportBranding = models.ReportBranding{
+	CompanyName:  "VITHSUTRA TECHNOLOGIES PVT. LTD.",
+	PhoneNumber:  "+919113068170",
+	Email:        "contact@vithsutra.com",
+	Website:      "www.vithsutra.com",
+	PrimaryColor: "#000000",
+	AccentColor:  "#000000",
+	Logo:         assets.Logo,
+}
+
+// hexColor splits a #RRGGBB color, anything else is black
+func hexColor(color string) (uint8, uint8, uint8) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+
+	if err != nil || len(color) != 7 {
+		return 0, 0, 0
+	}
+
+	return uint8(value >> 16), uint8(value >> 8), uint8(value)
+}
+
+// ReportLogoSize returns the pixel width and height of a png or jpeg logo
+func ReportLogoSize(logo []byte) (int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(logo))
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if config.Width == 0 || config.Height == 0 {
+		return 0, 0, errors.New("empty logo image")
+	}
+
+	return config.Width, config.Height, nil
+}
 
-func OuterBorderSection(pdf *gopdf.GoPdf) {
-	pdf.SetStrokeColor(0, 0, 0)
+func OuterBorderSection(pdf *gopdf.GoPdf, branding *models.ReportBranding) {
+	pdf.SetStrokeColor(hexColor(branding.AccentColor))
 	pdf.SetLineWidth(0.05)
 	pdf.Line(1, 1, 20, 1)
 	pdf.Line(1, 1, 1, 28.7)
@@ -23,70 +65,214 @@ func OuterBorderSection(pdf *gopdf.GoPdf) {
 	pdf.Line(20, 1, 20, 28.7)
 }
 
-func HeaderSection(pdf *gopdf.GoPdf) error {
+// LogoSection fits the logo into the top left corner keeping its aspect ratio
+func LogoSection(pdf *gopdf.GoPdf, logo []byte) error {
+	boxX, boxY, boxWidth, boxHeight := 1.8, 1.6, 3.5, 2.0
 
-	OuterBorderSection(pdf)
+	width, height, err := ReportLogoSize(logo)
 
-	x := 1.8
-	y := 1.6
-	pdf.Image(LOGO_IMAGE_PATH, x, y, &gopdf.Rect{
-		H: 2,
-		W: 3.5,
-	})
+	if err != nil {
+		return err
+	}
+
+	scale := min(boxWidth/float64(width), boxHeight/float64(height))
+	logoWidth, logoHeight := float64(width)*scale, float64(height)*scale
+
+	imageHolder, err := gopdf.ImageHolderByBytes(logo)
 
-	if err := pdf.SetFont("bold-font", "", 17); err != nil {
+	if err != nil {
 		return err
 	}
 
-	heading1 := "VITHSUTRA TECHNOLOGIES PVT. LTD."
-	phone_number := "+919113068170"
-	email := "contact@vithsutra.com"
-	web_address := "www.vithsutra.com"
+	return pdf.ImageByHolder(imageHolder, boxX+(boxWidth-logoWidth)/2, boxY+(boxHeight-logoHeight)/2, &gopdf.Rect{
+		W: logoWidth,
+		H: logoHeight,
+	})
+}
 
-	x, y = 6.5, 2
+func HeaderSection(pdf *gopdf.GoPdf, branding *models.ReportBranding) error {
 
-	pdf.SetXY(x, y)
-	pdf.Text(heading1)
+	OuterBorderSection(pdf, branding)
 
-	if err := pdf.SetFont("light-font", "", 14); err != nil {
-		return err
+	//without a logo the letterhead is centered on the page
+	textCenter, maxTextWidth := 10.5, 17.0
+
+	if len(branding.Logo) > 0 {
+		if err := LogoSection(pdf, branding.Logo); err != nil {
+			return err
+		}
+
+		textCenter, maxTextWidth = 12.4, 13.6
 	}
 
-	x, y = 6, 2.75
-	pdf.SetXY(x, y)
-	pdf.Text("Phone: ")
+	//long company names are shrunk to fit the line
+	fontSize := 17.0
+	var textWidth float64
 
-	x = pdf.GetX()
+	for ; fontSize >= 10; fontSize-- {
+		if err := pdf.SetFont("bold-font", "", fontSize); err != nil {
+			return err
+		}
 
-	pdf.SetXY(x, y)
-	pdf.Text(phone_number)
+		width, err := pdf.MeasureTextWidth(branding.CompanyName)
 
-	x, y = pdf.GetX()+0.3, 2.75
-	pdf.SetXY(x, y)
-	pdf.Text("Email: ")
+		if err != nil {
+			return err
+		}
 
-	x = pdf.GetX()
+		textWidth = width
 
-	pdf.SetXY(x, y)
-	pdf.Text(email)
+		if textWidth <= maxTextWidth {
+			break
+		}
+	}
 
-	x, y = 8.25, pdf.GetY()+0.75
-	pdf.SetXY(x, y)
-	pdf.Text("Web: ")
+	pdf.SetTextColor(hexColor(branding.PrimaryColor))
+	pdf.SetXY(textCenter-(textWidth/2), 2)
+	pdf.Text(branding.CompanyName)
+	pdf.SetTextColor(0, 0, 0)
 
-	x = pdf.GetX()
+	var contacts []string
 
-	pdf.SetXY(x, y)
-	pdf.Text(web_address)
+	if branding.PhoneNumber != "" {
+		contacts = append(contacts, "Phone: "+branding.PhoneNumber)
+	}
+
+	if branding.Email != "" {
+		contacts = append(contacts, "Email: "+branding.Email)
+	}
+
+	lines := []string{branding.Address, strings.Join(contacts, "   ")}
+
+	if branding.Website != "" {
+		lines = append(lines, "Web: "+branding.Website)
+	}
+
+	if err := pdf.SetFont("light-font", "", 11); err != nil {
+		return err
+	}
+
+	y := 2.7
 
-	pdf.SetStrokeColor(0, 0, 0)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		textWidth, err := pdf.MeasureTextWidth(line)
+
+		if err != nil {
+			return err
+		}
+
+		pdf.SetXY(textCenter-(textWidth/2), y)
+		pdf.Text(line)
+
+		y += 0.45
+	}
+
+	pdf.SetStrokeColor(hexColor(branding.AccentColor))
 	pdf.SetLineWidth(0.05)
 	pdf.Line(1.5, 4, 19.5, 4)
 
 	return nil
 }
 
-func EmployeeInfoSection(pdf *gopdf.GoPdf, employeeName, employeeCategory, date string) error {
+// VerificationSection prints the qr code of the verify url at the right of the employee info
+func VerificationSection(pdf *gopdf.GoPdf, verification *models.ReportVerification) error {
+	qrCode, err := qrcode.Encode(verification.VerifyUrl, qrcode.Medium, 256)
+
+	if err != nil {
+		return err
+	}
+
+	imageHolder, err := gopdf.ImageHolderByBytes(qrCode)
+
+	if err != nil {
+		return err
+	}
+
+	if err := pdf.ImageByHolder(imageHolder, 18, 4.25, &gopdf.Rect{W: 1.5, H: 1.5}); err != nil {
+		return err
+	}
+
+	if err := pdf.SetFont("light-font", "", 7); err != nil {
+		return err
+	}
+
+	label := "scan to verify"
+
+	textWidth, err := pdf.MeasureTextWidth(label)
+
+	if err != nil {
+		return err
+	}
+
+	pdf.SetXY(18.75-(textWidth/2), 6.05)
+	pdf.Text(label)
+
+	return nil
+}
+
+// FooterSection prints the footer text, the generation time, the report id of verifiable
+// reports and the page number below the border of every page, it runs once all pages are
+// rendered
+func FooterSection(pdf *gopdf.GoPdf, branding *models.ReportBranding, verification *models.ReportVerification, generatedAt time.Time) error {
+	pages := pdf.GetNumberOfPages()
+	generatedText := "Generated at " + generatedAt.Format("02-01-2006 15:04")
+
+	for page := 1; page <= pages; page++ {
+		if err := pdf.SetPage(page); err != nil {
+			return err
+		}
+
+		if err := pdf.SetFont("light-font", "", 8); err != nil {
+			return err
+		}
+
+		if branding.FooterText != "" {
+			textWidth, err := pdf.MeasureTextWidth(branding.FooterText)
+
+			if err != nil {
+				return err
+			}
+
+			pdf.SetXY(10.5-(textWidth/2), 29.1)
+			pdf.Text(branding.FooterText)
+		}
+
+		pdf.SetXY(1, 29.45)
+		pdf.Text(generatedText)
+
+		if verification != nil {
+			reportText := "Report " + verification.ReportId
+
+			textWidth, err := pdf.MeasureTextWidth(reportText)
+
+			if err != nil {
+				return err
+			}
+
+			pdf.SetXY(10.5-(textWidth/2), 29.45)
+			pdf.Text(reportText)
+		}
+
+		pageText := fmt.Sprintf("Page %d of %d", page, pages)
+
+		textWidth, err := pdf.MeasureTextWidth(pageText)
+
+		if err != nil {
+			return err
+		}
+
+		pdf.SetXY(20-textWidth, 29.45)
+		pdf.Text(pageText)
+	}
+
+	return nil
+}
+
+func EmployeeInfoSection(pdf *gopdf.GoPdf, employeeName, employeeCategory, date string, dateX float64) error {
 	x, y := 1.5, 5.5
 
 	if err := pdf.SetFont("bold-font", "", 15); err != nil {
@@ -126,7 +312,7 @@ func EmployeeInfoSection(pdf *gopdf.GoPdf, employeeName, employeeCategory, date
 		return err
 	}
 
-	x, y = 15.25, 5.5
+	x, y = dateX, 5.5
 
 	pdf.SetXY(x, y)
 	pdf.Text("Date:  ")
@@ -144,9 +330,9 @@ func EmployeeInfoSection(pdf *gopdf.GoPdf, employeeName, employeeCategory, date
 
 }
 
-func TableHeaderSection(pdf *gopdf.GoPdf, startY float64) error {
+func TableHeaderSection(pdf *gopdf.GoPdf, startY float64, branding *models.ReportBranding) error {
 
-	pdf.SetStrokeColor(0, 0, 0)
+	pdf.SetStrokeColor(hexColor(branding.AccentColor))
 	pdf.SetLineWidth(0.05)
 	pdf.Line(1, startY, 20, startY)
 	pdf.Line(1, startY+1.5, 20, startY+1.5)
@@ -159,6 +345,9 @@ func TableHeaderSection(pdf *gopdf.GoPdf, startY float64) error {
 		return err
 	}
 
+	pdf.SetTextColor(hexColor(branding.PrimaryColor))
+	defer pdf.SetTextColor(0, 0, 0)
+
 	heading1 := "SN"
 	heading2 := "Date"
 	heading3 := "Work Summary"
@@ -237,17 +426,17 @@ func TextWrapper(pdf *gopdf.GoPdf, text string, maxWidth float64) []string {
 	return lines
 }
 
-func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHistoryForPdf) (float64, string, error) {
+func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHistoryForPdf, branding *models.ReportBranding) (float64, string, error) {
 
 	var isFirstPage bool = true
 
 	var y float64 = startY + 2.25
 
-	var userPerDayWorkHours []string
+	var totalWorkedMinutes int
 
 	for index1, history := range history {
 		if isFirstPage {
-			TableHeaderSection(pdf, startY)
+			TableHeaderSection(pdf, startY, branding)
 			if err := pdf.SetFont("light-font", "", 14); err != nil {
 				return 0.0, "", err
 			}
@@ -256,7 +445,7 @@ func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHi
 
 		if y > 28.7 {
 			pdf.AddPage()
-			TableHeaderSection(pdf, 5)
+			TableHeaderSection(pdf, 5, branding)
 			if err := pdf.SetFont("light-font", "", 14); err != nil {
 				return 0.0, "", err
 			}
@@ -285,15 +474,11 @@ func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHi
 		pdf.SetXY(x, y)
 		pdf.Text(history.Date)
 
-		diffTime, err := CalculateTimeDiff(history.LoginTime, history.LogoutTime)
+		dayWorkHours := history.WorkHours
 
-		if err != nil {
-			return 0.0, "", err
-		}
+		totalWorkedMinutes += history.WorkedMinutes
 
-		userPerDayWorkHours = append(userPerDayWorkHours, diffTime)
-
-		textWidth, err = pdf.MeasureTextWidth(diffTime)
+		textWidth, err = pdf.MeasureTextWidth(dayWorkHours)
 
 		if err != nil {
 			return 0.0, "", err
@@ -302,9 +487,45 @@ func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHi
 		x = (18.5 - (textWidth / 2))
 
 		pdf.SetXY(x, y)
-		pdf.Text(diffTime)
+		pdf.Text(dayWorkHours)
+
+		//partial day leave is printed below the worked hours, it is not added to the total
+		if history.PartialLeaveHours != "" {
+			partialLeaveText := "+" + history.PartialLeaveHours + " leave"
+
+			if err := pdf.SetFont("light-font", "", 10); err != nil {
+				return 0.0, "", err
+			}
+
+			textWidth, err = pdf.MeasureTextWidth(partialLeaveText)
+
+			if err != nil {
+				return 0.0, "", err
+			}
+
+			pdf.SetXY(18.5-(textWidth/2), y+0.55)
+			pdf.Text(partialLeaveText)
+
+			if err := pdf.SetFont("light-font", "", 14); err != nil {
+				return 0.0, "", err
+			}
+		}
+
+		workSummary := history.WorkSummary
+
+		if shiftSummary := dayShiftSummary(history); shiftSummary != "" {
+			workSummary = "{" + shiftSummary + "} " + workSummary
+		}
+
+		if history.PartialLeave != "" {
+			workSummary = "(" + history.PartialLeave + ") " + workSummary
+		}
 
-		workSummaryLines := TextWrapper(pdf, history.WorkSummary, 8)
+		if history.DayNote != "" {
+			workSummary = "[" + history.DayNote + "] " + workSummary
+		}
+
+		workSummaryLines := TextWrapper(pdf, workSummary, 8)
 
 		var lineCounter float64 = 0
 
@@ -321,7 +542,7 @@ func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHi
 
 			if pdf.GetY() > 28.2 {
 				pdf.AddPage()
-				TableHeaderSection(pdf, 5)
+				TableHeaderSection(pdf, 5, branding)
 				if err := pdf.SetFont("light-font", "", 14); err != nil {
 					return 0.0, "", err
 				}
@@ -344,19 +565,37 @@ func TableSection(pdf *gopdf.GoPdf, startY float64, history []*models.UserWorkHi
 
 	}
 
-	totalWorkHours, err := SumTimes(userPerDayWorkHours)
+	return y, FormatMinutes(totalWorkedMinutes), nil
+}
 
-	if err != nil {
-		return 0.0, "", err
+// dayShiftSummary lists the net hours and the late arrival, early departure and overtime of a
+// worked day, days without work have no summary
+func dayShiftSummary(history *models.UserWorkHistoryForPdf) string {
+	if history.WorkedMinutes == 0 && history.LateMinutes == 0 {
+		return ""
 	}
 
-	return y, totalWorkHours, nil
+	parts := []string{"net " + FormatMinutes(history.NetWorkedMinutes)}
+
+	if history.LateMinutes > 0 {
+		parts = append(parts, "late "+FormatMinutes(history.LateMinutes))
+	}
+
+	if history.EarlyDepartureMinutes > 0 {
+		parts = append(parts, "left early "+FormatMinutes(history.EarlyDepartureMinutes))
+	}
+
+	if history.OvertimeMinutes > 0 {
+		parts = append(parts, "overtime "+FormatMinutes(history.OvertimeMinutes))
+	}
+
+	return strings.Join(parts, ", ")
 }
 
-func TotalWorkHoursSection(pdf *gopdf.GoPdf, startY float64, totalHrs string) error {
+func TotalWorkHoursSection(pdf *gopdf.GoPdf, startY float64, totalHrs string, branding *models.ReportBranding) error {
 	if startY >= 27 {
 		pdf.AddPage()
-		TableHeaderSection(pdf, 5)
+		TableHeaderSection(pdf, 5, branding)
 	}
 
 	pdf.Line(1, 27.25, 20, 27.25)
@@ -395,7 +634,20 @@ func TotalWorkHoursSection(pdf *gopdf.GoPdf, startY float64, totalHrs string) er
 	return nil
 }
 
-func GenerateUserReportPdf(data *models.UserReportPdf) (string, error) {
+// WriteUserReportPdf renders the work history report of one user into the writer
+func WriteUserReportPdf(writer io.Writer, data *models.UserReportPdf) error {
+	branding := data.Branding
+
+	if branding == nil {
+		branding = &defaultReportBranding
+	}
+
+	generatedAt := data.GeneratedAt
+
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
 	pdf := gopdf.GoPdf{}
 
 	pdf.Start(
@@ -405,17 +657,17 @@ func GenerateUserReportPdf(data *models.UserReportPdf) (string, error) {
 		},
 	)
 
-	if err := pdf.AddTTFFont("bold-font", "./fonts/Roboto/static/Roboto-Bold.ttf"); err != nil {
-		return "", err
+	if err := pdf.AddTTFFontData("bold-font", assets.BoldFont); err != nil {
+		return err
 	}
 
-	if err := pdf.AddTTFFont("light-font", "./fonts/Roboto/static/Roboto-Regular.ttf"); err != nil {
-		return "", err
+	if err := pdf.AddTTFFontData("light-font", assets.RegularFont); err != nil {
+		return err
 	}
 
 	pdf.AddHeader(
 		func() {
-			if err := HeaderSection(&pdf); err != nil {
+			if err := HeaderSection(&pdf, branding); err != nil {
 				log.Println(err)
 			}
 		},
@@ -423,28 +675,34 @@ func GenerateUserReportPdf(data *models.UserReportPdf) (string, error) {
 
 	pdf.AddPage()
 
-	currentDate := time.Now().Format("02-01-2006")
+	//the date moves left to make room for the qr code
+	dateX := 15.25
 
-	if err := EmployeeInfoSection(&pdf, data.Name, data.Position, currentDate); err != nil {
-		return "", err
+	if data.Verification != nil {
+		dateX = 13.6
+
+		if err := VerificationSection(&pdf, data.Verification); err != nil {
+			return err
+		}
+	}
+
+	if err := EmployeeInfoSection(&pdf, data.Name, data.Position, generatedAt.Format("02-01-2006"), dateX); err != nil {
+		return err
 	}
 
-	lastY, totalWorkHours, err := TableSection(&pdf, 7.2, data.History)
+	lastY, totalWorkHours, err := TableSection(&pdf, 7.2, data.History, branding)
 
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	if err := TotalWorkHoursSection(&pdf, lastY, totalWorkHours); err != nil {
-		return "", err
+	if err := TotalWorkHoursSection(&pdf, lastY, totalWorkHours, branding); err != nil {
+		return err
 	}
 
-	uid := uuid.New().String()
-
-	if err := pdf.WritePdf(fmt.Sprintf("./users_cache/%s.pdf", uid)); err != nil {
-		return "", err
+	if err := FooterSection(&pdf, branding, data.Verification, generatedAt); err != nil {
+		return err
 	}
 
-	return uid, nil
-
+	return pdf.Write(writer)
 }
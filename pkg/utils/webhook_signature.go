@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// SignWebhookPayload returns the X-Webhook-Signature value of a delivery, the hex hmac sha256 of
+// "<unix timestamp>.<body>" with the webhook secret. the timestamp is signed so receivers can
+// reject replayed requests.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRetryDelay is the wait before the next attempt after the given number of failed
+// attempts, doubling from 30 seconds up to 6 hours
+func WebhookRetryDelay(failedAttempts int) time.Duration {
+	delay := 30 * time.Second
+
+	for attempt := 1; attempt < failedAttempts && delay < 6*time.Hour; attempt++ {
+		delay *= 2
+	}
+
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+
+	return delay
+}
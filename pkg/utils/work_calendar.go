@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// NonWorkingDayReason returns the holiday name or "weekly off" when the date is not a working
+// day of the calendar, and an empty string for working days
+func NonWorkingDayReason(date time.Time, calendar *models.WorkCalendar) string {
+	if calendar == nil {
+		return ""
+	}
+
+	if holidayName, ok := calendar.Holidays[date.Format("2006-01-02")]; ok {
+		return holidayName
+	}
+
+	weekNumber := int32((date.Day()-1)/7 + 1)
+
+	for _, rule := range calendar.WeeklyOffs {
+		if rule.Weekday != int(date.Weekday()) {
+			continue
+		}
+
+		if len(rule.WeekNumbers) == 0 {
+			return "weekly off"
+		}
+
+		for _, ruleWeekNumber := range rule.WeekNumbers {
+			if ruleWeekNumber == weekNumber {
+				return "weekly off"
+			}
+		}
+	}
+
+	return ""
+}
+
+// CountWorkingDays returns the working days between two "2006-01-02" dates, both inclusive
+func CountWorkingDays(fromDate string, toDate string, calendar *models.WorkCalendar) (float64, error) {
+	from, err := time.Parse("2006-01-02", fromDate)
+
+	if err != nil {
+		return 0, errors.New("invalid date formates")
+	}
+
+	to, err := time.Parse("2006-01-02", toDate)
+
+	if err != nil {
+		return 0, errors.New("invalid date formates")
+	}
+
+	if to.Before(from) {
+		return 0, errors.New("dates order mismatch")
+	}
+
+	var workingDays float64
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if NonWorkingDayReason(date, calendar) == "" {
+			workingDays++
+		}
+	}
+
+	return workingDays, nil
+}
+
+// AddCalendarDays marks the worked days that fall on a holiday or weekly off and adds an empty
+// row for every holiday between the two dates without work. history must be ordered by date.
+func AddCalendarDays(history []*models.UserWorkHistoryForPdf, calendar *models.WorkCalendar, fromDate string, toDate string) ([]*models.UserWorkHistoryForPdf, error) {
+	from, err := time.Parse("2006-01-02", fromDate)
+
+	if err != nil {
+		return nil, errors.New("invalid date formates")
+	}
+
+	to, err := time.Parse("2006-01-02", toDate)
+
+	if err != nil {
+		return nil, errors.New("invalid date formates")
+	}
+
+	var days []*models.UserWorkHistoryForPdf
+	index := 0
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dateString := date.Format("2006-01-02")
+		reason := NonWorkingDayReason(date, calendar)
+
+		if index < len(history) && history[index].Date == dateString {
+			history[index].DayNote = reason
+			days = append(days, history[index])
+			index++
+			continue
+		}
+
+		if _, isHoliday := calendar.Holidays[dateString]; isHoliday {
+			days = append(days, &models.UserWorkHistoryForPdf{
+				Date:      dateString,
+				WorkHours: "00:00",
+				DayNote:   reason,
+			})
+		}
+	}
+
+	return append(days, history[index:]...), nil
+}
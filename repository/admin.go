@@ -19,16 +19,20 @@ type AdminRepo struct {
 	dbRepo          models.AdminInterface
 	storageRepo     models.AdminStorageInterface
 	emailServieRepo models.AdminEmailServiceInterface
+	tokens          *AuthTokenRepo
+	throttle        *LoginThrottleRepo
 }
 
-func NewAdminRepo(dbRepo models.AdminInterface, storageRepo models.AdminStorageInterface, emailServiceRepo models.AdminEmailServiceInterface) *AdminRepo {
+func NewAdminRepo(dbRepo models.AdminInterface, storageRepo models.AdminStorageInterface, emailServiceRepo models.AdminEmailServiceInterface, tokens *AuthTokenRepo, throttle *LoginThrottleRepo) *AdminRepo {
 	return &AdminRepo{
 		dbRepo:          dbRepo,
 		storageRepo:     storageRepo,
 		emailServieRepo: emailServiceRepo,
+		tokens:          tokens,
+		throttle:        throttle,
 	}
 }
-func (repo *AdminRepo) AdminLogin(ctx echo.Context) (*models.AdminLoginResponse, int32, error) {
+func (repo *AdminRepo) AdminLogin(ctx echo.Context) (*models.AuthTokenResponse, int32, error) {
 	adminLoginRequest := new(models.AdminLoginRequest)
 
 	if err := ctx.Bind(adminLoginRequest); err != nil {
@@ -40,6 +44,10 @@ func (repo *AdminRepo) AdminLogin(ctx echo.Context) (*models.AdminLoginResponse,
 		return nil, 400, errors.New("request body validation error")
 	}
 
+	if statusCode, err := repo.throttle.CheckLoginAttempts(ctx, models.LoginScopeAdminAccount, adminLoginRequest.Email); err != nil {
+		return nil, statusCode, err
+	}
+
 	adminEmailsExists, err := repo.dbRepo.CheckAdminEmailsExists(adminLoginRequest.Email)
 	if err != nil {
 		log.Println("error occurred with database, Error:", err.Error())
@@ -47,6 +55,7 @@ func (repo *AdminRepo) AdminLogin(ctx echo.Context) (*models.AdminLoginResponse,
 	}
 
 	if !adminEmailsExists {
+		repo.throttle.RecordLoginFailure(ctx, models.LoginScopeAdminAccount, adminLoginRequest.Email)
 		return nil, 400, errors.New("admin email does not exist")
 	}
 
@@ -57,20 +66,23 @@ func (repo *AdminRepo) AdminLogin(ctx echo.Context) (*models.AdminLoginResponse,
 	}
 
 	if err := utils.CheckPassword(hashedPassword, adminLoginRequest.Password); err != nil {
+		repo.throttle.RecordLoginFailure(ctx, models.LoginScopeAdminAccount, adminLoginRequest.Email)
 		return nil, 401, errors.New("incorrect password")
 	}
 
-	const firebasePassword = "firebasePassword"
+	repo.throttle.ClearLoginAttempts(models.LoginScopeAdminAccount, adminLoginRequest.Email)
 
-	token, err := utils.GenerateToken(adminId, adminLoginRequest.Email, userName, "admin", firebasePassword)
+	tokens, err := repo.tokens.IssueTokens(&models.TokenSubject{
+		AdminId: adminId,
+		Email:   adminLoginRequest.Email,
+		Name:    userName,
+	})
 	if err != nil {
 		log.Println("error occurred while generating the token, Error:", err.Error())
 		return nil, 500, errors.New("internal server error occurred")
 	}
 
-	return &models.AdminLoginResponse{
-		Token: token,
-	}, 200, nil
+	return tokens, 200, nil
 }
 
 func (repo *AdminRepo) AdminForgotPassword(ctx echo.Context) (int32, error) {
@@ -86,6 +98,10 @@ func (repo *AdminRepo) AdminForgotPassword(ctx echo.Context) (int32, error) {
 		return 400, errors.New("invalid request body format")
 	}
 
+	if statusCode, err := repo.throttle.CheckLoginAttempts(ctx, models.LoginScopeAdminOtp, adminForgotPasswordRequest.Email); err != nil {
+		return statusCode, err
+	}
+
 	emailExists, err := repo.dbRepo.CheckAdminEmailsExists(adminForgotPasswordRequest.Email)
 
 	if err != nil {
@@ -111,6 +127,9 @@ func (repo *AdminRepo) AdminForgotPassword(ctx echo.Context) (int32, error) {
 		return 500, errors.New("internal server error occurred")
 	}
 
+	//a new otp gets its own attempts
+	repo.throttle.ClearLoginAttempts(models.LoginScopeAdminOtp, adminForgotPasswordRequest.Email)
+
 	otpMessage := new(models.AdminOtpEmailFormat)
 
 	otpMessage.To = adminForgotPasswordRequest.Email
@@ -158,6 +177,10 @@ func (repo *AdminRepo) ValidateAdminOtp(ctx echo.Context) (string, int32, error)
 		return "", 400, errors.New("invalid request body")
 	}
 
+	if statusCode, err := repo.throttle.CheckLoginAttempts(ctx, models.LoginScopeAdminOtp, adminOtpValidateRequest.Email); err != nil {
+		return "", statusCode, err
+	}
+
 	emailExists, err := repo.dbRepo.CheckAdminEmailsExists(adminOtpValidateRequest.Email)
 
 	if err != nil {
@@ -169,6 +192,12 @@ func (repo *AdminRepo) ValidateAdminOtp(ctx echo.Context) (string, int32, error)
 		return "", 400, errors.New("email not exists")
 	}
 
+	attemptsLeft, statusCode, err := repo.throttle.ReserveOtpAttempt(models.LoginScopeAdminOtp, adminOtpValidateRequest.Email)
+
+	if err != nil {
+		return "", statusCode, err
+	}
+
 	isOtpValid, err := repo.dbRepo.ValidateAdminOtp(adminOtpValidateRequest.Email, adminOtpValidateRequest.Otp)
 
 	if err != nil {
@@ -177,9 +206,20 @@ func (repo *AdminRepo) ValidateAdminOtp(ctx echo.Context) (string, int32, error)
 	}
 
 	if !isOtpValid {
+		if repo.throttle.RecordOtpFailure(ctx, models.LoginScopeAdminOtp, adminOtpValidateRequest.Email, attemptsLeft) {
+			if err := repo.dbRepo.DeleteAdminOtps(adminOtpValidateRequest.Email); err != nil {
+				log.Println("error occurred with database, Error: ", err.Error())
+				return "", 500, errors.New("internal server error occurred")
+			}
+
+			return "", 401, errors.New("too many invalid otp attempts, please request a new otp")
+		}
+
 		return "", 401, errors.New("invalid otp")
 	}
 
+	repo.throttle.ClearLoginAttempts(models.LoginScopeAdminOtp, adminOtpValidateRequest.Email)
+
 	adminId, adminName, err := repo.dbRepo.GetAdminDetailsForValidOtp(adminOtpValidateRequest.Email)
 
 	if err != nil {
@@ -187,7 +227,11 @@ func (repo *AdminRepo) ValidateAdminOtp(ctx echo.Context) (string, int32, error)
 		return "", 500, errors.New("internal server error occurred")
 	}
 
-	token, err := utils.GenerateToken(adminId, adminOtpValidateRequest.Email, adminName, "admin", "")
+	token, err := repo.tokens.IssueAccessToken(&models.TokenSubject{
+		AdminId: adminId,
+		Email:   adminOtpValidateRequest.Email,
+		Name:    adminName,
+	})
 
 	if err != nil {
 		log.Println("error occurred while generating the token, Error: ", err.Error())
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+// longest date range of the analytics endpoints, both dates inclusive
+const analyticsMaxDays = 3 * 366
+
+type AnalyticsRepo struct {
+	dbRepo models.AnalyticsInterface
+}
+
+func NewAnalyticsRepo(dbRepo models.AnalyticsInterface) *AnalyticsRepo {
+	return &AnalyticsRepo{
+		dbRepo,
+	}
+}
+
+// bindAnalyticsRequest validates the query parameters shared by the analytics endpoints and
+// returns the request with the optional category
+func (repo *AnalyticsRepo) bindAnalyticsRequest(ctx echo.Context) (*models.AnalyticsRequest, *string, int32, error) {
+	analyticsRequest := new(models.AnalyticsRequest)
+
+	if err := ctx.Bind(analyticsRequest); err != nil {
+		return nil, nil, 400, errors.New("missing or invalid query parameters")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
+		log.Println("error occurred while registering the date validation, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if err := validation.Struct(analyticsRequest); err != nil {
+		return nil, nil, 400, errors.New("invalid query parameters")
+	}
+
+	if err := utils.CompareDates(analyticsRequest.StartDate, analyticsRequest.EndDate); err != nil {
+		return nil, nil, 400, errors.New("end date should be greater than start date")
+	}
+
+	startDate, _ := time.Parse("2006-01-02", analyticsRequest.StartDate)
+	endDate, _ := time.Parse("2006-01-02", analyticsRequest.EndDate)
+
+	if endDate.Sub(startDate) >= analyticsMaxDays*24*time.Hour {
+		return nil, nil, 400, fmt.Errorf("date range should not exceed %d days", analyticsMaxDays)
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(analyticsRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, nil, 400, errors.New("admin id not exists")
+	}
+
+	if analyticsRequest.CategoryId != "" {
+		categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(analyticsRequest.CategoryId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return nil, nil, 500, errors.New("internal server error occurred")
+		}
+
+		if !categoryIdExists {
+			return nil, nil, 400, errors.New("category id not exists")
+		}
+	}
+
+	return analyticsRequest, optionalCategoryId(analyticsRequest.CategoryId), 200, nil
+}
+
+// GetAttendanceTrend returns the daily headcount, average login time, average worked hours and
+// late arrivals of the range with the same figures over the whole range
+func (repo *AnalyticsRepo) GetAttendanceTrend(ctx echo.Context) (*models.AttendanceTrendResponse, int32, error) {
+	analyticsRequest, categoryId, statusCode, err := repo.bindAnalyticsRequest(ctx)
+
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	rows, err := repo.dbRepo.GetAttendanceTrend(analyticsRequest.AdminId, categoryId, analyticsRequest.StartDate, analyticsRequest.EndDate)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	response := &models.AttendanceTrendResponse{
+		StartDate: analyticsRequest.StartDate,
+		EndDate:   analyticsRequest.EndDate,
+	}
+
+	var total models.AttendanceTrendRow
+
+	for _, row := range rows {
+		response.Days = append(response.Days, &models.AttendanceTrendDay{
+			Date:               row.Date,
+			Present:            row.Present + row.HalfDay,
+			HalfDay:            row.HalfDay,
+			Absent:             row.Absent,
+			OnLeave:            row.OnLeave,
+			AverageLoginTime:   averageClockTime(row.FirstLoginMinutesSum, row.LoggedIn),
+			AverageWorkedHours: averageDuration(row.WorkedMinutesSum, row.Worked),
+			LateArrivals:       row.LateArrivals,
+			LateArrivalRate:    analyticsRate(float64(row.LateArrivals), float64(row.LoggedIn)),
+		})
+
+		total.Present += row.Present + row.HalfDay
+		total.LoggedIn += row.LoggedIn
+		total.FirstLoginMinutesSum += row.FirstLoginMinutesSum
+		total.Worked += row.Worked
+		total.WorkedMinutesSum += row.WorkedMinutesSum
+		total.LateArrivals += row.LateArrivals
+	}
+
+	if len(rows) > 0 {
+		response.Summary.AveragePresent = float64(total.Present) / float64(len(rows))
+	}
+
+	response.Summary.AverageLoginTime = averageClockTime(total.FirstLoginMinutesSum, total.LoggedIn)
+	response.Summary.AverageWorkedHours = averageDuration(total.WorkedMinutesSum, total.Worked)
+	response.Summary.LateArrivals = total.LateArrivals
+	response.Summary.LateArrivalRate = analyticsRate(float64(total.LateArrivals), float64(total.LoggedIn))
+
+	return response, 200, nil
+}
+
+// GetLeaveUtilization returns the leave days taken per leave type against the annual quota of
+// the category prorated to the range
+func (repo *AnalyticsRepo) GetLeaveUtilization(ctx echo.Context) ([]*models.LeaveUtilization, int32, error) {
+	analyticsRequest, categoryId, statusCode, err := repo.bindAnalyticsRequest(ctx)
+
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	utilization, err := repo.dbRepo.GetLeaveUtilization(analyticsRequest.AdminId, categoryId, analyticsRequest.StartDate, analyticsRequest.EndDate)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if utilization == nil {
+		return nil, 404, errors.New("leave types was empty")
+	}
+
+	startDate, _ := time.Parse("2006-01-02", analyticsRequest.StartDate)
+	endDate, _ := time.Parse("2006-01-02", analyticsRequest.EndDate)
+	rangeDays := endDate.Sub(startDate).Hours()/24 + 1
+
+	for _, leaveType := range utilization {
+		leaveType.ProratedQuota = leaveType.AnnualQuota * float64(leaveType.UsersCount) * rangeDays / 365
+		leaveType.UtilizationRate = analyticsRate(leaveType.LeaveDays, leaveType.ProratedQuota)
+	}
+
+	return utilization, 200, nil
+}
+
+// GetTopAbsentees returns the users with the most absent days of the range, ten unless the
+// limit parameter asks for up to a hundred
+func (repo *AnalyticsRepo) GetTopAbsentees(ctx echo.Context) ([]*models.TopAbsentee, int32, error) {
+	limit := ctx.QueryParam("limit")
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 || limitInt > 100 {
+		limitInt = 10 //default limit
+	}
+
+	analyticsRequest, categoryId, statusCode, err := repo.bindAnalyticsRequest(ctx)
+
+	if err != nil {
+		return nil, statusCode, err
+	}
+
+	absentees, err := repo.dbRepo.GetTopAbsentees(analyticsRequest.AdminId, categoryId, analyticsRequest.StartDate, analyticsRequest.EndDate, limitInt)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if absentees == nil {
+		return nil, 404, errors.New("absentees was empty")
+	}
+
+	return absentees, 200, nil
+}
+
+// averageClockTime formats the average of minutes after midnight as HH:MM, nil without values
+func averageClockTime(minutesSum int64, count int32) *string {
+	if count == 0 {
+		return nil
+	}
+
+	average := int(minutesSum / int64(count))
+	clockTime := fmt.Sprintf("%02d:%02d", average/60, average%60)
+
+	return &clockTime
+}
+
+func averageDuration(minutesSum int64, count int32) *string {
+	if count == 0 {
+		return nil
+	}
+
+	duration := utils.FormatMinutes(int(minutesSum / int64(count)))
+
+	return &duration
+}
+
+// analyticsRate rounds the share to four decimals, nil when there is nothing to share
+func analyticsRate(part float64, whole float64) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+
+	share := float64(int(part/whole*10000+0.5)) / 10000
+
+	return &share
+}
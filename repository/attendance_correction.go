@@ -0,0 +1,487 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+type AttendanceCorrectionRepo struct {
+	dbRepo     models.AttendanceCorrectionInterface
+	punchClock *PunchClock
+}
+
+func NewAttendanceCorrectionRepo(dbRepo models.AttendanceCorrectionInterface, punchClock *PunchClock) *AttendanceCorrectionRepo {
+	return &AttendanceCorrectionRepo{
+		dbRepo,
+		punchClock,
+	}
+}
+
+func (repo *AttendanceCorrectionRepo) parseWallClock(date string, clock string) (*time.Time, error) {
+	if date == "" {
+		return nil, nil
+	}
+
+	parsedTime, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, repo.punchClock.Location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsedTime, nil
+}
+
+// resolveCorrectedTimes merges the requested times into the current session times and checks
+// that the result is a valid session that does not overlap any other session of the user
+func (repo *AttendanceCorrectionRepo) resolveCorrectedTimes(correction *models.AttendanceCorrection, session *models.WorkSessionTimes) (time.Time, *time.Time, int32, error) {
+	var loginAt time.Time
+	var logoutAt *time.Time
+	excludeSessionId := ""
+
+	if session != nil {
+		loginAt = session.LoginAt
+		logoutAt = session.LogoutAt
+		excludeSessionId = session.SessionId
+	}
+
+	if correction.RequestedLoginAt != nil {
+		loginAt = *correction.RequestedLoginAt
+	}
+
+	if correction.RequestedLogoutAt != nil {
+		logoutAt = correction.RequestedLogoutAt
+	}
+
+	if logoutAt != nil && !logoutAt.After(loginAt) {
+		return time.Time{}, nil, 400, errors.New("logout time must be after the login time")
+	}
+
+	now := time.Now()
+
+	if loginAt.After(now) || (logoutAt != nil && logoutAt.After(now)) {
+		return time.Time{}, nil, 400, errors.New("corrected times can not be in the future")
+	}
+
+	overlapExists, err := repo.dbRepo.CheckWorkSessionTimeOverlap(correction.UserId, excludeSessionId, loginAt, logoutAt)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return time.Time{}, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if overlapExists {
+		return time.Time{}, nil, 409, errors.New("corrected session overlaps with another work session")
+	}
+
+	return loginAt, logoutAt, 200, nil
+}
+
+func (repo *AttendanceCorrectionRepo) ApplyAttendanceCorrection(ctx echo.Context) (string, int32, error) {
+	correctionRequest := new(models.AttendanceCorrectionRequest)
+
+	if err := ctx.Bind(correctionRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return "", 500, errors.New("internal server error")
+	}
+
+	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return "", 500, errors.New("internal server error")
+	}
+
+	if err := validation.Struct(correctionRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	hasLogin := correctionRequest.LoginDate != ""
+	hasLogout := correctionRequest.LogoutDate != ""
+
+	switch correctionRequest.CorrectionType {
+	case "missed_login":
+		if !hasLogin || !hasLogout {
+			return "", 400, errors.New("missed login correction requires both login and logout time")
+		}
+	case "missed_logout":
+		if hasLogin || !hasLogout {
+			return "", 400, errors.New("missed logout correction requires only the logout time")
+		}
+	case "wrong_time":
+		if !hasLogin && !hasLogout {
+			return "", 400, errors.New("wrong time correction requires the login or the logout time")
+		}
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(correctionRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return "", 400, errors.New("user id not exists")
+	}
+
+	requestedLoginAt, err := repo.parseWallClock(correctionRequest.LoginDate, correctionRequest.LoginTime)
+
+	if err != nil {
+		return "", 400, errors.New("invalid login date or time")
+	}
+
+	requestedLogoutAt, err := repo.parseWallClock(correctionRequest.LogoutDate, correctionRequest.LogoutTime)
+
+	if err != nil {
+		return "", 400, errors.New("invalid logout date or time")
+	}
+
+	correction := &models.AttendanceCorrection{
+		CorrectionId:      uuid.NewString(),
+		UserId:            correctionRequest.UserId,
+		CorrectionType:    correctionRequest.CorrectionType,
+		RequestedLoginAt:  requestedLoginAt,
+		RequestedLogoutAt: requestedLogoutAt,
+		Work:              correctionRequest.Work,
+		Reason:            correctionRequest.Reason,
+		Status:            "pending",
+	}
+
+	var session *models.WorkSessionTimes
+
+	if correctionRequest.SessionId != "" {
+		sessionExists, err := repo.dbRepo.CheckUserWorkSessionIdExists(correctionRequest.UserId, correctionRequest.SessionId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if !sessionExists {
+			return "", 400, errors.New("work session not exists")
+		}
+
+		session, err = repo.dbRepo.GetWorkSessionTimes(correctionRequest.SessionId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		//an auto closed session counts as a missed logout too
+		if correctionRequest.CorrectionType == "missed_logout" && session.LogoutAt != nil && !session.AutoClosed {
+			return "", 400, errors.New("work session already has a logout")
+		}
+
+		pendingCorrectionExists, err := repo.dbRepo.CheckPendingAttendanceCorrectionExists(correctionRequest.SessionId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if pendingCorrectionExists {
+			return "", 400, errors.New("pending correction already exists for the work session")
+		}
+
+		correction.SessionId = &correctionRequest.SessionId
+	}
+
+	if _, _, statusCode, err := repo.resolveCorrectedTimes(correction, session); err != nil {
+		return "", statusCode, err
+	}
+
+	if err := repo.dbRepo.CreateAttendanceCorrection(correction); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	return correction.CorrectionId, 201, nil
+}
+
+func (repo *AttendanceCorrectionRepo) GetUserAttendanceCorrections(ctx echo.Context) (int32, []*models.AttendanceCorrectionResponse, int32, error) {
+	userId := ctx.Param("userId")
+	status := ctx.QueryParam("status")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	if status != "" && status != "pending" && status != "approved" && status != "rejected" {
+		return 0, nil, 400, errors.New("invalid correction status")
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 0, nil, 400, errors.New("user id not exists")
+	}
+
+	correctionsCount, err := repo.dbRepo.GetUserAttendanceCorrectionsCount(userId, status)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	corrections, err := repo.dbRepo.GetUserAttendanceCorrections(userId, status, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if corrections == nil {
+		return 0, nil, 404, errors.New("attendance corrections was empty")
+	}
+
+	return int32(correctionsCount), corrections, 200, nil
+}
+
+func (repo *AttendanceCorrectionRepo) GetPendingAttendanceCorrections(ctx echo.Context) (int32, []*models.AttendanceCorrectionResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	pendingCorrectionsCount, err := repo.dbRepo.GetPendingAttendanceCorrectionsCount(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	pendingCorrections, err := repo.dbRepo.GetPendingAttendanceCorrections(adminId, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if pendingCorrections == nil {
+		return 0, nil, 404, errors.New("pending attendance corrections was empty")
+	}
+
+	return int32(pendingCorrectionsCount), pendingCorrections, 200, nil
+}
+
+// getCorrectionForReview binds the review request and loads the pending correction, the
+// reviewing admin must be the admin of the user who asked for the correction
+func (repo *AttendanceCorrectionRepo) getCorrectionForReview(ctx echo.Context) (*models.AttendanceCorrection, *models.AttendanceCorrectionReviewRequest, int32, error) {
+	correctionId := ctx.Param("correctionId")
+
+	reviewRequest := new(models.AttendanceCorrectionReviewRequest)
+
+	if err := ctx.Bind(reviewRequest); err != nil {
+		return nil, nil, 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(reviewRequest); err != nil {
+		return nil, nil, 400, errors.New("request body validation error")
+	}
+
+	correctionIdExists, err := repo.dbRepo.CheckAttendanceCorrectionIdExists(correctionId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !correctionIdExists {
+		return nil, nil, 400, errors.New("attendance correction id not exists")
+	}
+
+	correction, err := repo.dbRepo.GetAttendanceCorrection(correctionId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if correction.Status != "pending" {
+		return nil, nil, 400, errors.New("attendance correction was not in pending status")
+	}
+
+	adminId, err := repo.dbRepo.GetAdminIdByUserId(correction.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if adminId != reviewRequest.AdminId {
+		return nil, nil, 403, errors.New("attendance correction belongs to another admin")
+	}
+
+	return correction, reviewRequest, 200, nil
+}
+
+func (repo *AttendanceCorrectionRepo) ApproveAttendanceCorrection(ctx echo.Context) (int32, error) {
+	correction, reviewRequest, statusCode, err := repo.getCorrectionForReview(ctx)
+
+	if err != nil {
+		return statusCode, err
+	}
+
+	approval := &models.AttendanceCorrectionApproval{
+		CorrectionId:  correction.CorrectionId,
+		UserId:        correction.UserId,
+		Work:          correction.Work,
+		Reason:        correction.Reason,
+		ReviewedBy:    reviewRequest.AdminId,
+		ReviewComment: reviewRequest.Comment,
+	}
+
+	var session *models.WorkSessionTimes
+
+	if correction.SessionId == nil {
+		approval.SessionId = uuid.NewString()
+		approval.NewSession = true
+	} else {
+		session, err = repo.dbRepo.GetWorkSessionTimes(*correction.SessionId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		approval.SessionId = session.SessionId
+	}
+
+	//the sessions may have changed since the correction was submitted
+	loginAt, logoutAt, statusCode, err := repo.resolveCorrectedTimes(correction, session)
+
+	if err != nil {
+		return statusCode, err
+	}
+
+	approval.LoginAt = loginAt
+	approval.LogoutAt = logoutAt
+
+	approved, err := repo.dbRepo.ApproveAttendanceCorrection(approval)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !approved {
+		return 400, errors.New("attendance correction was not in pending status")
+	}
+
+	return 200, nil
+}
+
+func (repo *AttendanceCorrectionRepo) RejectAttendanceCorrection(ctx echo.Context) (int32, error) {
+	correction, reviewRequest, statusCode, err := repo.getCorrectionForReview(ctx)
+
+	if err != nil {
+		return statusCode, err
+	}
+
+	rejected, err := repo.dbRepo.RejectAttendanceCorrection(correction.CorrectionId, reviewRequest.AdminId, reviewRequest.Comment)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !rejected {
+		return 400, errors.New("attendance correction was not in pending status")
+	}
+
+	return 200, nil
+}
+
+func (repo *AttendanceCorrectionRepo) GetWorkSessionAuditTrail(ctx echo.Context) ([]*models.WorkSessionAuditResponse, int32, error) {
+	sessionId := ctx.Param("sessionId")
+
+	auditTrail, err := repo.dbRepo.GetWorkSessionAuditTrail(sessionId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if auditTrail == nil {
+		return nil, 404, errors.New("work session audit trail was empty")
+	}
+
+	return auditTrail, 200, nil
+}
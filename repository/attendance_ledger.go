@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+// days before yesterday recomputed on every run, so approved corrections and auto closed
+// sessions of the last week reach the ledger without a manual recompute
+const attendanceLedgerLookbackDays = 7
+
+type AttendanceLedgerRepo struct {
+	dbRepo   models.AttendanceLedgerInterface
+	location *time.Location
+}
+
+func NewAttendanceLedgerRepo(dbRepo models.AttendanceLedgerInterface, location *time.Location) *AttendanceLedgerRepo {
+	return &AttendanceLedgerRepo{
+		dbRepo,
+		location,
+	}
+}
+
+// StartLedger computes the attendance ledger every interval until the process exits
+func (repo *AttendanceLedgerRepo) StartLedger(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		repo.ComputeAttendanceLedger()
+		<-ticker.C
+	}
+}
+
+// ComputeAttendanceLedger computes the finished days of every user up to yesterday in the
+// organization timezone. a user without a ledger is backfilled from the creation date.
+func (repo *AttendanceLedgerRepo) ComputeAttendanceLedger() {
+	yesterday := time.Now().In(repo.location).AddDate(0, 0, -1).Format("2006-01-02")
+	lookbackFrom := time.Now().In(repo.location).AddDate(0, 0, -attendanceLedgerLookbackDays).Format("2006-01-02")
+
+	users, err := repo.dbRepo.GetAttendanceUsers("")
+
+	if err != nil {
+		log.Println("error occurred with database while computing the attendance ledger, Error: ", err.Error())
+		return
+	}
+
+	computedUsers := 0
+
+	for _, user := range users {
+		fromDate := lookbackFrom
+
+		if user.LastComputedDate == nil {
+			fromDate = user.CreatedDate
+		} else if *user.LastComputedDate < fromDate {
+			//the job did not run for longer than the lookback, fill the gap
+			lastComputedDate, err := time.Parse("2006-01-02", *user.LastComputedDate)
+
+			if err != nil {
+				log.Println("error occurred while computing the attendance ledger, Error: ", err.Error())
+				continue
+			}
+
+			fromDate = lastComputedDate.AddDate(0, 0, 1).Format("2006-01-02")
+		}
+
+		if err := repo.computeUserAttendance(user, fromDate, yesterday); err != nil {
+			log.Println("error occurred while computing the attendance ledger of the user "+user.UserId+", Error: ", err.Error())
+			continue
+		}
+
+		computedUsers++
+	}
+
+	if computedUsers > 0 {
+		log.Printf("attendance ledger computed for %d users\n", computedUsers)
+	}
+}
+
+// RecomputeUserAttendance computes the finished days of the user between the two dates again,
+// days from today on are left to the scheduled run
+func (repo *AttendanceLedgerRepo) RecomputeUserAttendance(userId string, fromDate string, toDate string) error {
+	yesterday := time.Now().In(repo.location).AddDate(0, 0, -1).Format("2006-01-02")
+
+	if toDate > yesterday {
+		toDate = yesterday
+	}
+
+	users, err := repo.dbRepo.GetAttendanceUsers(userId)
+
+	if err != nil {
+		return err
+	}
+
+	if len(users) == 0 {
+		return errors.New("user id not exists")
+	}
+
+	return repo.computeUserAttendance(users[0], fromDate, toDate)
+}
+
+func (repo *AttendanceLedgerRepo) computeUserAttendance(user *models.AttendanceUser, fromDate string, toDate string) error {
+	days, err := repo.BuildUserAttendanceDays(user, fromDate, toDate)
+
+	if err != nil {
+		return err
+	}
+
+	if len(days) == 0 {
+		return nil
+	}
+
+	return repo.dbRepo.UpsertAttendanceDays(days)
+}
+
+// BuildUserAttendanceDays derives the attendance days of the user between the two dates from
+// the sessions, granted leaves and work calendar without storing them. days before the user
+// was created are left out.
+func (repo *AttendanceLedgerRepo) BuildUserAttendanceDays(user *models.AttendanceUser, fromDate string, toDate string) ([]*models.AttendanceDay, error) {
+	if fromDate < user.CreatedDate {
+		fromDate = user.CreatedDate
+	}
+
+	if fromDate > toDate {
+		return nil, nil
+	}
+
+	sessionDays, err := repo.dbRepo.GetAttendanceSessionDays(user.UserId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := repo.dbRepo.GetUserGrantedLeavesBetween(user.UserId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	workCalendar, err := repo.dbRepo.GetUserWorkCalendar(user.UserId, fromDate, toDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	shiftMinutes, err := utils.ShiftMinutes(user.ShiftStartTime, user.ShiftEndTime)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.BuildAttendanceDays(
+		user.UserId,
+		fromDate,
+		toDate,
+		sessionDays,
+		leaves,
+		workCalendar,
+		shiftMinutes-user.BreakMinutes,
+	)
+}
+
+func (repo *AttendanceLedgerRepo) GetAttendanceMonth(ctx echo.Context) (*models.AttendanceMonthGridResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+	month := ctx.QueryParam("month")
+
+	if month == "" {
+		month = time.Now().In(repo.location).Format("2006-01")
+	}
+
+	monthStart, err := time.Parse("2006-01", month)
+
+	if err != nil {
+		return nil, 400, errors.New("month parameter must be in YYYY-MM format")
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, 400, errors.New("admin id not exists")
+	}
+
+	users, err := repo.dbRepo.GetAttendanceMonth(adminId, month)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if users == nil {
+		return nil, 404, errors.New("users was empty")
+	}
+
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+
+	for _, user := range users {
+		grid := make([]*models.AttendanceDayResponse, daysInMonth)
+		summary := make(map[string]int)
+
+		for _, day := range user.Days {
+			date, err := time.Parse("2006-01-02", day.Date)
+
+			if err != nil {
+				log.Println("error occurred while building the attendance grid, Error: ", err.Error())
+				return nil, 500, errors.New("internal server error occurred")
+			}
+
+			grid[date.Day()-1] = day
+			summary[day.Status]++
+		}
+
+		user.Days = grid
+		user.Summary = summary
+	}
+
+	return &models.AttendanceMonthGridResponse{
+		Month: month,
+		Users: users,
+	}, 200, nil
+}
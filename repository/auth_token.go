@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+type AuthTokenRepo struct {
+	dbRepo          models.AuthTokenInterface
+	accessTokenTtl  time.Duration
+	refreshTokenTtl time.Duration
+}
+
+func NewAuthTokenRepo(dbRepo models.AuthTokenInterface, accessTokenTtl time.Duration, refreshTokenTtl time.Duration) *AuthTokenRepo {
+	return &AuthTokenRepo{
+		dbRepo,
+		accessTokenTtl,
+		refreshTokenTtl,
+	}
+}
+
+// StartCleanup deletes the expired refresh tokens every interval until the process exits
+func (repo *AuthTokenRepo) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := repo.dbRepo.DeleteExpiredRefreshTokens()
+
+		if err != nil {
+			log.Println("error occurred with database while deleting expired refresh tokens, Error: ", err.Error())
+		} else if deleted > 0 {
+			log.Printf("%d expired refresh tokens deleted\n", deleted)
+		}
+
+		<-ticker.C
+	}
+}
+
+// IssueAccessToken signs an access token for the subject without a refresh token, for flows
+// like the password reset that should not start a session
+func (repo *AuthTokenRepo) IssueAccessToken(subject *models.TokenSubject) (string, error) {
+	role, subjectId := tokenSubjectRole(subject)
+
+	return utils.GenerateAccessToken(role, subjectId, subject.AdminId, subject.Email, subject.Name, repo.accessTokenTtl)
+}
+
+// IssueTokens starts a session for the subject with an access token and the first refresh token
+// of a new family
+func (repo *AuthTokenRepo) IssueTokens(subject *models.TokenSubject) (*models.AuthTokenResponse, error) {
+	refreshToken, tokenHash, err := utils.GenerateRefreshToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tokenId := uuid.NewString()
+
+	if err := repo.dbRepo.CreateRefreshToken(&models.RefreshToken{
+		TokenId:   tokenId,
+		FamilyId:  tokenId,
+		AdminId:   subject.AdminId,
+		UserId:    subject.UserId,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(repo.refreshTokenTtl),
+	}); err != nil {
+		return nil, err
+	}
+
+	return repo.authTokenResponse(subject, refreshToken)
+}
+
+func (repo *AuthTokenRepo) authTokenResponse(subject *models.TokenSubject, refreshToken string) (*models.AuthTokenResponse, error) {
+	accessToken, err := repo.IssueAccessToken(subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthTokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(repo.accessTokenTtl.Seconds()),
+	}, nil
+}
+
+func tokenSubjectRole(subject *models.TokenSubject) (string, string) {
+	if subject.UserId != nil {
+		return models.RoleUser, *subject.UserId
+	}
+
+	return models.RoleAdmin, subject.AdminId
+}
+
+// RefreshTokens trades a refresh token for a new access token and refresh token, the presented
+// token can not be used again
+func (repo *AuthTokenRepo) RefreshTokens(ctx echo.Context) (*models.AuthTokenResponse, int32, error) {
+	refreshTokenRequest := new(models.RefreshTokenRequest)
+
+	if err := ctx.Bind(refreshTokenRequest); err != nil {
+		return nil, 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(refreshTokenRequest); err != nil {
+		return nil, 400, errors.New("request body validation error")
+	}
+
+	refreshToken, tokenHash, err := utils.GenerateRefreshToken()
+
+	if err != nil {
+		log.Println("error occurred while generating the refresh token, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	next := &models.RefreshToken{
+		TokenId:   uuid.NewString(),
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(repo.refreshTokenTtl),
+	}
+
+	subject, result, err := repo.dbRepo.RotateRefreshToken(utils.HashRefreshToken(refreshTokenRequest.RefreshToken), next)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	switch result {
+	case models.RefreshTokenInvalid:
+		return nil, 401, errors.New("invalid refresh token")
+	case models.RefreshTokenExpired:
+		return nil, 401, errors.New("refresh token expired, please login again")
+	case models.RefreshTokenRevoked:
+		return nil, 401, errors.New("refresh token was revoked, please login again")
+	case models.RefreshTokenReused:
+		_, subjectId := tokenSubjectRole(subject)
+		log.Println("refresh token reused, the sessions of the family were revoked, subject: ", subjectId)
+		return nil, 401, errors.New("refresh token was already used, please login again")
+	}
+
+	response, err := repo.authTokenResponse(subject, refreshToken)
+
+	if err != nil {
+		log.Println("error occurred while generating the token, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	return response, 200, nil
+}
+
+// Logout revokes the refresh token and every token rotated from the same login. an unknown token
+// is not reported, the device is logged out either way.
+func (repo *AuthTokenRepo) Logout(ctx echo.Context) (int32, error) {
+	refreshTokenRequest := new(models.RefreshTokenRequest)
+
+	if err := ctx.Bind(refreshTokenRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(refreshTokenRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	if err := repo.dbRepo.RevokeRefreshTokenFamily(utils.HashRefreshToken(refreshTokenRequest.RefreshToken)); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+// RevokeUserSessions logs the user out of every device, the refresh tokens are revoked and the
+// access tokens issued so far are refused
+func (repo *AuthTokenRepo) RevokeUserSessions(ctx echo.Context) (int32, error) {
+	userId := ctx.Param("userId")
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 400, errors.New("user id not exists")
+	}
+
+	if err := repo.dbRepo.RevokeUserSessions(userId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
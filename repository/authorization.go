@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"strings"
+
+	jwt_token "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// context key of the json body fields read by the authorization, the body itself is put back
+// for the handler
+const authorizationBodyKey = "authorization_body"
+
+type AuthorizationRepo struct {
+	dbRepo models.AuthorizationInterface
+}
+
+func NewAuthorizationRepo(dbRepo models.AuthorizationInterface) *AuthorizationRepo {
+	return &AuthorizationRepo{
+		dbRepo,
+	}
+}
+
+// Authenticate reads the claims of the token verified by the jwt middleware and checks that its
+// admin or user still exists and its sessions were not revoked since
+func (repo *AuthorizationRepo) Authenticate(ctx echo.Context) (*models.AuthClaims, int32, error) {
+	token, ok := ctx.Get("user").(*jwt_token.Token)
+
+	if !ok {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	subject, err := token.Claims.GetSubject()
+
+	if err != nil || subject == "" {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	issuedAt, err := token.Claims.GetIssuedAt()
+
+	if err != nil || issuedAt == nil {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	expiresAt, err := token.Claims.GetExpirationTime()
+
+	if err != nil || expiresAt == nil {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	mapClaims, ok := token.Claims.(jwt_token.MapClaims)
+
+	if !ok {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	role, _ := mapClaims["role"].(string)
+
+	if role != models.RoleAdmin && role != models.RoleUser {
+		return nil, 401, errors.New("invalid or missing token")
+	}
+
+	owner, err := repo.dbRepo.GetTokenOwner(role, subject)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if owner == nil {
+		return nil, 401, errors.New("account of the token not exists")
+	}
+
+	//iat has second precision, a token issued within the second of the revocation is refused
+	if owner.SessionsRevokedAt != nil && issuedAt.Unix() <= owner.SessionsRevokedAt.Unix() {
+		return nil, 401, errors.New("token was revoked, please login again")
+	}
+
+	return &models.AuthClaims{
+		Subject:   subject,
+		Role:      role,
+		AdminId:   owner.AdminId,
+		ExpiresAt: expiresAt.Time,
+	}, 200, nil
+}
+
+// Authorize checks every id the rules point at against the caller. a resource must belong to
+// the admin of the caller, and a user scoped resource must also belong to a calling user. ids
+// that are missing or do not exist are left to the handler to report.
+func (repo *AuthorizationRepo) Authorize(ctx echo.Context, claims *models.AuthClaims, rules []models.AuthRule) (int32, error) {
+	for _, rule := range rules {
+		resourceIds, statusCode, err := authorizationValues(ctx, rule)
+
+		if err != nil {
+			return statusCode, err
+		}
+
+		for _, resourceId := range resourceIds {
+			if resourceId == "" {
+				continue
+			}
+
+			owner, err := repo.dbRepo.GetResourceOwner(rule.Resource, resourceId)
+
+			if err != nil {
+				log.Println("error occurred with database, Error: ", err.Error())
+				return 500, errors.New("internal server error occurred")
+			}
+
+			if owner == nil {
+				continue
+			}
+
+			if owner.AdminId != claims.AdminId || (claims.Role == models.RoleUser && owner.UserId != nil && *owner.UserId != claims.Subject) {
+				return 403, errors.New("access denied for the " + strings.ReplaceAll(rule.Resource, "_", " "))
+			}
+		}
+	}
+
+	return 200, nil
+}
+
+// permissions a user may not use on the own leaves and attendance, so that nobody approves or
+// corrects the own requests
+var selfRestrictedPermissions = map[string]bool{
+	models.PermissionLeaveApprove:      true,
+	models.PermissionLeaveAdjust:       true,
+	models.PermissionAttendanceCorrect: true,
+}
+
+// AuthorizePermission checks that the caller holds the permission and that every id the rules
+// point at belongs to the organization of the caller. the admin holds every permission. a user
+// holds the ones granted by the roles of the user, and a category scoped grant only covers
+// requests that name a resource of a managed category and no resource of any other category.
+func (repo *AuthorizationRepo) AuthorizePermission(ctx echo.Context, claims *models.AuthClaims, permission string, rules []models.AuthRule) (int32, error) {
+	var grant *models.PermissionGrant
+
+	if claims.Role == models.RoleUser {
+		if !models.GrantablePermissions[permission] {
+			return 403, errors.New("permission " + permission + " required")
+		}
+
+		var err error
+		grant, err = repo.dbRepo.GetUserPermissionGrant(claims.Subject, permission)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if grant == nil {
+			return 403, errors.New("permission " + permission + " required")
+		}
+	}
+
+	var managedResources int
+
+	for _, rule := range rules {
+		resourceIds, statusCode, err := authorizationValues(ctx, rule)
+
+		if err != nil {
+			return statusCode, err
+		}
+
+		for _, resourceId := range resourceIds {
+			if resourceId == "" {
+				continue
+			}
+
+			owner, err := repo.dbRepo.GetResourceOwner(rule.Resource, resourceId)
+
+			if err != nil {
+				log.Println("error occurred with database, Error: ", err.Error())
+				return 500, errors.New("internal server error occurred")
+			}
+
+			if owner == nil {
+				continue
+			}
+
+			resourceName := strings.ReplaceAll(rule.Resource, "_", " ")
+
+			if owner.AdminId != claims.AdminId {
+				return 403, errors.New("access denied for the " + resourceName)
+			}
+
+			if grant == nil {
+				continue
+			}
+
+			if selfRestrictedPermissions[permission] && owner.UserId != nil && *owner.UserId == claims.Subject {
+				return 403, errors.New("permission " + permission + " can not be used on your own " + resourceName)
+			}
+
+			//the admin id only names the organization, the other ids decide the categories
+			if !grant.CategoryScoped || rule.Resource == models.ResourceAdmin {
+				continue
+			}
+
+			if owner.CategoryId == nil || !slices.Contains(grant.ManagedCategoryIds, *owner.CategoryId) {
+				return 403, errors.New("permission " + permission + " is limited to the categories you manage")
+			}
+
+			managedResources++
+		}
+	}
+
+	if grant != nil && grant.CategoryScoped && managedResources == 0 {
+		return 403, errors.New("permission " + permission + " is limited to the categories you manage")
+	}
+
+	return 200, nil
+}
+
+// authorizationValues returns every value the handler could bind for the rule. the binder
+// matches query, form and json keys case insensitively, so all spellings of the name are checked.
+func authorizationValues(ctx echo.Context, rule models.AuthRule) ([]string, int32, error) {
+	switch rule.Source {
+	case models.SourceParam:
+		return []string{ctx.Param(rule.Name)}, 200, nil
+	case models.SourceQuery:
+		//a body would be bound over the query parameters
+		if ctx.Request().ContentLength != 0 {
+			return nil, 400, errors.New("request body not allowed")
+		}
+
+		return foldedValues(ctx.QueryParams(), rule.Name), 200, nil
+	}
+
+	if !strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		formParams, err := ctx.FormParams()
+
+		if err != nil {
+			return nil, 400, errors.New("invalid form request body")
+		}
+
+		return foldedValues(formParams, rule.Name), 200, nil
+	}
+
+	fields, ok := ctx.Get(authorizationBodyKey).(map[string]interface{})
+
+	if !ok {
+		body, err := io.ReadAll(ctx.Request().Body)
+
+		if err != nil {
+			return nil, 400, errors.New("invalid json request body")
+		}
+
+		ctx.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		//a body that is not an object is rejected by the handler
+		fields = make(map[string]interface{})
+		json.Unmarshal(body, &fields)
+
+		ctx.Set(authorizationBodyKey, fields)
+	}
+
+	var values []string
+
+	for key, value := range fields {
+		if stringValue, ok := value.(string); ok && strings.EqualFold(key, rule.Name) {
+			values = append(values, stringValue)
+		}
+	}
+
+	return values, 200, nil
+}
+
+func foldedValues(params map[string][]string, name string) []string {
+	var values []string
+
+	for key, keyValues := range params {
+		if strings.EqualFold(key, name) {
+			values = append(values, keyValues...)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// fakeAuthorizationDb holds the owners of resource ids and the grants of users by permission
+type fakeAuthorizationDb struct {
+	resourceOwners map[string]*models.ResourceOwner
+	grants         map[string]map[string]*models.PermissionGrant
+}
+
+func (db *fakeAuthorizationDb) GetResourceOwner(resource string, resourceId string) (*models.ResourceOwner, error) {
+	return db.resourceOwners[resourceId], nil
+}
+
+func (db *fakeAuthorizationDb) GetTokenOwner(role string, subjectId string) (*models.TokenOwner, error) {
+	return nil, nil
+}
+
+func (db *fakeAuthorizationDb) GetUserPermissionGrant(userId string, permission string) (*models.PermissionGrant, error) {
+	return db.grants[userId][permission], nil
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func queryRule(name string, resource string) models.AuthRule {
+	return models.AuthRule{Source: models.SourceQuery, Name: name, Resource: resource}
+}
+
+func TestAuthorizePermission(t *testing.T) {
+	db := &fakeAuthorizationDb{
+		resourceOwners: map[string]*models.ResourceOwner{
+			"admin-a":            {AdminId: "admin-a"},
+			"admin-b":            {AdminId: "admin-b"},
+			"managed-user":       {AdminId: "admin-a", UserId: stringPointer("user-managed"), CategoryId: stringPointer("category-managed")},
+			"unmanaged-user":     {AdminId: "admin-a", UserId: stringPointer("user-unmanaged"), CategoryId: stringPointer("category-unmanaged")},
+			"managed-leave":      {AdminId: "admin-a", UserId: stringPointer("user-managed"), CategoryId: stringPointer("category-managed")},
+			"own-leave":          {AdminId: "admin-a", UserId: stringPointer("user-officer"), CategoryId: stringPointer("category-managed")},
+			"own-work-session":   {AdminId: "admin-a", UserId: stringPointer("user-officer"), CategoryId: stringPointer("category-managed")},
+			"organization-site":  {AdminId: "admin-a"},
+			"other-organization": {AdminId: "admin-b", UserId: stringPointer("user-b"), CategoryId: stringPointer("category-b")},
+		},
+		grants: map[string]map[string]*models.PermissionGrant{
+			"user-officer": {
+				models.PermissionUserRead:          {CategoryScoped: false},
+				models.PermissionWorkSiteRead:      {CategoryScoped: false},
+				models.PermissionLeaveApprove:      {CategoryScoped: false},
+				models.PermissionAttendanceCorrect: {CategoryScoped: false},
+				models.PermissionAccountManage:     {CategoryScoped: false},
+				models.PermissionRoleManage:        {CategoryScoped: false},
+			},
+			"user-manager": {
+				models.PermissionUserRead:  {CategoryScoped: true, ManagedCategoryIds: []string{"category-managed"}},
+				models.PermissionLeaveRead: {CategoryScoped: true, ManagedCategoryIds: []string{"category-managed"}},
+			},
+		},
+	}
+
+	repo := NewAuthorizationRepo(db)
+
+	admin := &models.AuthClaims{Subject: "admin-a", Role: models.RoleAdmin, AdminId: "admin-a"}
+	officer := &models.AuthClaims{Subject: "user-officer", Role: models.RoleUser, AdminId: "admin-a"}
+	manager := &models.AuthClaims{Subject: "user-manager", Role: models.RoleUser, AdminId: "admin-a"}
+	plainUser := &models.AuthClaims{Subject: "user-plain", Role: models.RoleUser, AdminId: "admin-a"}
+
+	userRule := queryRule("user_id", models.ResourceUser)
+	adminRule := queryRule("admin_id", models.ResourceAdmin)
+
+	tests := []struct {
+		name       string
+		claims     *models.AuthClaims
+		permission string
+		rules      []models.AuthRule
+		query      map[string]string
+		statusCode int32
+	}{
+		{
+			name:       "admin on own organization",
+			claims:     admin,
+			permission: models.PermissionRoleManage,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "unmanaged-user"},
+			statusCode: 200,
+		},
+		{
+			name:       "admin on another organization",
+			claims:     admin,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "other-organization"},
+			statusCode: 403,
+		},
+		{
+			name:       "user without a grant",
+			claims:     plainUser,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "managed-user"},
+			statusCode: 403,
+		},
+		{
+			name:       "grant of another permission",
+			claims:     manager,
+			permission: models.PermissionUserDelete,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "managed-user"},
+			statusCode: 403,
+		},
+		{
+			name:       "organization wide grant",
+			claims:     officer,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "unmanaged-user"},
+			statusCode: 200,
+		},
+		{
+			name:       "organization wide grant on another organization",
+			claims:     officer,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "other-organization"},
+			statusCode: 403,
+		},
+		{
+			name:       "category scoped grant on a managed category",
+			claims:     manager,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "managed-user"},
+			statusCode: 200,
+		},
+		{
+			name:       "category scoped grant on an unmanaged category",
+			claims:     manager,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "unmanaged-user"},
+			statusCode: 403,
+		},
+		{
+			name:       "category scoped grant naming a managed and an unmanaged category",
+			claims:     manager,
+			permission: models.PermissionLeaveRead,
+			rules:      []models.AuthRule{queryRule("leave_id", models.ResourceLeave), userRule},
+			query:      map[string]string{"leave_id": "managed-leave", "user_id": "unmanaged-user"},
+			statusCode: 403,
+		},
+		{
+			name:       "category scoped grant on an organization wide resource",
+			claims:     manager,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{queryRule("site_id", models.ResourceWorkSite)},
+			query:      map[string]string{"site_id": "organization-site"},
+			statusCode: 403,
+		},
+		{
+			name:       "category scoped grant naming only the organization",
+			claims:     manager,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{adminRule},
+			query:      map[string]string{"admin_id": "admin-a"},
+			statusCode: 403,
+		},
+		{
+			name:       "category scoped grant naming no resource",
+			claims:     manager,
+			permission: models.PermissionUserRead,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": ""},
+			statusCode: 403,
+		},
+		{
+			name:       "leave approval on own leave",
+			claims:     officer,
+			permission: models.PermissionLeaveApprove,
+			rules:      []models.AuthRule{queryRule("leave_id", models.ResourceLeave)},
+			query:      map[string]string{"leave_id": "own-leave"},
+			statusCode: 403,
+		},
+		{
+			name:       "leave approval on the leave of another user",
+			claims:     officer,
+			permission: models.PermissionLeaveApprove,
+			rules:      []models.AuthRule{queryRule("leave_id", models.ResourceLeave)},
+			query:      map[string]string{"leave_id": "managed-leave"},
+			statusCode: 200,
+		},
+		{
+			name:       "attendance correction on own work session",
+			claims:     officer,
+			permission: models.PermissionAttendanceCorrect,
+			rules:      []models.AuthRule{queryRule("session_id", models.ResourceWorkSession)},
+			query:      map[string]string{"session_id": "own-work-session"},
+			statusCode: 403,
+		},
+		{
+			name:       "account management held by a user",
+			claims:     officer,
+			permission: models.PermissionAccountManage,
+			rules:      []models.AuthRule{adminRule},
+			query:      map[string]string{"admin_id": "admin-a"},
+			statusCode: 403,
+		},
+		{
+			name:       "role management held by a user",
+			claims:     officer,
+			permission: models.PermissionRoleManage,
+			rules:      []models.AuthRule{userRule},
+			query:      map[string]string{"user_id": "managed-user"},
+			statusCode: 403,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query := url.Values{}
+
+			for name, value := range test.query {
+				query.Set(name, value)
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+			ctx := echo.New().NewContext(request, httptest.NewRecorder())
+
+			statusCode, err := repo.AuthorizePermission(ctx, test.claims, test.permission, test.rules)
+
+			if statusCode != test.statusCode {
+				t.Errorf("expected status %d, got %d %v", test.statusCode, statusCode, err)
+			}
+
+			if (err != nil) != (test.statusCode != 200) {
+				t.Errorf("expected an error only on refusal, got %v", err)
+			}
+		})
+	}
+}
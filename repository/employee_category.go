@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
 )
 
 type EmployeeCategoryRepo struct {
@@ -94,3 +95,74 @@ func (repo *EmployeeCategoryRepo) DeleteEmployeeCategory(ctx echo.Context) (int3
 	}
 	return 200, nil
 }
+
+func (repo *EmployeeCategoryRepo) UpdateCategoryAttendanceSettings(ctx echo.Context) (int32, error) {
+	settingsRequest := new(models.CategoryAttendanceSettingsRequest)
+
+	if err := ctx.Bind(settingsRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return 500, errors.New("internal server error")
+	}
+
+	if err := validation.Struct(settingsRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	shiftMinutes, err := utils.ShiftMinutes(settingsRequest.ShiftStartTime, settingsRequest.ShiftEndTime)
+
+	if err != nil {
+		return 400, err
+	}
+
+	if int(settingsRequest.BreakMinutes) >= shiftMinutes {
+		return 400, errors.New("break must be shorter than the shift")
+	}
+
+	categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(settingsRequest.CategoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !categoryIdExists {
+		return 400, errors.New("employee category id not exists")
+	}
+
+	if err := repo.dbRepo.UpsertCategoryAttendanceSettings(settingsRequest); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *EmployeeCategoryRepo) GetCategoryAttendanceSettings(ctx echo.Context) (*models.CategoryAttendanceSettingsResponse, int32, error) {
+	categoryId := ctx.Param("categoryId")
+
+	categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(categoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !categoryIdExists {
+		return nil, 400, errors.New("employee category id not exists")
+	}
+
+	settings, err := repo.dbRepo.GetCategoryAttendanceSettings(categoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	return settings, 200, nil
+}
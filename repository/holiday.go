@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+// ics files larger than this are rejected, a holiday calendar is a few kilobytes
+const maxIcsFileSize = 2 << 20
+
+type HolidayRepo struct {
+	dbRepo models.HolidayInterface
+}
+
+func NewHolidayRepo(dbRepo models.HolidayInterface) *HolidayRepo {
+	return &HolidayRepo{
+		dbRepo,
+	}
+}
+
+// checkHolidayScope verifies the admin and the optional category of a holiday or weekly off rule
+func (repo *HolidayRepo) checkHolidayScope(adminId string, categoryId string) (int32, error) {
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return 400, errors.New("admin id not exists")
+	}
+
+	if categoryId == "" {
+		return 200, nil
+	}
+
+	categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(categoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !categoryIdExists {
+		return 400, errors.New("category id not exists")
+	}
+
+	return 200, nil
+}
+
+func optionalCategoryId(categoryId string) *string {
+	if categoryId == "" {
+		return nil
+	}
+
+	return &categoryId
+}
+
+func validateHolidayYear(year string) error {
+	if year == "" {
+		return nil
+	}
+
+	if _, err := strconv.Atoi(year); err != nil || len(year) != 4 {
+		return errors.New("year parameter must be valid year")
+	}
+
+	return nil
+}
+
+func (repo *HolidayRepo) CreateHoliday(ctx echo.Context) (int32, error) {
+	createHolidayRequest := new(models.CreateHolidayRequest)
+
+	if err := ctx.Bind(createHolidayRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return 500, errors.New("internal server error")
+	}
+
+	if err := validation.Struct(createHolidayRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	if statusCode, err := repo.checkHolidayScope(createHolidayRequest.AdminId, createHolidayRequest.CategoryId); err != nil {
+		return statusCode, err
+	}
+
+	categoryId := optionalCategoryId(createHolidayRequest.CategoryId)
+
+	holidayExists, err := repo.dbRepo.CheckHolidayExists(createHolidayRequest.AdminId, categoryId, createHolidayRequest.HolidayDate)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if holidayExists {
+		return 400, errors.New("holiday already exists on the date")
+	}
+
+	holiday := &models.Holiday{
+		HolidayId:   uuid.NewString(),
+		AdminId:     createHolidayRequest.AdminId,
+		CategoryId:  categoryId,
+		HolidayDate: createHolidayRequest.HolidayDate,
+		HolidayName: createHolidayRequest.HolidayName,
+		Source:      "manual",
+	}
+
+	if err := repo.dbRepo.CreateHoliday(holiday); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 201, nil
+}
+
+// ImportHolidays reads the holidays of an uploaded iCalendar file, form fields admin_id, the
+// optional category_id and the file. dates that already have a holiday are skipped.
+func (repo *HolidayRepo) ImportHolidays(ctx echo.Context) (*models.HolidayImportResponse, int32, error) {
+	adminId := ctx.FormValue("admin_id")
+	categoryId := ctx.FormValue("category_id")
+
+	if adminId == "" {
+		return nil, 400, errors.New("admin id is required")
+	}
+
+	if statusCode, err := repo.checkHolidayScope(adminId, categoryId); err != nil {
+		return nil, statusCode, err
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+
+	if err != nil || fileHeader == nil {
+		return nil, 400, errors.New("input file was empty")
+	}
+
+	if fileHeader.Size > maxIcsFileSize {
+		return nil, 400, errors.New("ics file is too large")
+	}
+
+	file, err := fileHeader.Open()
+
+	if err != nil {
+		return nil, 400, errors.New("unable to get the uploaded file")
+	}
+
+	defer file.Close()
+
+	icsHolidays, err := utils.ParseIcsHolidays(file)
+
+	if err != nil {
+		return nil, 400, err
+	}
+
+	if len(icsHolidays) == 0 {
+		return nil, 400, errors.New("no holidays found in the ics file")
+	}
+
+	holidays := make([]*models.Holiday, 0, len(icsHolidays))
+
+	for _, icsHoliday := range icsHolidays {
+		var icsUid *string
+
+		if icsHoliday.Uid != "" {
+			uid := icsHoliday.Uid
+			icsUid = &uid
+		}
+
+		holidays = append(holidays, &models.Holiday{
+			HolidayId:   uuid.NewString(),
+			AdminId:     adminId,
+			CategoryId:  optionalCategoryId(categoryId),
+			HolidayDate: icsHoliday.Date,
+			HolidayName: icsHoliday.Name,
+			Source:      "ics",
+			IcsUid:      icsUid,
+		})
+	}
+
+	imported, err := repo.dbRepo.ImportHolidays(holidays)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	return &models.HolidayImportResponse{
+		Imported: imported,
+		Skipped:  len(holidays) - imported,
+	}, 201, nil
+}
+
+func (repo *HolidayRepo) GetHolidays(ctx echo.Context) ([]*models.HolidayResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+	year := ctx.QueryParam("year")
+
+	if err := validateHolidayYear(year); err != nil {
+		return nil, 400, err
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, 400, errors.New("admin id not exists")
+	}
+
+	holidays, err := repo.dbRepo.GetHolidays(adminId, year)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if holidays == nil {
+		return nil, 404, errors.New("holidays was empty")
+	}
+
+	return holidays, 200, nil
+}
+
+func (repo *HolidayRepo) DeleteHoliday(ctx echo.Context) (int32, error) {
+	holidayId := ctx.Param("holidayId")
+
+	holidayIdExists, err := repo.dbRepo.CheckHolidayIdExists(holidayId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !holidayIdExists {
+		return 400, errors.New("holiday id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteHoliday(holidayId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *HolidayRepo) UpsertWeeklyOffRule(ctx echo.Context) (int32, error) {
+	weeklyOffRuleRequest := new(models.WeeklyOffRuleRequest)
+
+	if err := ctx.Bind(weeklyOffRuleRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(weeklyOffRuleRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	if statusCode, err := repo.checkHolidayScope(weeklyOffRuleRequest.AdminId, weeklyOffRuleRequest.CategoryId); err != nil {
+		return statusCode, err
+	}
+
+	weekNumbers := weeklyOffRuleRequest.WeekNumbers
+
+	if weekNumbers == nil {
+		weekNumbers = []int32{}
+	}
+
+	rule := &models.WeeklyOffRule{
+		RuleId:      uuid.NewString(),
+		AdminId:     weeklyOffRuleRequest.AdminId,
+		CategoryId:  optionalCategoryId(weeklyOffRuleRequest.CategoryId),
+		Weekday:     *weeklyOffRuleRequest.Weekday,
+		WeekNumbers: weekNumbers,
+	}
+
+	if err := repo.dbRepo.UpsertWeeklyOffRule(rule); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *HolidayRepo) GetWeeklyOffRules(ctx echo.Context) ([]*models.WeeklyOffRuleResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, 400, errors.New("admin id not exists")
+	}
+
+	rules, err := repo.dbRepo.GetWeeklyOffRules(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if rules == nil {
+		return nil, 404, errors.New("weekly off rules was empty")
+	}
+
+	return rules, 200, nil
+}
+
+func (repo *HolidayRepo) DeleteWeeklyOffRule(ctx echo.Context) (int32, error) {
+	ruleId := ctx.Param("ruleId")
+
+	ruleIdExists, err := repo.dbRepo.CheckWeeklyOffRuleIdExists(ruleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !ruleIdExists {
+		return 400, errors.New("rule id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteWeeklyOffRule(ruleId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *HolidayRepo) GetUserHolidays(ctx echo.Context) ([]*models.HolidayResponse, int32, error) {
+	userId := ctx.Param("userId")
+	year := ctx.QueryParam("year")
+
+	if err := validateHolidayYear(year); err != nil {
+		return nil, 400, err
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return nil, 400, errors.New("user id not exists")
+	}
+
+	holidays, err := repo.dbRepo.GetUserHolidays(userId, year)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if holidays == nil {
+		return nil, 404, errors.New("user holidays was empty")
+	}
+
+	return holidays, 200, nil
+}
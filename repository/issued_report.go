@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// without signing key the reports are rendered without verification and are not recorded
+type IssuedReportRepo struct {
+	dbRepo        models.IssuedReportInterface
+	signingKey    ed25519.PrivateKey
+	verifyBaseUrl string
+}
+
+func NewIssuedReportRepo(dbRepo models.IssuedReportInterface, signingKey ed25519.PrivateKey, verifyBaseUrl string) *IssuedReportRepo {
+	return &IssuedReportRepo{
+		dbRepo,
+		signingKey,
+		strings.TrimSuffix(verifyBaseUrl, "/"),
+	}
+}
+
+// IssueUserReport renders the report with a new report id and its verify url, then records the
+// sha256 of the pdf bytes signed with the server key. only recorded reports are returned. without
+// signing key the report is rendered without report id and verify qr code.
+func (repo *IssuedReportRepo) IssueUserReport(userId string, startDate string, endDate string, report *models.UserReportPdf) ([]byte, error) {
+	if repo.signingKey == nil {
+		var file bytes.Buffer
+
+		if err := utils.WriteUserReportPdf(&file, report); err != nil {
+			return nil, err
+		}
+
+		return file.Bytes(), nil
+	}
+
+	reportId := uuid.NewString()
+
+	report.Verification = &models.ReportVerification{
+		ReportId:  reportId,
+		VerifyUrl: repo.verifyBaseUrl + "/verify/report/" + reportId,
+	}
+
+	var file bytes.Buffer
+
+	if err := utils.WriteUserReportPdf(&file, report); err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(file.Bytes())
+
+	categoryName, err := repo.dbRepo.GetUserCategoryName(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	issuedReport := &models.IssuedReport{
+		ReportId:     reportId,
+		UserId:       userId,
+		UserName:     report.Name,
+		CategoryName: categoryName,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Sha256:       hex.EncodeToString(digest[:]),
+		IssuedAt:     report.GeneratedAt,
+	}
+
+	if issuedReport.IssuedAt.IsZero() {
+		issuedReport.IssuedAt = time.Now()
+	}
+
+	signature := ed25519.Sign(repo.signingKey, []byte(issuedReportPayload(issuedReport)))
+	issuedReport.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	if err := repo.dbRepo.CreateIssuedReport(issuedReport); err != nil {
+		return nil, err
+	}
+
+	return file.Bytes(), nil
+}
+
+// VerifyReport returns who the report was issued for and the date range it covers. when the
+// sha256 query parameter is given it is compared with the hash of the issued pdf.
+func (repo *IssuedReportRepo) VerifyReport(ctx echo.Context) (*models.ReportVerificationResponse, int32, error) {
+	reportId := ctx.Param("reportId")
+	hash := strings.ToLower(ctx.QueryParam("sha256"))
+
+	if repo.signingKey == nil {
+		return nil, 404, errors.New("report verification is not enabled on this server")
+	}
+
+	if hash != "" && !sha256HexPattern.MatchString(hash) {
+		return nil, 400, errors.New("sha256 parameter must be a hex encoded sha256 hash")
+	}
+
+	issuedReport, err := repo.dbRepo.GetIssuedReport(reportId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if issuedReport == nil {
+		return nil, 404, errors.New("report was not issued by this server")
+	}
+
+	payload := issuedReportPayload(issuedReport)
+	signature, err := base64.StdEncoding.DecodeString(issuedReport.Signature)
+
+	//a record whose signature does not match was changed after the report was issued
+	if err != nil || !ed25519.Verify(repo.signingKey.Public().(ed25519.PublicKey), []byte(payload), signature) {
+		log.Println("signature mismatch on the issued report " + issuedReport.ReportId)
+		return nil, 409, errors.New("report record failed the signature check")
+	}
+
+	response := &models.ReportVerificationResponse{
+		ReportId:      issuedReport.ReportId,
+		UserName:      issuedReport.UserName,
+		CategoryName:  issuedReport.CategoryName,
+		StartDate:     issuedReport.StartDate,
+		EndDate:       issuedReport.EndDate,
+		IssuedAt:      issuedReport.IssuedAt,
+		Sha256:        issuedReport.Sha256,
+		SignedPayload: payload,
+		Signature:     issuedReport.Signature,
+		PublicKey:     base64.StdEncoding.EncodeToString(repo.signingKey.Public().(ed25519.PublicKey)),
+	}
+
+	if hash != "" {
+		hashMatches := hash == issuedReport.Sha256
+		response.HashMatches = &hashMatches
+	}
+
+	return response, 200, nil
+}
+
+func issuedReportPayload(report *models.IssuedReport) string {
+	return utils.ReportSignedPayload(
+		report.ReportId,
+		report.UserName,
+		report.CategoryName,
+		report.StartDate,
+		report.EndDate,
+		report.Sha256,
+	)
+}
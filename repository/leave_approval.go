@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+type LeaveApprovalRepo struct {
+	dbRepo           models.LeaveApprovalInterface
+	attendanceLedger *AttendanceLedgerRepo
+	presence         *PresenceRepo
+	webhooks         *WebhookRepo
+}
+
+func NewLeaveApprovalRepo(dbRepo models.LeaveApprovalInterface, attendanceLedger *AttendanceLedgerRepo, presence *PresenceRepo, webhooks *WebhookRepo) *LeaveApprovalRepo {
+	return &LeaveApprovalRepo{
+		dbRepo,
+		attendanceLedger,
+		presence,
+		webhooks,
+	}
+}
+
+// UpdateReportingManager sets the reporting manager of a user, an empty manager id removes it.
+// the manager must be a user of the same organization that does not report to the user.
+func (repo *LeaveApprovalRepo) UpdateReportingManager(ctx echo.Context) (int32, error) {
+	updateRequest := new(models.UpdateReportingManagerRequest)
+
+	if err := ctx.Bind(updateRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(updateRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(updateRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 400, errors.New("user id not exists")
+	}
+
+	var managerId *string
+
+	if updateRequest.ManagerId != "" {
+		if updateRequest.ManagerId == updateRequest.UserId {
+			return 400, errors.New("user can not be the own reporting manager")
+		}
+
+		managerIdExists, err := repo.dbRepo.CheckUserIdExists(updateRequest.ManagerId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if !managerIdExists {
+			return 400, errors.New("manager id not exists")
+		}
+
+		userAdminId, err := repo.dbRepo.GetAdminIdByUserId(updateRequest.UserId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		managerAdminId, err := repo.dbRepo.GetAdminIdByUserId(updateRequest.ManagerId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if userAdminId != managerAdminId {
+			return 400, errors.New("manager not belongs to the organization of the user")
+		}
+
+		cycle, err := repo.dbRepo.CheckReportingLineCycle(updateRequest.UserId, updateRequest.ManagerId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if cycle {
+			return 400, errors.New("manager reports to the user, reporting lines can not form a cycle")
+		}
+
+		managerId = &updateRequest.ManagerId
+	}
+
+	if err := repo.dbRepo.SetUserReportingManager(updateRequest.UserId, managerId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+// UpsertLeaveApprovalChain sets the approval steps of the leaves applied from now on, leaves
+// already applied keep their steps
+func (repo *LeaveApprovalRepo) UpsertLeaveApprovalChain(ctx echo.Context) (string, int32, error) {
+	upsertRequest := new(models.UpsertLeaveApprovalChainRequest)
+
+	if err := ctx.Bind(upsertRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(upsertRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	var categoryId *string
+
+	if upsertRequest.CategoryId != "" {
+		categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(upsertRequest.CategoryId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if !categoryIdExists {
+			return "", 400, errors.New("category id not exists")
+		}
+
+		categoryId = &upsertRequest.CategoryId
+	}
+
+	chainId, err := repo.dbRepo.UpsertLeaveApprovalChain(&models.LeaveApprovalChain{
+		ChainId:    uuid.NewString(),
+		AdminId:    upsertRequest.AdminId,
+		CategoryId: categoryId,
+		Steps:      upsertRequest.Steps,
+	})
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	return chainId, 200, nil
+}
+
+func (repo *LeaveApprovalRepo) GetLeaveApprovalChains(ctx echo.Context) ([]*models.LeaveApprovalChainResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	chains, err := repo.dbRepo.GetLeaveApprovalChains(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if chains == nil {
+		return nil, 404, errors.New("leave approval chains was empty")
+	}
+
+	return chains, 200, nil
+}
+
+func (repo *LeaveApprovalRepo) DeleteLeaveApprovalChain(ctx echo.Context) (int32, error) {
+	chainId := ctx.Param("chainId")
+
+	chainIdExists, err := repo.dbRepo.CheckLeaveApprovalChainIdExists(chainId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !chainIdExists {
+		return 400, errors.New("chain id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteLeaveApprovalChain(chainId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *LeaveApprovalRepo) GetLeaveApprovalSteps(ctx echo.Context) ([]*models.LeaveApprovalStepResponse, int32, error) {
+	leaveId := ctx.Param("leaveId")
+
+	leaveIdExists, err := repo.dbRepo.CheckLeaveIdExists(leaveId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !leaveIdExists {
+		return nil, 400, errors.New("leave id not exists")
+	}
+
+	steps, err := repo.dbRepo.GetLeaveApprovalSteps(leaveId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if steps == nil {
+		return nil, 404, errors.New("leave approval steps was empty")
+	}
+
+	return steps, 200, nil
+}
+
+// GetManagerPendingLeaves returns the pending leaves waiting on the user as reporting manager
+func (repo *LeaveApprovalRepo) GetManagerPendingLeaves(ctx echo.Context) (int32, []*models.UserPendingLeaveResponse, int32, error) {
+	managerId := ctx.Param("userId")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	pendingLeavesCount, err := repo.dbRepo.GetManagerPendingLeavesCount(managerId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	pendingLeaves, err := repo.dbRepo.GetManagerPendingLeaves(managerId, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if pendingLeaves == nil {
+		return 0, nil, 404, errors.New("pending approvals was empty")
+	}
+
+	return int32(pendingLeavesCount), pendingLeaves, 200, nil
+}
+
+// GrantUserLeave approves the admin step the leave is waiting on, the leave is granted with the
+// approval of its last step
+func (repo *LeaveApprovalRepo) GrantUserLeave(ctx echo.Context) (string, int32, error) {
+	claims, ok := ctx.Get(models.AuthClaimsKey).(*models.AuthClaims)
+
+	if !ok {
+		return "", 401, errors.New("invalid or missing token")
+	}
+
+	return repo.decideLeave(ctx, ctx.Param("leaveId"), "", claims.Subject, claims.Role, true)
+}
+
+// RejectUserLeave rejects the admin step the leave is waiting on, which cancels the leave
+func (repo *LeaveApprovalRepo) RejectUserLeave(ctx echo.Context) (int32, error) {
+	claims, ok := ctx.Get(models.AuthClaimsKey).(*models.AuthClaims)
+
+	if !ok {
+		return 401, errors.New("invalid or missing token")
+	}
+
+	_, statusCode, err := repo.decideLeave(ctx, ctx.Param("leaveId"), "", claims.Subject, claims.Role, false)
+
+	return statusCode, err
+}
+
+// ApproveManagedLeave approves the leave step waiting on the user as reporting manager
+func (repo *LeaveApprovalRepo) ApproveManagedLeave(ctx echo.Context) (string, int32, error) {
+	managerId := ctx.Param("userId")
+
+	return repo.decideLeave(ctx, ctx.Param("leaveId"), managerId, managerId, models.RoleUser, true)
+}
+
+// RejectManagedLeave rejects the leave step waiting on the user as reporting manager, which
+// cancels the leave
+func (repo *LeaveApprovalRepo) RejectManagedLeave(ctx echo.Context) (int32, error) {
+	managerId := ctx.Param("userId")
+
+	_, statusCode, err := repo.decideLeave(ctx, ctx.Param("leaveId"), managerId, managerId, models.RoleUser, false)
+
+	return statusCode, err
+}
+
+// decideLeave records the decision on the step the leave is waiting on. manager id is the
+// reporting manager deciding, empty for a decision on an admin step.
+func (repo *LeaveApprovalRepo) decideLeave(ctx echo.Context, leaveId string, managerId string, decidedBy string, decidedByType string, approved bool) (string, int32, error) {
+	decisionRequest := new(models.LeaveDecisionRequest)
+
+	if err := ctx.Bind(decisionRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(decisionRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	leaveIdExists, err := repo.dbRepo.CheckLeaveIdExists(leaveId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if !leaveIdExists {
+		return "", 400, errors.New("leave id not exists")
+	}
+
+	userLeave, err := repo.dbRepo.GetUserLeave(leaveId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	step, isLastStep, err := repo.dbRepo.GetCurrentLeaveApprovalStep(leaveId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if step == nil {
+		return "", 400, errors.New("leave was not in pending status")
+	}
+
+	//a reporting manager step without manager waits on the admin
+	waitsOnManager := step.ApproverType == models.ApproverReportingManager && step.ApproverUserId != nil
+
+	if managerId == "" && waitsOnManager {
+		return "", 409, errors.New("leave is waiting on the reporting manager")
+	}
+
+	if managerId != "" && (!waitsOnManager || *step.ApproverUserId != managerId) {
+		return "", 403, errors.New("leave is not waiting on your approval")
+	}
+
+	if approved && isLastStep && userLeave.LeaveTypeId != nil {
+		leaveBalance, err := repo.dbRepo.GetUserLeaveBalance(userLeave.UserId, *userLeave.LeaveTypeId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if leaveBalance != nil && !leaveBalance.AllowNegativeBalance && userLeave.LeaveDays > leaveBalance.Balance {
+			return "", 400, fmt.Errorf("insufficient leave balance, %g days left", leaveBalance.Balance)
+		}
+	}
+
+	decision := &models.LeaveDecision{
+		Approved:      approved,
+		DecidedBy:     decidedBy,
+		DecidedByType: decidedByType,
+	}
+
+	if decisionRequest.Comment != "" {
+		decision.Comment = &decisionRequest.Comment
+	}
+
+	result, err := repo.dbRepo.DecideLeaveApprovalStep(step, decision)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	switch result {
+	case models.LeaveDecisionConflict:
+		return "", 409, errors.New("leave could not be decided, the leave status was changed")
+	case models.LeaveDecisionInsufficientBalance:
+		//the balance check above raced with another grant of the same leave type
+		return "", 409, errors.New("insufficient leave balance, the balance was changed")
+	case models.LeaveDecisionGranted:
+		if err := repo.attendanceLedger.RecomputeUserAttendance(userLeave.UserId, userLeave.LeaveFrom, userLeave.LeaveTo); err != nil {
+			log.Println("error occurred while recomputing the attendance ledger, Error: ", err.Error())
+		}
+
+		repo.presence.Publish(&models.PresenceEvent{
+			EventType: "leave_granted",
+			UserId:    userLeave.UserId,
+			LeaveId:   userLeave.LeaveId,
+			LeaveFrom: userLeave.LeaveFrom,
+			LeaveTo:   userLeave.LeaveTo,
+		})
+
+		repo.webhooks.Enqueue(userLeave.UserId, "leave.granted", webhookLeaveEvent(userLeave))
+	case models.LeaveDecisionRejected:
+		leaveEvent := webhookLeaveEvent(userLeave)
+		leaveEvent.CancelledBy = step.ApproverType
+
+		repo.webhooks.Enqueue(userLeave.UserId, "leave.cancelled", leaveEvent)
+	}
+
+	return result, 200, nil
+}
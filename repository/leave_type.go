@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+type LeaveTypeRepo struct {
+	dbRepo   models.LeaveTypeInterface
+	location *time.Location
+}
+
+func NewLeaveTypeRepo(dbRepo models.LeaveTypeInterface, location *time.Location) *LeaveTypeRepo {
+	return &LeaveTypeRepo{
+		dbRepo,
+		location,
+	}
+}
+
+// StartAccrual credits the leave quotas every interval until the process exits
+func (repo *LeaveTypeRepo) StartAccrual(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		repo.AccrueLeaveBalances()
+		<-ticker.C
+	}
+}
+
+// AccrueLeaveBalances credits the quota of the current year, or month for monthly leave types,
+// the periods follow the organization timezone
+func (repo *LeaveTypeRepo) AccrueLeaveBalances() {
+	now := time.Now().In(repo.location)
+
+	accrued, err := repo.dbRepo.AccrueLeaveBalances(now.Format("2006"), now.Format("2006-01"))
+
+	if err != nil {
+		log.Println("error occurred with database while accruing leave balances, Error: ", err.Error())
+		return
+	}
+
+	if accrued > 0 {
+		log.Printf("leave balances accrued for %d users\n", accrued)
+	}
+}
+
+func (repo *LeaveTypeRepo) CreateLeaveType(ctx echo.Context) (int32, error) {
+	createLeaveTypeRequest := new(models.CreateLeaveTypeRequest)
+
+	if err := ctx.Bind(createLeaveTypeRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(createLeaveTypeRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(createLeaveTypeRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return 400, errors.New("admin id not exists")
+	}
+
+	categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(createLeaveTypeRequest.CategoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !categoryIdExists {
+		return 400, errors.New("category id not exists")
+	}
+
+	leaveName := strings.ToLower(createLeaveTypeRequest.LeaveName)
+
+	leaveTypeExists, err := repo.dbRepo.CheckLeaveTypeExists(createLeaveTypeRequest.CategoryId, leaveName)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if leaveTypeExists {
+		return 400, errors.New("leave type already exists")
+	}
+
+	accrualFrequency := createLeaveTypeRequest.AccrualFrequency
+
+	if accrualFrequency == "" {
+		accrualFrequency = "yearly"
+	}
+
+	leaveType := &models.LeaveType{
+		LeaveTypeId:          uuid.NewString(),
+		AdminId:              createLeaveTypeRequest.AdminId,
+		CategoryId:           createLeaveTypeRequest.CategoryId,
+		LeaveName:            leaveName,
+		AnnualQuota:          createLeaveTypeRequest.AnnualQuota,
+		AccrualFrequency:     accrualFrequency,
+		CarryForwardLimit:    createLeaveTypeRequest.CarryForwardLimit,
+		AllowNegativeBalance: createLeaveTypeRequest.AllowNegativeBalance,
+	}
+
+	if err := repo.dbRepo.CreateLeaveType(leaveType); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	//credit the current period right away instead of waiting for the next tick
+	repo.AccrueLeaveBalances()
+
+	return 201, nil
+}
+
+func (repo *LeaveTypeRepo) GetLeaveTypes(ctx echo.Context) ([]*models.LeaveTypeResponse, int32, error) {
+	categoryId := ctx.Param("categoryId")
+
+	categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(categoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !categoryIdExists {
+		return nil, 400, errors.New("category id not exists")
+	}
+
+	leaveTypes, err := repo.dbRepo.GetLeaveTypes(categoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	return leaveTypes, 200, nil
+}
+
+func (repo *LeaveTypeRepo) DeleteLeaveType(ctx echo.Context) (int32, error) {
+	leaveTypeId := ctx.Param("leaveTypeId")
+
+	leaveTypeIdExists, err := repo.dbRepo.CheckLeaveTypeIdExists(leaveTypeId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !leaveTypeIdExists {
+		return 400, errors.New("leave type id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteLeaveType(leaveTypeId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *LeaveTypeRepo) AdjustLeaveBalance(ctx echo.Context) (int32, error) {
+	adjustRequest := new(models.LeaveBalanceAdjustRequest)
+
+	if err := ctx.Bind(adjustRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(adjustRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	leaveBalance, err := repo.dbRepo.GetUserLeaveBalance(adjustRequest.UserId, adjustRequest.LeaveTypeId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if leaveBalance == nil {
+		return 400, errors.New("leave type not exists for the user category")
+	}
+
+	entry := &models.LeaveLedgerEntry{
+		EntryId:     uuid.NewString(),
+		UserId:      adjustRequest.UserId,
+		LeaveTypeId: adjustRequest.LeaveTypeId,
+		EntryType:   "adjustment",
+		Days:        adjustRequest.Days,
+		Note:        adjustRequest.Note,
+	}
+
+	if err := repo.dbRepo.AdjustLeaveBalance(entry); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *LeaveTypeRepo) GetUserLeaveBalances(ctx echo.Context) ([]*models.LeaveBalanceResponse, int32, error) {
+	userId := ctx.Param("userId")
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return nil, 400, errors.New("user id not exists")
+	}
+
+	leaveBalances, err := repo.dbRepo.GetUserLeaveBalances(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if leaveBalances == nil {
+		return nil, 404, errors.New("user leave balances was empty")
+	}
+
+	return leaveBalances, 200, nil
+}
+
+func (repo *LeaveTypeRepo) GetUserLeaveLedger(ctx echo.Context) (int32, []*models.LeaveLedgerResponse, int32, error) {
+	userId := ctx.Param("userId")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 0, nil, 400, errors.New("user id not exists")
+	}
+
+	ledgerCount, err := repo.dbRepo.GetUserLeaveLedgerCount(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	ledgerEntries, err := repo.dbRepo.GetUserLeaveLedger(userId, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if ledgerEntries == nil {
+		return 0, nil, 404, errors.New("user leave ledger was empty")
+	}
+
+	return int32(ledgerCount), ledgerEntries, 200, nil
+}
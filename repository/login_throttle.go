@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// failure policy of a counter scope. the first free failures go through, every further failure
+// locks the key for double the previous delay, starting at base delay, up to max delay which is
+// the lockout. failures older than reset after are forgotten.
+type loginThrottlePolicy struct {
+	freeFailures int32
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	resetAfter   time.Duration
+}
+
+var accountLoginPolicy = loginThrottlePolicy{
+	freeFailures: 3,
+	baseDelay:    30 * time.Second,
+	maxDelay:     30 * time.Minute,
+	resetAfter:   24 * time.Hour,
+}
+
+// a client ip is shared by the users behind the same network, so it gets more attempts
+var ipLoginPolicy = loginThrottlePolicy{
+	freeFailures: 20,
+	baseDelay:    time.Minute,
+	maxDelay:     time.Hour,
+	resetAfter:   time.Hour,
+}
+
+// invalid otps after which every otp of the email is invalidated
+const maxOtpFailures = 5
+
+const otpFailuresResetAfter = time.Hour
+
+// counters are kept for the longest reset window of the policies
+const loginAttemptRetention = 24 * time.Hour
+
+type LoginThrottleRepo struct {
+	store            models.LoginAttemptStoreInterface
+	dbRepo           models.LoginThrottleInterface
+	emailServiceRepo models.LoginAlertEmailServiceInterface
+}
+
+func NewLoginThrottleRepo(store models.LoginAttemptStoreInterface, dbRepo models.LoginThrottleInterface, emailServiceRepo models.LoginAlertEmailServiceInterface) *LoginThrottleRepo {
+	return &LoginThrottleRepo{
+		store,
+		dbRepo,
+		emailServiceRepo,
+	}
+}
+
+// StartCleanup removes the stale counters every interval until the process exits
+func (repo *LoginThrottleRepo) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := repo.store.DeleteStaleLoginAttempts(time.Now().Add(-loginAttemptRetention)); err != nil {
+			log.Println("error occurred while deleting stale login attempts, Error: ", err.Error())
+		}
+
+		<-ticker.C
+	}
+}
+
+// CheckLoginAttempts refuses the attempt with 429 while the key or the client ip is locked, the
+// Retry-After header tells when to try again
+func (repo *LoginThrottleRepo) CheckLoginAttempts(ctx echo.Context, scope string, key string) (int32, error) {
+	keyLockedUntil, err := repo.lockedUntil(scope, key)
+
+	if err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	ipLockedUntil, err := repo.lockedUntil(models.LoginScopeIp, ctx.RealIP())
+
+	if err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if ipLockedUntil.After(keyLockedUntil) {
+		keyLockedUntil = ipLockedUntil
+	}
+
+	retryAfter := time.Until(keyLockedUntil)
+
+	if retryAfter <= 0 {
+		return 200, nil
+	}
+
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+
+	ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds))
+
+	return 429, fmt.Errorf("too many failed attempts, try again in %d seconds", retryAfterSeconds)
+}
+
+// RecordLoginFailure counts a failed login against the account and the client ip, the admins
+// are alerted when the account gets locked out
+func (repo *LoginThrottleRepo) RecordLoginFailure(ctx echo.Context, scope string, email string) {
+	clientIp := ctx.RealIP()
+
+	accountLockedUntil, lockedOut, err := repo.recordFailure(scope, email, accountLoginPolicy)
+
+	if err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+	} else if lockedOut {
+		repo.sendLoginAlert(scope, email, clientIp, "account_locked", &accountLockedUntil)
+	}
+
+	repo.recordIpFailure(clientIp)
+}
+
+// ReserveOtpAttempt counts the otp attempt against the email before the otp is compared, so
+// concurrent guesses can not go past the cap. the attempts left after this one are returned, the
+// attempt is refused once the email used up its attempts on the current otps.
+func (repo *LoginThrottleRepo) ReserveOtpAttempt(scope string, email string) (int32, int32, error) {
+	attempt, err := repo.store.RecordLoginFailure(scope, email, otpFailuresResetAfter)
+
+	if err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+		return 0, 500, errors.New("internal server error occurred")
+	}
+
+	if attempt.Failures > maxOtpFailures {
+		return 0, 429, errors.New("too many invalid otp attempts, please request a new otp")
+	}
+
+	return maxOtpFailures - attempt.Failures, 200, nil
+}
+
+// RecordOtpFailure counts an invalid otp against the client ip, the attempt was already counted
+// against the email by ReserveOtpAttempt. true is returned when it was the last attempt of the
+// email, its otps must then be invalidated.
+func (repo *LoginThrottleRepo) RecordOtpFailure(ctx echo.Context, scope string, email string, attemptsLeft int32) bool {
+	clientIp := ctx.RealIP()
+
+	repo.recordIpFailure(clientIp)
+
+	if attemptsLeft > 0 {
+		return false
+	}
+
+	repo.sendLoginAlert(scope, email, clientIp, "otp_invalidated", nil)
+
+	return true
+}
+
+// ClearLoginAttempts forgets the failures of the key after a successful attempt, the client ip
+// keeps its failures
+func (repo *LoginThrottleRepo) ClearLoginAttempts(scope string, key string) {
+	if err := repo.store.ClearLoginAttempt(scope, key); err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+	}
+}
+
+func (repo *LoginThrottleRepo) lockedUntil(scope string, key string) (time.Time, error) {
+	attempt, err := repo.store.GetLoginAttempt(scope, key)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if attempt == nil || attempt.LockedUntil == nil {
+		return time.Time{}, nil
+	}
+
+	return *attempt.LockedUntil, nil
+}
+
+func (repo *LoginThrottleRepo) recordIpFailure(clientIp string) {
+	_, lockedOut, err := repo.recordFailure(models.LoginScopeIp, clientIp, ipLoginPolicy)
+
+	if err != nil {
+		log.Println("error occurred with login attempt store, Error: ", err.Error())
+		return
+	}
+
+	if lockedOut {
+		log.Println("client ip locked out after repeated failed attempts, ip: ", clientIp)
+	}
+}
+
+// recordFailure counts the failure and locks the key for the backoff delay of the failure count,
+// locked out is true when the delay reached the lockout of the policy
+func (repo *LoginThrottleRepo) recordFailure(scope string, key string, policy loginThrottlePolicy) (time.Time, bool, error) {
+	attempt, err := repo.store.RecordLoginFailure(scope, key, policy.resetAfter)
+
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	delay := policy.backoffDelay(attempt.Failures)
+
+	if delay == 0 {
+		return time.Time{}, false, nil
+	}
+
+	lockedUntil := time.Now().Add(delay)
+
+	if err := repo.store.LockLoginAttempt(scope, key, lockedUntil); err != nil {
+		return time.Time{}, false, err
+	}
+
+	return lockedUntil, delay == policy.maxDelay, nil
+}
+
+func (policy loginThrottlePolicy) backoffDelay(failures int32) time.Duration {
+	if failures <= policy.freeFailures {
+		return 0
+	}
+
+	doublings := failures - policy.freeFailures - 1
+
+	//stop doubling before the shift overflows
+	if doublings >= 30 {
+		return policy.maxDelay
+	}
+
+	delay := policy.baseDelay << doublings
+
+	if delay <= 0 || delay > policy.maxDelay {
+		return policy.maxDelay
+	}
+
+	return delay
+}
+
+func (repo *LoginThrottleRepo) sendLoginAlert(scope string, email string, clientIp string, reason string, lockedUntil *time.Time) {
+	recipient, err := repo.dbRepo.GetLoginAlertRecipient(scope, email)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return
+	}
+
+	//attempts against an unknown email have nobody to alert
+	if recipient == "" {
+		return
+	}
+
+	data := map[string]string{
+		"email":     email,
+		"client_ip": clientIp,
+		"reason":    reason,
+	}
+
+	if lockedUntil != nil {
+		data["locked_until"] = lockedUntil.UTC().Format(time.RFC3339)
+	}
+
+	alertEmail := &models.LoginAlertEmailFormat{
+		To:        recipient,
+		Subject:   "Suspicious sign in activity on " + email,
+		EmailType: "suspicious_login_activity",
+		Data:      data,
+	}
+
+	jsonBytes, err := json.Marshal(alertEmail)
+
+	if err != nil {
+		log.Println("error occurred while encoding the login alert email, Error: ", err.Error())
+		return
+	}
+
+	if err := repo.emailServiceRepo.SendEmail(jsonBytes); err != nil {
+		log.Println("error occurred while sending the login alert email, Error: ", err.Error())
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+type MusterRollRepo struct {
+	dbRepo           models.MusterRollInterface
+	attendanceLedger *AttendanceLedgerRepo
+	location         *time.Location
+}
+
+func NewMusterRollRepo(dbRepo models.MusterRollInterface, attendanceLedger *AttendanceLedgerRepo, location *time.Location) *MusterRollRepo {
+	return &MusterRollRepo{
+		dbRepo,
+		attendanceLedger,
+		location,
+	}
+}
+
+// DownloadMusterRoll builds the muster roll of the admin's employees for the month from the
+// work history and leave tables. only finished days are filled in, so the current month stops
+// at yesterday. the requested format, xlsx by default, is returned with the roll.
+func (repo *MusterRollRepo) DownloadMusterRoll(ctx echo.Context) (*models.MusterRoll, string, int32, error) {
+	musterRollRequest := new(models.MusterRollDownloadRequest)
+
+	if err := ctx.Bind(musterRollRequest); err != nil {
+		return nil, "", 400, errors.New("missing or invalid query parameters")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(musterRollRequest); err != nil {
+		return nil, "", 400, errors.New("invalid query parameters")
+	}
+
+	monthStart, err := time.Parse("2006-01", musterRollRequest.Month)
+
+	if err != nil {
+		return nil, "", 400, errors.New("month parameter must be in YYYY-MM format")
+	}
+
+	yesterday := time.Now().In(repo.location).AddDate(0, 0, -1).Format("2006-01-02")
+	fromDate := monthStart.Format("2006-01-02")
+	toDate := monthStart.AddDate(0, 1, -1).Format("2006-01-02")
+
+	if fromDate > time.Now().In(repo.location).Format("2006-01-02") {
+		return nil, "", 400, errors.New("month has not started yet")
+	}
+
+	if toDate > yesterday {
+		toDate = yesterday
+	}
+
+	format := musterRollRequest.Format
+
+	if format == "" {
+		format = "xlsx"
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(musterRollRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, "", 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, "", 400, errors.New("admin id not exists")
+	}
+
+	if musterRollRequest.CategoryId != "" {
+		categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(musterRollRequest.CategoryId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return nil, "", 500, errors.New("internal server error occurred")
+		}
+
+		if !categoryIdExists {
+			return nil, "", 400, errors.New("category id not exists")
+		}
+	}
+
+	employees, err := repo.dbRepo.GetMusterRollEmployees(musterRollRequest.AdminId, musterRollRequest.CategoryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, "", 500, errors.New("internal server error occurred")
+	}
+
+	if employees == nil {
+		return nil, "", 404, errors.New("employees was empty")
+	}
+
+	overtime, err := repo.dbRepo.GetMusterRollOvertime(musterRollRequest.AdminId, musterRollRequest.CategoryId, fromDate, toDate)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, "", 500, errors.New("internal server error occurred")
+	}
+
+	musterRoll := &models.MusterRoll{
+		Month: musterRollRequest.Month,
+		Days:  monthStart.AddDate(0, 1, -1).Day(),
+	}
+
+	for _, employee := range employees {
+		days, err := repo.attendanceLedger.BuildUserAttendanceDays(&models.AttendanceUser{
+			UserId:         employee.UserId,
+			CreatedDate:    employee.CreatedDate,
+			ShiftStartTime: employee.ShiftStartTime,
+			ShiftEndTime:   employee.ShiftEndTime,
+			BreakMinutes:   employee.BreakMinutes,
+		}, fromDate, toDate)
+
+		if err != nil {
+			log.Println("error occurred while building the muster roll, Error: ", err.Error())
+			return nil, "", 500, errors.New("internal server error occurred")
+		}
+
+		row, err := utils.BuildMusterRollRow(employee, days, musterRoll.Days, overtime[employee.UserId])
+
+		if err != nil {
+			log.Println("error occurred while building the muster roll, Error: ", err.Error())
+			return nil, "", 500, errors.New("internal server error occurred")
+		}
+
+		musterRoll.Rows = append(musterRoll.Rows, row)
+	}
+
+	return musterRoll, format, 200, nil
+}
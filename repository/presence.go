@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// events buffered per subscriber, a subscriber that falls further behind misses events
+const presenceSubscriberBuffer = 64
+
+type PresenceRepo struct {
+	dbRepo        models.PresenceInterface
+	authorization *AuthorizationRepo
+	mutex         sync.Mutex
+	subscribers   map[string]map[chan *models.PresenceEvent]struct{}
+}
+
+func NewPresenceRepo(dbRepo models.PresenceInterface, authorization *AuthorizationRepo) *PresenceRepo {
+	return &PresenceRepo{
+		dbRepo:        dbRepo,
+		authorization: authorization,
+		subscribers:   make(map[string]map[chan *models.PresenceEvent]struct{}),
+	}
+}
+
+// StartListener delivers the published events to the subscribers of this replica, listening
+// again after a short pause whenever the database connection fails
+func (repo *PresenceRepo) StartListener() {
+	for {
+		err := repo.dbRepo.ListenPresenceEvents(repo.dispatch)
+
+		log.Println("error occurred while listening for presence events, Error: ", err.Error())
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func (repo *PresenceRepo) dispatch(payload []byte) {
+	event := new(models.PresenceEvent)
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		log.Println("error occurred while reading a presence event, Error: ", err.Error())
+		return
+	}
+
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	for subscriber := range repo.subscribers[event.AdminId] {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Publish sends the event to the subscribers of every replica, a failure is only logged as the
+// change behind the event is already stored
+func (repo *PresenceRepo) Publish(event *models.PresenceEvent) {
+	event.OccurredAt = time.Now()
+
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		log.Println("error occurred while encoding a presence event, Error: ", err.Error())
+		return
+	}
+
+	if err := repo.dbRepo.PublishPresenceEvent(payload); err != nil {
+		log.Println("error occurred with database while publishing a presence event, Error: ", err.Error())
+	}
+}
+
+// SubscribePresence registers a subscriber for the events of the admin, the returned function
+// removes it
+func (repo *PresenceRepo) SubscribePresence(ctx echo.Context) (<-chan *models.PresenceEvent, func(), int32, error) {
+	adminId := ctx.Param("adminId")
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, nil, 400, errors.New("admin id not exists")
+	}
+
+	subscriber := make(chan *models.PresenceEvent, presenceSubscriberBuffer)
+
+	repo.mutex.Lock()
+
+	if repo.subscribers[adminId] == nil {
+		repo.subscribers[adminId] = make(map[chan *models.PresenceEvent]struct{})
+	}
+
+	repo.subscribers[adminId][subscriber] = struct{}{}
+
+	repo.mutex.Unlock()
+
+	unsubscribe := func() {
+		repo.mutex.Lock()
+		defer repo.mutex.Unlock()
+
+		delete(repo.subscribers[adminId], subscriber)
+
+		if len(repo.subscribers[adminId]) == 0 {
+			delete(repo.subscribers, adminId)
+		}
+	}
+
+	return subscriber, unsubscribe, 200, nil
+}
+
+// CheckSubscriber runs the checks of the stream route again for a subscriber that is already
+// streaming, so a revoked session, a removed account or a withdrawn permission stops the stream
+func (repo *PresenceRepo) CheckSubscriber(ctx echo.Context) (int32, error) {
+	claims, statusCode, err := repo.authorization.Authenticate(ctx)
+
+	if err != nil {
+		return statusCode, err
+	}
+
+	return repo.authorization.AuthorizePermission(ctx, claims, models.PermissionPresenceRead, []models.AuthRule{
+		{Source: models.SourceParam, Name: "adminId", Resource: models.ResourceAdmin},
+	})
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+// PunchClock stamps attendance punches with the server time. the date and time sent by the
+// device are only kept for audit and compared against the server time to catch wrong clocks.
+type PunchClock struct {
+	Location        *time.Location
+	MaxClockSkew    time.Duration
+	RejectClockSkew bool
+}
+
+// Stamp returns the punch for the current server time, clientDate and clientTime are optional
+// and read as wall clock values of the organization timezone
+func (clock *PunchClock) Stamp(clientDate string, clientTime string) (*models.WorkPunch, int32, error) {
+	punch := &models.WorkPunch{
+		ServerAt: time.Now().In(clock.Location).Truncate(time.Second),
+	}
+
+	if clientDate == "" || clientTime == "" {
+		return punch, 200, nil
+	}
+
+	clientAt, err := time.ParseInLocation("2006-01-02 15:04", clientDate+" "+clientTime, clock.Location)
+
+	if err != nil {
+		return nil, 400, errors.New("invalid date or time")
+	}
+
+	//the device only reports minutes, so the seconds of the server time are not counted as skew
+	skew := punch.ServerAt.Truncate(time.Minute).Sub(clientAt)
+	skewSeconds := int32(skew / time.Second)
+
+	punch.ClientAt = &clientAt
+	punch.ClockSkewSeconds = &skewSeconds
+
+	if skew > clock.MaxClockSkew || skew < -clock.MaxClockSkew {
+		if clock.RejectClockSkew {
+			return nil, 400, errors.New("device clock differs from the server time, please correct the device date and time")
+		}
+		punch.ClockSkewFlagged = true
+	}
+
+	return punch, 200, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+const reportLogoMaxBytes = 1 << 20
+
+var reportLogoFileTypes = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpg",
+}
+
+type ReportBrandingRepo struct {
+	dbRepo      models.ReportBrandingInterface
+	storageRepo models.ReportBrandingStorageInterface
+}
+
+func NewReportBrandingRepo(dbRepo models.ReportBrandingInterface, storageRepo models.ReportBrandingStorageInterface) *ReportBrandingRepo {
+	return &ReportBrandingRepo{
+		dbRepo,
+		storageRepo,
+	}
+}
+
+func (repo *ReportBrandingRepo) UpsertReportBranding(ctx echo.Context) (int32, error) {
+	reportBrandingRequest := new(models.ReportBrandingRequest)
+
+	if err := ctx.Bind(reportBrandingRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(reportBrandingRequest); err != nil {
+		return 400, errors.New("invalid request format")
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(reportBrandingRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return 400, errors.New("admin id not exists")
+	}
+
+	branding := &models.ReportBranding{
+		AdminId:      reportBrandingRequest.AdminId,
+		CompanyName:  reportBrandingRequest.CompanyName,
+		Address:      reportBrandingRequest.Address,
+		PhoneNumber:  reportBrandingRequest.PhoneNumber,
+		Email:        reportBrandingRequest.Email,
+		Website:      reportBrandingRequest.Website,
+		PrimaryColor: reportBrandingRequest.PrimaryColor,
+		AccentColor:  reportBrandingRequest.AccentColor,
+		FooterText:   reportBrandingRequest.FooterText,
+	}
+
+	if branding.PrimaryColor == "" {
+		branding.PrimaryColor = "#000000"
+	}
+
+	if branding.AccentColor == "" {
+		branding.AccentColor = "#000000"
+	}
+
+	if err := repo.dbRepo.UpsertReportBranding(branding); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *ReportBrandingRepo) GetReportBranding(ctx echo.Context) (*models.ReportBrandingResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	branding, _, err := repo.dbRepo.GetReportBranding(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if branding == nil {
+		return nil, 404, errors.New("report branding not configured")
+	}
+
+	return branding, 200, nil
+}
+
+// UpdateReportLogo stores the png or jpeg logo of the form field logo, up to 1MB, as the logo of
+// the admin's letterhead
+func (repo *ReportBrandingRepo) UpdateReportLogo(ctx echo.Context) (int32, error) {
+	adminId := ctx.Param("adminId")
+
+	branding, prevLogoKey, err := repo.dbRepo.GetReportBranding(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if branding == nil {
+		return 400, errors.New("report branding not configured")
+	}
+
+	file, err := ctx.FormFile("logo")
+
+	if err != nil {
+		return 400, errors.New("missing logo file")
+	}
+
+	if file.Size > reportLogoMaxBytes {
+		return 400, errors.New("logo file should not exceed 1MB")
+	}
+
+	src, err := file.Open()
+
+	if err != nil {
+		log.Println("error occurred while opening the uploaded file, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	defer src.Close()
+
+	logo, err := io.ReadAll(io.LimitReader(src, reportLogoMaxBytes+1))
+
+	if err != nil {
+		log.Println("error occurred while reading the uploaded file, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if len(logo) > reportLogoMaxBytes {
+		return 400, errors.New("logo file should not exceed 1MB")
+	}
+
+	fileType, ok := reportLogoFileTypes[http.DetectContentType(logo)]
+
+	if !ok {
+		return 400, errors.New("logo should be a png or jpeg image")
+	}
+
+	if _, _, err := utils.ReportLogoSize(logo); err != nil {
+		return 400, errors.New("logo image could not be read")
+	}
+
+	logoKey := fmt.Sprintf("%v.%v", adminId, fileType)
+
+	if err := repo.storageRepo.UploadReportLogo(logoKey, bytes.NewReader(logo)); err != nil {
+		log.Println("error occurred with aws s3, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if err := repo.dbRepo.UpdateReportBrandingLogo(adminId, &logoKey); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	//a logo of the other file type is replaced, not overwritten
+	if prevLogoKey != nil && *prevLogoKey != logoKey {
+		if err := repo.storageRepo.DeleteReportLogo(*prevLogoKey); err != nil {
+			log.Println("error occurred with aws s3, Error: ", err.Error())
+		}
+	}
+
+	return 200, nil
+}
+
+func (repo *ReportBrandingRepo) DeleteReportLogo(ctx echo.Context) (int32, error) {
+	adminId := ctx.Param("adminId")
+
+	branding, logoKey, err := repo.dbRepo.GetReportBranding(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if branding == nil || logoKey == nil {
+		return 400, errors.New("report logo was not there")
+	}
+
+	if err := repo.storageRepo.DeleteReportLogo(*logoKey); err != nil {
+		log.Println("error occurred with aws s3, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if err := repo.dbRepo.UpdateReportBrandingLogo(adminId, nil); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+// LoadUserReportBranding returns the letterhead of the user's admin with the logo, nil when the
+// admin did not configure one. a logo that cannot be downloaded is left out rather than failing
+// the report.
+func (repo *ReportBrandingRepo) LoadUserReportBranding(userId string) (*models.ReportBranding, error) {
+	branding, err := repo.dbRepo.GetUserReportBranding(userId)
+
+	if err != nil || branding == nil {
+		return nil, err
+	}
+
+	if branding.LogoKey != nil {
+		branding.Logo, err = repo.storageRepo.GetReportLogo(*branding.LogoKey)
+
+		if err != nil {
+			log.Println("error occurred with aws s3 while loading the report logo of the admin "+branding.AdminId+", Error: ", err.Error())
+			branding.Logo = nil
+		}
+	}
+
+	return branding, nil
+}
@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+const (
+	// longest date range of a report job, both dates inclusive
+	reportJobMaxDays = 366
+	// renders of one user before the user is marked failed in the job
+	reportJobRenderAttempts = 3
+	// a running job not updated for this long belonged to a worker that died and is claimed
+	// again, at most reportJobMaxAttempts times
+	reportJobStaleAfter  = 15 * time.Minute
+	reportJobMaxAttempts = 3
+	reportJobUrlExpiry   = time.Hour
+)
+
+type ReportJobRepo struct {
+	dbRepo      models.ReportJobInterface
+	storageRepo models.ReportJobStorageInterface
+	userRepo    *UserRepo
+}
+
+func NewReportJobRepo(dbRepo models.ReportJobInterface, storageRepo models.ReportJobStorageInterface, userRepo *UserRepo) *ReportJobRepo {
+	return &ReportJobRepo{
+		dbRepo,
+		storageRepo,
+		userRepo,
+	}
+}
+
+// StartWorkers starts the given number of report workers, each claims the next job as soon as
+// it finished one and checks for new jobs every poll interval while idle
+func (repo *ReportJobRepo) StartWorkers(workers int, pollInterval time.Duration) {
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			for {
+				if !repo.ProcessNextReportJob() {
+					time.Sleep(pollInterval)
+				}
+			}
+		}()
+	}
+}
+
+// ProcessNextReportJob claims and renders one job, false is returned when no job was waiting
+func (repo *ReportJobRepo) ProcessNextReportJob() bool {
+	job, err := repo.dbRepo.ClaimReportJob(reportJobStaleAfter, reportJobMaxAttempts)
+
+	if err != nil {
+		log.Println("error occurred with database while claiming a report job, Error: ", err.Error())
+		return false
+	}
+
+	if job == nil {
+		return false
+	}
+
+	repo.processReportJob(job)
+
+	return true
+}
+
+// processReportJob renders the pdf of every user of the job into a zip archive and stores the
+// archive. users that fail every render attempt are left out of the archive and recorded on
+// the job.
+func (repo *ReportJobRepo) processReportJob(job *models.ReportJob) {
+	items, err := repo.dbRepo.GetReportJobItems(job.JobId)
+
+	if err != nil {
+		log.Println("error occurred with database while processing the report job "+job.JobId+", Error: ", err.Error())
+		repo.failReportJob(job.JobId, "internal server error occurred")
+		return
+	}
+
+	archive, err := os.CreateTemp("", "report_job_*.zip")
+
+	if err != nil {
+		log.Println("error occurred while creating the report archive, Error: ", err.Error())
+		repo.failReportJob(job.JobId, "internal server error occurred")
+		return
+	}
+
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	zipWriter := zip.NewWriter(archive)
+
+	renderedUsers := 0
+	failedUsers := 0
+
+	for _, item := range items {
+		report, attempts, err := repo.renderUserReport(job, item)
+
+		if err != nil {
+			log.Println("error occurred while rendering the report of the user "+item.UserId+", Error: ", err.Error())
+
+			lastError := err.Error()
+
+			if err := repo.dbRepo.UpdateReportJobItem(job.JobId, item.UserId, "failed", attempts, &lastError); err != nil {
+				log.Println("error occurred with database while processing the report job "+job.JobId+", Error: ", err.Error())
+			}
+
+			failedUsers++
+			continue
+		}
+
+		entry, err := zipWriter.Create(reportFileName(item))
+
+		if err == nil {
+			_, err = entry.Write(report)
+		}
+
+		if err != nil {
+			log.Println("error occurred while writing the report archive, Error: ", err.Error())
+			repo.failReportJob(job.JobId, "internal server error occurred")
+			return
+		}
+
+		if err := repo.dbRepo.UpdateReportJobItem(job.JobId, item.UserId, "rendered", attempts, nil); err != nil {
+			log.Println("error occurred with database while processing the report job "+job.JobId+", Error: ", err.Error())
+		}
+
+		renderedUsers++
+	}
+
+	if renderedUsers == 0 {
+		repo.failReportJob(job.JobId, "no report could be rendered")
+		return
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		log.Println("error occurred while writing the report archive, Error: ", err.Error())
+		repo.failReportJob(job.JobId, "internal server error occurred")
+		return
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		log.Println("error occurred while reading the report archive, Error: ", err.Error())
+		repo.failReportJob(job.JobId, "internal server error occurred")
+		return
+	}
+
+	objectKey := job.JobId + ".zip"
+
+	if err := repo.storageRepo.UploadReportArchive(objectKey, archive); err != nil {
+		log.Println("error occurred while uploading the report archive, Error: ", err.Error())
+		repo.failReportJob(job.JobId, "error occurred while storing the report archive")
+		return
+	}
+
+	status := "completed"
+
+	if failedUsers > 0 {
+		status = "completed_with_errors"
+	}
+
+	if err := repo.dbRepo.CompleteReportJob(job.JobId, status, &objectKey, nil); err != nil {
+		log.Println("error occurred with database while completing the report job "+job.JobId+", Error: ", err.Error())
+		return
+	}
+
+	log.Printf("report job %s %s, %d rendered and %d failed\n", job.JobId, status, renderedUsers, failedUsers)
+}
+
+// renderUserReport renders the pdf of one user, retrying with a growing delay. the number of
+// attempts made is returned with the result.
+func (repo *ReportJobRepo) renderUserReport(job *models.ReportJob, item *models.ReportJobItem) ([]byte, int, error) {
+	var err error
+
+	for attempt := 1; attempt <= reportJobRenderAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * 2 * time.Second)
+		}
+
+		var file []byte
+
+		_, file, err = repo.userRepo.RenderUserReport(item.UserId, job.StartDate, job.EndDate)
+
+		if err != nil {
+			continue
+		}
+
+		return file, attempt, nil
+	}
+
+	return nil, reportJobRenderAttempts, err
+}
+
+func (repo *ReportJobRepo) failReportJob(jobId string, message string) {
+	if err := repo.dbRepo.CompleteReportJob(jobId, "failed", nil, &message); err != nil {
+		log.Println("error occurred with database while failing the report job "+jobId+", Error: ", err.Error())
+	}
+}
+
+// reportFileName names the pdf of the user inside the archive after the user, the id keeps
+// users with the same name apart
+func reportFileName(item *models.ReportJobItem) string {
+	return fmt.Sprintf("%s_%s.pdf", utils.SafeFileName(item.UserName), item.UserId)
+}
+
+func (repo *ReportJobRepo) CreateReportJob(ctx echo.Context) (string, int32, error) {
+	createReportJobRequest := new(models.CreateReportJobRequest)
+
+	if err := ctx.Bind(createReportJobRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
+		log.Println("error occurred while registering the date validation, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if err := validation.Struct(createReportJobRequest); err != nil {
+		return "", 400, errors.New("invalid request format")
+	}
+
+	if err := utils.CompareDates(createReportJobRequest.StartDate, createReportJobRequest.EndDate); err != nil {
+		return "", 400, errors.New("end date should be greater than start date")
+	}
+
+	startDate, _ := time.Parse("2006-01-02", createReportJobRequest.StartDate)
+	endDate, _ := time.Parse("2006-01-02", createReportJobRequest.EndDate)
+
+	if endDate.Sub(startDate) >= reportJobMaxDays*24*time.Hour {
+		return "", 400, fmt.Errorf("date range should not exceed %d days", reportJobMaxDays)
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(createReportJobRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return "", 400, errors.New("admin id not exists")
+	}
+
+	if createReportJobRequest.CategoryId != "" {
+		categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(createReportJobRequest.CategoryId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if !categoryIdExists {
+			return "", 400, errors.New("category id not exists")
+		}
+	}
+
+	reportJob := &models.ReportJob{
+		JobId:      uuid.NewString(),
+		AdminId:    createReportJobRequest.AdminId,
+		CategoryId: optionalCategoryId(createReportJobRequest.CategoryId),
+		StartDate:  createReportJobRequest.StartDate,
+		EndDate:    createReportJobRequest.EndDate,
+	}
+
+	totalUsers, err := repo.dbRepo.CreateReportJob(reportJob)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if totalUsers == 0 {
+		return "", 404, errors.New("users was empty")
+	}
+
+	return reportJob.JobId, 201, nil
+}
+
+// GetReportJob returns the progress of the job, the users that failed to render and, once the
+// archive is stored, a download url valid for an hour
+func (repo *ReportJobRepo) GetReportJob(ctx echo.Context) (*models.ReportJobResponse, int32, error) {
+	jobId := ctx.Param("jobId")
+
+	reportJob, err := repo.dbRepo.GetReportJob(jobId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if reportJob == nil {
+		return nil, 404, errors.New("report job not exists")
+	}
+
+	if reportJob.FailedUsers > 0 {
+		reportJob.Failures, err = repo.dbRepo.GetReportJobFailures(jobId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return nil, 500, errors.New("internal server error occurred")
+		}
+	}
+
+	if reportJob.ObjectKey != nil {
+		downloadUrl, err := repo.storageRepo.GetReportArchiveUrl(*reportJob.ObjectKey, reportJobUrlExpiry)
+
+		if err != nil {
+			log.Println("error occurred while signing the report archive url, Error: ", err.Error())
+			return nil, 500, errors.New("internal server error occurred")
+		}
+
+		reportJob.DownloadUrl = &downloadUrl
+	}
+
+	return reportJob, 200, nil
+}
+
+func (repo *ReportJobRepo) GetReportJobs(ctx echo.Context) (int32, []*models.ReportJobResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	reportJobsCount, err := repo.dbRepo.GetReportJobsCount(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	reportJobs, err := repo.dbRepo.GetReportJobs(adminId, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if reportJobs == nil {
+		return 0, nil, 404, errors.New("report jobs was empty")
+	}
+
+	return reportJobsCount, reportJobs, 200, nil
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"errors"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+type RoleRepo struct {
+	dbRepo models.RoleInterface
+}
+
+func NewRoleRepo(dbRepo models.RoleInterface) *RoleRepo {
+	return &RoleRepo{
+		dbRepo,
+	}
+}
+
+func checkGrantablePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !models.GrantablePermissions[permission] {
+			return errors.New("permission " + permission + " can not be granted by a role")
+		}
+	}
+
+	return nil
+}
+
+func (repo *RoleRepo) CreateRole(ctx echo.Context) (string, int32, error) {
+	createRoleRequest := new(models.CreateRoleRequest)
+
+	if err := ctx.Bind(createRoleRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(createRoleRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	if err := checkGrantablePermissions(createRoleRequest.Permissions); err != nil {
+		return "", 400, err
+	}
+
+	roleNameExists, err := repo.dbRepo.CheckRoleNameExists(createRoleRequest.AdminId, createRoleRequest.RoleName, "")
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if roleNameExists {
+		return "", 400, errors.New("role name already exists")
+	}
+
+	role := &models.Role{
+		RoleId:         uuid.NewString(),
+		AdminId:        createRoleRequest.AdminId,
+		RoleName:       createRoleRequest.RoleName,
+		Permissions:    createRoleRequest.Permissions,
+		CategoryScoped: createRoleRequest.CategoryScoped,
+	}
+
+	if err := repo.dbRepo.CreateRole(role); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	return role.RoleId, 201, nil
+}
+
+func (repo *RoleRepo) GetRoles(ctx echo.Context) ([]*models.RoleResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	roles, err := repo.dbRepo.GetRoles(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if roles == nil {
+		return nil, 404, errors.New("roles was empty")
+	}
+
+	return roles, 200, nil
+}
+
+// UpdateRole replaces the name and permissions of the role, the users holding the role get the
+// new permissions with their next request
+func (repo *RoleRepo) UpdateRole(ctx echo.Context) (int32, error) {
+	updateRoleRequest := new(models.UpdateRoleRequest)
+
+	if err := ctx.Bind(updateRoleRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(updateRoleRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	if err := checkGrantablePermissions(updateRoleRequest.Permissions); err != nil {
+		return 400, err
+	}
+
+	role, err := repo.dbRepo.GetRole(updateRoleRequest.RoleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if role == nil {
+		return 400, errors.New("role id not exists")
+	}
+
+	roleNameExists, err := repo.dbRepo.CheckRoleNameExists(role.AdminId, updateRoleRequest.RoleName, role.RoleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if roleNameExists {
+		return 400, errors.New("role name already exists")
+	}
+
+	role.RoleName = updateRoleRequest.RoleName
+	role.Permissions = updateRoleRequest.Permissions
+	role.CategoryScoped = updateRoleRequest.CategoryScoped
+
+	if err := repo.dbRepo.UpdateRole(role); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *RoleRepo) DeleteRole(ctx echo.Context) (int32, error) {
+	roleId := ctx.Param("roleId")
+
+	role, err := repo.dbRepo.GetRole(roleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if role == nil {
+		return 400, errors.New("role id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteRole(roleId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *RoleRepo) AssignUserRole(ctx echo.Context) (int32, error) {
+	assignUserRoleRequest := new(models.AssignUserRoleRequest)
+
+	if err := ctx.Bind(assignUserRoleRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(assignUserRoleRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(assignUserRoleRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 400, errors.New("user id not exists")
+	}
+
+	role, err := repo.dbRepo.GetRole(assignUserRoleRequest.RoleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if role == nil {
+		return 400, errors.New("role id not exists")
+	}
+
+	adminId, err := repo.dbRepo.GetAdminIdByUserId(assignUserRoleRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if adminId != role.AdminId {
+		return 400, errors.New("role not belongs to the organization of the user")
+	}
+
+	if err := repo.dbRepo.AssignUserRole(assignUserRoleRequest.UserId, assignUserRoleRequest.RoleId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *RoleRepo) RemoveUserRole(ctx echo.Context) (int32, error) {
+	userId := ctx.Param("userId")
+	roleId := ctx.Param("roleId")
+
+	removed, err := repo.dbRepo.RemoveUserRole(userId, roleId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !removed {
+		return 400, errors.New("user not holds the role")
+	}
+
+	return 200, nil
+}
+
+// UpdateUserManagedCategories replaces the categories the user manages, the scope of the
+// category scoped roles of the user
+func (repo *RoleRepo) UpdateUserManagedCategories(ctx echo.Context) (int32, error) {
+	updateRequest := new(models.UpdateUserManagedCategoriesRequest)
+
+	if err := ctx.Bind(updateRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(updateRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(updateRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 400, errors.New("user id not exists")
+	}
+
+	adminId, err := repo.dbRepo.GetAdminIdByUserId(updateRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	categoriesExist, err := repo.dbRepo.CheckAdminCategoriesExist(adminId, updateRequest.CategoryIds)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !categoriesExist {
+		return 400, errors.New("category id not exists")
+	}
+
+	if err := repo.dbRepo.SetUserManagedCategories(updateRequest.UserId, updateRequest.CategoryIds); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *RoleRepo) GetUserRoles(ctx echo.Context) (*models.UserRolesResponse, int32, error) {
+	userId := ctx.Param("userId")
+
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return nil, 400, errors.New("user id not exists")
+	}
+
+	roles, err := repo.dbRepo.GetUserRoles(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	managedCategoryIds, err := repo.dbRepo.GetUserManagedCategoryIds(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if roles == nil {
+		roles = []*models.RoleResponse{}
+	}
+
+	if managedCategoryIds == nil {
+		managedCategoryIds = []string{}
+	}
+
+	return &models.UserRolesResponse{
+		Roles:              roles,
+		ManagedCategoryIds: managedCategoryIds,
+	}, 200, nil
+}
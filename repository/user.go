@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -22,17 +23,41 @@ type UserRepo struct {
 	dbRepo           models.UserDatabaseInterface
 	storageRepo      models.UserStorageInterface
 	emailServiceRepo models.UserEmailServiceInterface
+	punchClock       *PunchClock
+	attendanceLedger *AttendanceLedgerRepo
+	reportBranding   *ReportBrandingRepo
+	issuedReports    *IssuedReportRepo
+	presence         *PresenceRepo
+	webhooks         *WebhookRepo
+	tokens           *AuthTokenRepo
+	throttle         *LoginThrottleRepo
 }
 
 func NewUserRepo(
 	dbRepo models.UserDatabaseInterface,
 	storageRepo models.UserStorageInterface,
 	emailServiceRepo models.UserEmailServiceInterface,
+	punchClock *PunchClock,
+	attendanceLedger *AttendanceLedgerRepo,
+	reportBranding *ReportBrandingRepo,
+	issuedReports *IssuedReportRepo,
+	presence *PresenceRepo,
+	webhooks *WebhookRepo,
+	tokens *AuthTokenRepo,
+	throttle *LoginThrottleRepo,
 ) *UserRepo {
 	return &UserRepo{
 		dbRepo,
 		storageRepo,
 		emailServiceRepo,
+		punchClock,
+		attendanceLedger,
+		reportBranding,
+		issuedReports,
+		presence,
+		webhooks,
+		tokens,
+		throttle,
 	}
 }
 func (repo *UserRepo) CreateUser(ctx echo.Context) (string, int32, error) {
@@ -93,6 +118,15 @@ func (repo *UserRepo) CreateUser(ctx echo.Context) (string, int32, error) {
 		return "", 500, errors.New("internal server error")
 	}
 
+	repo.webhooks.Enqueue(userId, "user.created", &models.WebhookUserEvent{
+		UserId:      userId,
+		CategoryId:  user.CategoryId,
+		Name:        user.Name,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Position:    user.Position,
+	})
+
 	log.Println("[CreateUser] Preparing welcome email...")
 	userWelcomeEmailFormat := &models.UserWelcomeEmailFormat{
 		To:        createUserRequest.Email,
@@ -180,7 +214,7 @@ func (repo *UserRepo) DeleteUser(ctx echo.Context) (int32, error) {
 	return 200, nil
 }
 
-func (repo *UserRepo) UserLogin(ctx echo.Context) (*models.UserLoginResponse, int32, error) {
+func (repo *UserRepo) UserLogin(ctx echo.Context) (*models.AuthTokenResponse, int32, error) {
 	userLoginRequest := new(models.UserLoginRequest)
 	if err := ctx.Bind(userLoginRequest); err != nil {
 		return nil, 400, errors.New("invalid json request body")
@@ -191,6 +225,21 @@ func (repo *UserRepo) UserLogin(ctx echo.Context) (*models.UserLoginResponse, in
 		return nil, 400, errors.New("request body validation error")
 	}
 
+	if statusCode, err := repo.throttle.CheckLoginAttempts(ctx, models.LoginScopeUserAccount, userLoginRequest.Email); err != nil {
+		return nil, statusCode, err
+	}
+
+	userEmailExists, err := repo.dbRepo.CheckUserEmailExists(userLoginRequest.Email)
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !userEmailExists {
+		repo.throttle.RecordLoginFailure(ctx, models.LoginScopeUserAccount, userLoginRequest.Email)
+		return nil, 400, errors.New("user email does not exist")
+	}
+
 	userId, userName, hashedPassword, err := repo.dbRepo.GetUserForLogin(userLoginRequest.Email)
 	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
@@ -198,129 +247,278 @@ func (repo *UserRepo) UserLogin(ctx echo.Context) (*models.UserLoginResponse, in
 	}
 
 	if err := utils.CheckPassword(hashedPassword, userLoginRequest.Password); err != nil {
+		repo.throttle.RecordLoginFailure(ctx, models.LoginScopeUserAccount, userLoginRequest.Email)
 		return nil, 401, errors.New("incorrect password")
 	}
 
+	repo.throttle.ClearLoginAttempts(models.LoginScopeUserAccount, userLoginRequest.Email)
+
 	adminId, err := repo.dbRepo.GetAdminIdByUserId(userId)
 	if err != nil {
 		log.Println("error fetching admin_id: ", err.Error())
 		return nil, 500, errors.New("failed to fetch admin ID")
 	}
 
-	token, err := utils.GenerateToken(userId, userLoginRequest.Email, userName, adminId, "")
+	tokens, err := repo.tokens.IssueTokens(&models.TokenSubject{
+		AdminId: adminId,
+		UserId:  &userId,
+		Email:   userLoginRequest.Email,
+		Name:    userName,
+	})
 	if err != nil {
 		log.Println("error occurred while generating token, Error: ", err.Error())
 		return nil, 500, errors.New("internal server error occurred")
 	}
 
-	response := &models.UserLoginResponse{
-		Token: token,
-	}
-	return response, 200, nil
+	return tokens, 200, nil
 }
 
-func (repo *UserRepo) UserWorkLogin(ctx echo.Context) (int32, error) {
+func (repo *UserRepo) UserWorkLogin(ctx echo.Context) (string, int32, error) {
 	userWorkLoginRequest := new(models.UserWorkLoginRequest)
 
 	if err := ctx.Bind(userWorkLoginRequest); err != nil {
-		return 400, errors.New("invalid json request body")
+		return "", 400, errors.New("invalid json request body")
 	}
 
 	validation := validator.New()
 
 	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.RegisterValidation("latitude", utils.ValidateLatitude); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.RegisterValidation("longitude", utils.ValidateLongitude); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.Struct(userWorkLoginRequest); err != nil {
-		return 400, errors.New("request body validation error")
+		return "", 400, errors.New("request body validation error")
+	}
+
+	punch, statusCode, err := repo.punchClock.Stamp(userWorkLoginRequest.LoginDate, userWorkLoginRequest.LoginTime)
+
+	if err != nil {
+		return "", statusCode, err
 	}
 
-	userWorkLoginEntryExists, err := repo.dbRepo.CheckUserWorkEntryExists(userWorkLoginRequest.UserId, userWorkLoginRequest.LoginDate)
+	openSessionExists, err := repo.dbRepo.CheckUserOpenWorkSessionExists(userWorkLoginRequest.UserId)
 
 	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if openSessionExists {
+		return "", 400, errors.New("work session already open, logout before starting a new one")
+	}
+
+	sessionOverlapExists, err := repo.dbRepo.CheckUserWorkSessionOverlap(userWorkLoginRequest.UserId, punch.ServerAt)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if sessionOverlapExists {
+		return "", 409, errors.New("work session overlaps with an earlier session")
+	}
+
+	geofence, statusCode, err := repo.evaluateUserGeofence(userWorkLoginRequest.UserId, userWorkLoginRequest.Latitude, userWorkLoginRequest.Longitude)
+
+	if err != nil {
+		return "", statusCode, err
 	}
 
-	if userWorkLoginEntryExists {
-		return 400, errors.New("duplicate login entries on the same date not allowed")
+	if geofence.Reject {
+		return "", 403, errors.New("work login location is outside the allowed work sites")
 	}
 
 	userWorkHistory := &models.UserWorkHistory{
+		SessionId:    uuid.NewString(),
 		UserId:       userWorkLoginRequest.UserId,
-		WorkDate:     userWorkLoginRequest.LoginDate,
-		LoginTime:    userWorkLoginRequest.LoginTime,
-		LogoutTime:   "pending",
+		WorkDate:     punch.ServerAt.Format("2006-01-02"),
 		Latitude:     userWorkLoginRequest.Latitude,
 		Longitude:    userWorkLoginRequest.Longitude,
 		UploadedWork: "pending",
+		Punch:        punch,
+		Geofence:     geofence,
 	}
 
-	if err := repo.dbRepo.UserWorkLogin(userWorkHistory); err != nil {
+	sessionOpened, err := repo.dbRepo.UserWorkLogin(userWorkHistory)
+
+	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
+		return "", 500, errors.New("internal server error occurred")
 	}
 
-	return 200, nil
+	//a concurrent login of the user opened a session after the checks above
+	if !sessionOpened {
+		return "", 409, errors.New("work session overlaps with an earlier session")
+	}
+
+	repo.presence.Publish(&models.PresenceEvent{
+		EventType: "punch_in",
+		UserId:    userWorkHistory.UserId,
+		SessionId: userWorkHistory.SessionId,
+		Latitude:  userWorkHistory.Latitude,
+		Longitude: userWorkHistory.Longitude,
+	})
+
+	repo.webhooks.Enqueue(userWorkHistory.UserId, "work.login", &models.WebhookWorkEvent{
+		UserId:    userWorkHistory.UserId,
+		SessionId: userWorkHistory.SessionId,
+		PunchedAt: punch.ServerAt,
+		Latitude:  userWorkHistory.Latitude,
+		Longitude: userWorkHistory.Longitude,
+	})
+
+	return userWorkHistory.SessionId, 200, nil
 }
 
-func (repo *UserRepo) UserWorkLogout(ctx echo.Context) (int32, error) {
+func (repo *UserRepo) UserWorkLogout(ctx echo.Context) (string, int32, error) {
 	userWorkLogoutRequest := new(models.UserWorkLogoutRequest)
 
 	if err := ctx.Bind(userWorkLogoutRequest); err != nil {
-		return 400, errors.New("invalid json request body")
+		return "", 400, errors.New("invalid json request body")
 	}
 
 	validation := validator.New()
 
 	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
 		log.Println("Error occurred while registering validation, Error: ", err.Error())
-		return 500, errors.New("internal server error")
+		return "", 500, errors.New("internal server error")
+	}
+
+	if err := validation.RegisterValidation("latitude", utils.ValidateLatitude); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return "", 500, errors.New("internal server error")
+	}
+
+	if err := validation.RegisterValidation("longitude", utils.ValidateLongitude); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return "", 500, errors.New("internal server error")
 	}
 
 	if err := validation.Struct(userWorkLogoutRequest); err != nil {
-		return 400, errors.New("request body validation error")
+		return "", 400, errors.New("request body validation error")
 	}
 
-	userWorkLoginEntryExists, err := repo.dbRepo.CheckUserWorkLoginEntryExists(userWorkLogoutRequest.UserId, userWorkLogoutRequest.LogoutDate)
+	punch, statusCode, err := repo.punchClock.Stamp(userWorkLogoutRequest.LogoutDate, userWorkLogoutRequest.LogoutTime)
 
 	if err != nil {
+		return "", statusCode, err
+	}
+
+	//clients that do not track sessions close the latest open session
+	if userWorkLogoutRequest.SessionId == "" {
+		openSessionId, err := repo.dbRepo.GetUserOpenWorkSessionId(userWorkLogoutRequest.UserId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if openSessionId == "" {
+			return "", 400, errors.New("logout entry not allowed without login entry")
+		}
+
+		userWorkLogoutRequest.SessionId = openSessionId
+	} else {
+		openSessionExists, err := repo.dbRepo.CheckUserOpenWorkSessionIdExists(userWorkLogoutRequest.UserId, userWorkLogoutRequest.SessionId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return "", 500, errors.New("internal server error occurred")
+		}
+
+		if !openSessionExists {
+			return "", 400, errors.New("work session not exists or already closed")
+		}
+	}
+
+	//logout location is optional, older app versions do not send it
+	logoutGeofence := new(models.GeofenceResult)
+
+	if userWorkLogoutRequest.Latitude != "" {
+		geofence, statusCode, err := repo.evaluateUserGeofence(userWorkLogoutRequest.UserId, userWorkLogoutRequest.Latitude, userWorkLogoutRequest.Longitude)
+
+		if err != nil {
+			return "", statusCode, err
+		}
+
+		if geofence.Reject {
+			return "", 403, errors.New("work logout location is outside the allowed work sites")
+		}
+
+		logoutGeofence = geofence
+	}
+
+	if err := repo.dbRepo.UserWorkLogout(userWorkLogoutRequest, punch, logoutGeofence); err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	repo.presence.Publish(&models.PresenceEvent{
+		EventType: "punch_out",
+		UserId:    userWorkLogoutRequest.UserId,
+		SessionId: userWorkLogoutRequest.SessionId,
+		Latitude:  userWorkLogoutRequest.Latitude,
+		Longitude: userWorkLogoutRequest.Longitude,
+	})
+
+	repo.webhooks.Enqueue(userWorkLogoutRequest.UserId, "work.logout", &models.WebhookWorkEvent{
+		UserId:    userWorkLogoutRequest.UserId,
+		SessionId: userWorkLogoutRequest.SessionId,
+		PunchedAt: punch.ServerAt,
+		Latitude:  userWorkLogoutRequest.Latitude,
+		Longitude: userWorkLogoutRequest.Longitude,
+	})
+
+	return userWorkLogoutRequest.SessionId, 200, nil
+}
+
+func (repo *UserRepo) evaluateUserGeofence(userId string, latitude string, longitude string) (*models.GeofenceResult, int32, error) {
+	parsedLatitude, err := strconv.ParseFloat(latitude, 64)
+
+	if err != nil {
+		return nil, 400, errors.New("invalid latitude")
 	}
 
-	if !userWorkLoginEntryExists {
-		return 400, errors.New("logout entry not allowed without login entry")
+	parsedLongitude, err := strconv.ParseFloat(longitude, 64)
+
+	if err != nil {
+		return nil, 400, errors.New("invalid longitude")
 	}
 
-	if err := repo.dbRepo.UserWorkLogout(userWorkLogoutRequest); err != nil {
+	workSites, err := repo.dbRepo.GetUserWorkSites(userId)
+
+	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
+		return nil, 500, errors.New("internal server error occurred")
 	}
 
-	return 200, nil
+	geofence := utils.EvaluateGeofence(workSites, models.GeoPoint{
+		Latitude:  parsedLatitude,
+		Longitude: parsedLongitude,
+	})
+
+	return geofence, 200, nil
 }
 
 func (repo *UserRepo) ApplyUserLeave(ctx echo.Context) (int32, error) {
@@ -337,40 +535,255 @@ func (repo *UserRepo) ApplyUserLeave(ctx echo.Context) (int32, error) {
 		return 500, errors.New("internal server error")
 	}
 
+	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return 500, errors.New("internal server error")
+	}
+
 	if err := validation.Struct(userLeaveRequest); err != nil {
 		return 400, errors.New("request body validation error")
 	}
 
-	if err := utils.CompareDates(userLeaveRequest.LeaveFrom, userLeaveRequest.LeaveTo); err != nil {
+	leaveDays, err := utils.CountLeaveDays(userLeaveRequest.LeaveFrom, userLeaveRequest.LeaveTo)
+
+	if err != nil {
 		return 400, err
 	}
 
-	userPendingLeaveExists, err := repo.dbRepo.CheckUserPendingLeaveExists(userLeaveRequest.UserId)
+	userIdExists, err := repo.dbRepo.CheckUserIdExists(userLeaveRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !userIdExists {
+		return 400, errors.New("user id not exists")
+	}
+
+	workCalendar, err := repo.dbRepo.GetUserWorkCalendar(userLeaveRequest.UserId, userLeaveRequest.LeaveFrom, userLeaveRequest.LeaveTo)
 
 	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
 		return 500, errors.New("internal server error occurred")
 	}
 
-	if userPendingLeaveExists {
-		return 400, errors.New("pending leave already exists")
+	leaveGranularity := userLeaveRequest.LeaveGranularity
+
+	if leaveGranularity == "" {
+		leaveGranularity = "full_day"
+	}
+
+	var leaveStartTime, leaveEndTime *string
+	var leaveMinutes *int32
+	var shiftStartTime, shiftEndTime string
+
+	//holidays and weekly offs inside the range are not charged to the leave balance
+	if leaveGranularity == "full_day" {
+		leaveDays, err = utils.CountWorkingDays(userLeaveRequest.LeaveFrom, userLeaveRequest.LeaveTo, workCalendar)
+
+		if err != nil {
+			return 400, err
+		}
+
+		if leaveDays == 0 {
+			return 400, errors.New("leave dates are all holidays or weekly offs")
+		}
+	}
+
+	if leaveGranularity != "full_day" {
+		if userLeaveRequest.LeaveFrom != userLeaveRequest.LeaveTo {
+			return 400, errors.New("partial day leave must start and end on the same date")
+		}
+
+		leaveDate, err := time.Parse("2006-01-02", userLeaveRequest.LeaveFrom)
+
+		if err != nil {
+			return 400, errors.New("invalid date formates")
+		}
+
+		if reason := utils.NonWorkingDayReason(leaveDate, workCalendar); reason != "" {
+			return 400, fmt.Errorf("leave date is not a working day (%s)", reason)
+		}
+
+		shiftStartTime, shiftEndTime, err = repo.dbRepo.GetUserShift(userLeaveRequest.UserId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		startTime, endTime, portionMinutes, shiftMinutes, err := utils.ShiftLeavePortion(
+			leaveGranularity,
+			userLeaveRequest.StartTime,
+			userLeaveRequest.EndTime,
+			shiftStartTime,
+			shiftEndTime,
+		)
+
+		if err != nil {
+			return 400, err
+		}
+
+		if leaveGranularity == "hours" {
+			leaveDays = math.Round(float64(portionMinutes)/float64(shiftMinutes)*100) / 100
+		} else {
+			leaveDays = 0.5
+		}
+
+		minutes := int32(portionMinutes)
+
+		leaveStartTime = &startTime
+		leaveEndTime = &endTime
+		leaveMinutes = &minutes
+	}
+
+	activeLeaves, err := repo.dbRepo.GetUserActiveLeavesBetween(userLeaveRequest.UserId, userLeaveRequest.LeaveFrom, userLeaveRequest.LeaveTo)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	for _, activeLeave := range activeLeaves {
+		overlaps := activeLeave.LeaveGranularity == "full_day" || leaveGranularity == "full_day"
+
+		if !overlaps {
+			overlaps, err = utils.LeavePortionsOverlap(
+				*leaveStartTime,
+				*leaveEndTime,
+				*activeLeave.LeaveStartTime,
+				*activeLeave.LeaveEndTime,
+				shiftStartTime,
+			)
+
+			if err != nil {
+				log.Println("error occurred while comparing leave times, Error: ", err.Error())
+				return 500, errors.New("internal server error occurred")
+			}
+		}
+
+		if !overlaps {
+			continue
+		}
+
+		if activeLeave.LeaveGranularity != "full_day" {
+			return 409, fmt.Errorf(
+				"leave overlaps with the %s leave %s on %s from %s to %s",
+				activeLeave.LeaveStatus,
+				activeLeave.LeaveId,
+				activeLeave.LeaveFrom,
+				*activeLeave.LeaveStartTime,
+				*activeLeave.LeaveEndTime,
+			)
+		}
+
+		return 409, fmt.Errorf(
+			"leave overlaps with the %s leave %s from %s to %s",
+			activeLeave.LeaveStatus,
+			activeLeave.LeaveId,
+			activeLeave.LeaveFrom,
+			activeLeave.LeaveTo,
+		)
+	}
+
+	var leaveTypeId *string
+
+	if userLeaveRequest.LeaveTypeId == "" {
+		//leaves without a type are only accepted while the category of the user has no leave types
+		userHasLeaveTypes, err := repo.dbRepo.CheckUserHasLeaveTypes(userLeaveRequest.UserId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if userHasLeaveTypes {
+			return 400, errors.New("leave type is required")
+		}
+	} else {
+		leaveBalance, err := repo.dbRepo.GetUserLeaveBalance(userLeaveRequest.UserId, userLeaveRequest.LeaveTypeId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if leaveBalance == nil {
+			return 400, errors.New("leave type not exists for the user category")
+		}
+
+		if !leaveBalance.AllowNegativeBalance && leaveDays > leaveBalance.AvailableDays {
+			return 400, fmt.Errorf("insufficient leave balance, %g days available", leaveBalance.AvailableDays)
+		}
+
+		leaveTypeId = &userLeaveRequest.LeaveTypeId
+	}
+
+	approvalChain, err := repo.dbRepo.GetLeaveApprovalChainSteps(userLeaveRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	managerId, err := repo.dbRepo.GetUserReportingManagerId(userLeaveRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
 	}
 
 	userLeave := &models.UserLeave{
 		LeaveId:              uuid.NewString(),
 		UserId:               userLeaveRequest.UserId,
+		LeaveTypeId:          leaveTypeId,
 		LeaveFrom:            userLeaveRequest.LeaveFrom,
 		LeaveTo:              userLeaveRequest.LeaveTo,
+		LeaveGranularity:     leaveGranularity,
+		LeaveStartTime:       leaveStartTime,
+		LeaveEndTime:         leaveEndTime,
+		LeaveMinutes:         leaveMinutes,
+		LeaveDays:            leaveDays,
 		LeaveReason:          userLeaveRequest.LeaveReason,
 		LeaveStatus:          "pending",
 		LeaveStatusUpdatedBy: "user",
 	}
 
-	if err := repo.dbRepo.ApplyUserLeave(userLeave); err != nil {
+	approvalSteps := utils.BuildLeaveApprovalSteps(userLeave.LeaveId, approvalChain, managerId)
+
+	//the overlap checks above only saw these leaves, a leave applied meanwhile fails the insert
+	checkedLeaveIds := make([]string, 0, len(activeLeaves))
+
+	for _, activeLeave := range activeLeaves {
+		checkedLeaveIds = append(checkedLeaveIds, activeLeave.LeaveId)
+	}
+
+	result, err := repo.dbRepo.ApplyUserLeave(userLeave, approvalSteps, checkedLeaveIds)
+
+	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
 		return 500, errors.New("internal server error occurred")
 	}
 
+	switch result {
+	case models.LeaveApplyConflict:
+		return 409, errors.New("another leave was applied for these dates at the same time, please try again")
+	case models.LeaveApplyInsufficientBalance:
+		//the balance check above raced with another leave of the same leave type
+		return 409, errors.New("insufficient leave balance, another leave of the same type was applied at the same time")
+	}
+
+	repo.presence.Publish(&models.PresenceEvent{
+		EventType: "leave_applied",
+		UserId:    userLeave.UserId,
+		LeaveId:   userLeave.LeaveId,
+		LeaveFrom: userLeave.LeaveFrom,
+		LeaveTo:   userLeave.LeaveTo,
+	})
+
+	repo.webhooks.Enqueue(userLeave.UserId, "leave.applied", webhookLeaveEvent(userLeave))
+
 	return 200, nil
 }
 
@@ -533,58 +946,76 @@ func (repo *UserRepo) CancelUserLeave(ctx echo.Context) (int32, error) {
 		return 400, errors.New("leave id not exists")
 	}
 
-	leaveExists, err := repo.dbRepo.CheckPendingLeaveExistsByLeaveId(leaveId)
+	userLeave, err := repo.dbRepo.GetUserLeave(leaveId)
 
 	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
 		return 500, errors.New("internal server error occurred")
 	}
 
-	if !leaveExists {
-		return 400, errors.New("leave was not in pending status")
+	if userLeave.UserId != userId {
+		return 400, errors.New("leave not belongs to the user")
 	}
 
-	if err := repo.dbRepo.CancelUserLeave(leaveId, userType); err != nil {
-		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
-	}
-
-	return 200, nil
-}
+	switch userLeave.LeaveStatus {
+	case "pending":
+	case "granted":
+		//users may take back a granted leave until it starts, admins at any time
+		today := time.Now().In(repo.punchClock.Location).Format("2006-01-02")
 
-func (repo *UserRepo) GrantUserLeave(ctx echo.Context) (int32, error) {
-	leaveId := ctx.Param("leaveId")
+		if userType == "user" && userLeave.LeaveFrom <= today {
+			return 400, errors.New("leave already started, only admin can cancel it")
+		}
+	default:
+		return 400, errors.New("leave was already canceled")
+	}
 
-	leaveExists, err := repo.dbRepo.CheckLeaveIdExists(leaveId)
+	canceled, err := repo.dbRepo.CancelUserLeave(leaveId, userType, userLeave.LeaveStatus)
 
 	if err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
 		return 500, errors.New("internal server error occurred")
 	}
 
-	if !leaveExists {
-		return 400, errors.New("leave id not exists")
+	if !canceled {
+		return 409, errors.New("leave status was changed, please try again")
 	}
 
-	pendingLeavExists, err := repo.dbRepo.CheckPendingLeaveExistsByLeaveId(leaveId)
-
-	if err != nil {
-		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
+	//only a granted leave is part of the ledger
+	if userLeave.LeaveStatus == "granted" {
+		repo.recomputeLeaveAttendance(userLeave)
 	}
 
-	if !pendingLeavExists {
-		return 400, errors.New("leave was not in pending status")
-	}
+	leaveEvent := webhookLeaveEvent(userLeave)
+	leaveEvent.CancelledBy = userType
 
-	if err := repo.dbRepo.GrantUserLeave(leaveId); err != nil {
-		log.Println("error occurred with database, Error: ", err.Error())
-		return 500, errors.New("internal server error occurred")
-	}
+	repo.webhooks.Enqueue(userLeave.UserId, "leave.cancelled", leaveEvent)
 
 	return 200, nil
 }
 
+func webhookLeaveEvent(userLeave *models.UserLeave) *models.WebhookLeaveEvent {
+	return &models.WebhookLeaveEvent{
+		UserId:           userLeave.UserId,
+		LeaveId:          userLeave.LeaveId,
+		LeaveTypeId:      userLeave.LeaveTypeId,
+		LeaveFrom:        userLeave.LeaveFrom,
+		LeaveTo:          userLeave.LeaveTo,
+		LeaveGranularity: userLeave.LeaveGranularity,
+		LeaveStartTime:   userLeave.LeaveStartTime,
+		LeaveEndTime:     userLeave.LeaveEndTime,
+		LeaveDays:        userLeave.LeaveDays,
+	}
+}
+
+// recomputeLeaveAttendance refreshes the ledger days of the leave, a failure is only logged as
+// the leave itself is already stored and the scheduled run fixes recent days
+func (repo *UserRepo) recomputeLeaveAttendance(userLeave *models.UserLeave) {
+	if err := repo.attendanceLedger.RecomputeUserAttendance(userLeave.UserId, userLeave.LeaveFrom, userLeave.LeaveTo); err != nil {
+		log.Println("error occurred while recomputing the attendance ledger, Error: ", err.Error())
+	}
+}
+
 func (repo *UserRepo) UpdateUserProfileInfo(ctx echo.Context) (int32, error) {
 
 	userProfileInfoUpdateRequest := new(models.UserProfileInfoUpdateRequest)
@@ -815,6 +1246,10 @@ func (repo *UserRepo) UserForgotPassword(ctx echo.Context) (int32, error) {
 		return 400, errors.New("invalid request body format")
 	}
 
+	if statusCode, err := repo.throttle.CheckLoginAttempts(ctx, models.LoginScopeUserOtp, userForgotPasswordRequest.Email); err != nil {
+		return statusCode, err
+	}
+
 	emailsExists, err := repo.dbRepo.CheckUserEmailExists(userForgotPasswordRequest.Email)
 
 	if err != nil {
@@ -840,6 +1275,9 @@ func (repo *UserRepo) UserForgotPassword(ctx echo.Context) (int32, error) {
 		return 500, errors.New("internal server error was occurred")
 	}
 
+	//a new otp gets its own attempts
+	repo.throttle.ClearLoginAttempts(models.LoginScopeUserOtp, userForgotPasswordRequest.Email)
+
 	otpMessage := new(models.UserOtpEmailFormat)
 
 	otpMessage.To = userForgotPasswordRequest.Email
@@ -887,6 +1325,10 @@ func (user *UserRepo) ValidateUserOtp(ctx echo.Context) (string, int32, error) {
 		return "", 400, errors.New("invalid request body format")
 	}
 
+	if statusCode, err := user.throttle.CheckLoginAttempts(ctx, models.LoginScopeUserOtp, otpValidateRequest.Email); err != nil {
+		return "", statusCode, err
+	}
+
 	userEmailExists, err := user.dbRepo.CheckUserEmailExists(otpValidateRequest.Email)
 
 	if err != nil {
@@ -898,6 +1340,12 @@ func (user *UserRepo) ValidateUserOtp(ctx echo.Context) (string, int32, error) {
 		return "", 400, errors.New("email id not exists")
 	}
 
+	attemptsLeft, statusCode, err := user.throttle.ReserveOtpAttempt(models.LoginScopeUserOtp, otpValidateRequest.Email)
+
+	if err != nil {
+		return "", statusCode, err
+	}
+
 	otpExists, err := user.dbRepo.CheckOtpExists(otpValidateRequest.Email, otpValidateRequest.Otp)
 
 	if err != nil {
@@ -906,9 +1354,20 @@ func (user *UserRepo) ValidateUserOtp(ctx echo.Context) (string, int32, error) {
 	}
 
 	if !otpExists {
+		if user.throttle.RecordOtpFailure(ctx, models.LoginScopeUserOtp, otpValidateRequest.Email, attemptsLeft) {
+			if err := user.dbRepo.ClearUserOtps(otpValidateRequest.Email); err != nil {
+				log.Println("error occurred with database, Error: ", err.Error())
+				return "", 500, errors.New("internal server error")
+			}
+
+			return "", 400, errors.New("too many invalid otp attempts, please request a new otp")
+		}
+
 		return "", 400, errors.New("invalid otp")
 	}
 
+	user.throttle.ClearLoginAttempts(models.LoginScopeUserOtp, otpValidateRequest.Email)
+
 	if err := user.dbRepo.ClearOtp(otpValidateRequest.Email, otpValidateRequest.Otp); err != nil {
 		log.Println("error occurred with database, Error: ", err.Error())
 		return "", 500, errors.New("internal server error")
@@ -921,7 +1380,19 @@ func (user *UserRepo) ValidateUserOtp(ctx echo.Context) (string, int32, error) {
 		return "", 500, errors.New("internal server error")
 	}
 
-	token, err := utils.GenerateToken(userId, otpValidateRequest.Email, userName, "", "")
+	adminId, err := user.dbRepo.GetAdminIdByUserId(userId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error")
+	}
+
+	token, err := user.tokens.IssueAccessToken(&models.TokenSubject{
+		AdminId: adminId,
+		UserId:  &userId,
+		Email:   otpValidateRequest.Email,
+		Name:    userName,
+	})
 
 	if err != nil {
 		log.Println("error occurred while generating jwt token, Error: ", err.Error())
@@ -1052,48 +1523,102 @@ func (user *UserRepo) GetAllUsersWorkHistoryByAdminId(adminId string, limit, off
 	return int32(len(workHistory)), workHistory, int32(totalCount), nil
 }
 
-func (user *UserRepo) DownloadUserWorkHistory(ctx echo.Context) (*models.UserReportPdf, int32, error) {
+// DownloadUserWorkHistory renders and issues the pdf report of the user, the report is returned
+// with the pdf bytes
+func (user *UserRepo) DownloadUserWorkHistory(ctx echo.Context) (*models.UserReportPdf, []byte, int32, error) {
 	userRequest := new(models.UserReportPdfDownloadRequest)
 
 	if err := ctx.Bind(userRequest); err != nil {
-		return nil, 400, errors.New("missing or invalid query parameters")
+		return nil, nil, 400, errors.New("missing or invalid query parameters")
 	}
 
 	validate := validator.New()
 
 	if err := validate.RegisterValidation("date", utils.ValidateDate); err != nil {
 		log.Println("error occurred while registering the date validation, Error: ", err.Error())
-		return nil, 500, errors.New("internal server error occurred")
+		return nil, nil, 500, errors.New("internal server error occurred")
 	}
 
 	if err := validate.Struct(userRequest); err != nil {
-		return nil, 400, errors.New("invalid query parameters")
+		return nil, nil, 400, errors.New("invalid query parameters")
 	}
 
 	if err := utils.CompareDates(userRequest.StartDate, userRequest.EndDate); err != nil {
-		return nil, 400, errors.New("end date should be greater than start date")
+		return nil, nil, 400, errors.New("end date should be greater than start date")
 	}
 
-	userName, userCategory, err := user.dbRepo.GetUserInfoForPdf(userRequest.UserId)
+	userReportPdf, file, err := user.RenderUserReport(userRequest.UserId, userRequest.StartDate, userRequest.EndDate)
 
 	if err != nil {
-		log.Println("error occurred with database, Error: ", err.Error())
-		return nil, 500, errors.New("internal server error")
+		log.Println("error occurred while rendering the user report, Error: ", err.Error())
+		return nil, nil, 500, errors.New("internal server error")
 	}
 
-	history, err := user.dbRepo.GetWorkHistoryForPdf(userRequest.UserId, userRequest.StartDate, userRequest.EndDate)
+	return userReportPdf, file, 200, nil
+}
+
+// RenderUserReport builds the report of the user between the two dates and renders it as an
+// issued, verifiable pdf
+func (user *UserRepo) RenderUserReport(userId string, startDate string, endDate string) (*models.UserReportPdf, []byte, error) {
+	userReportPdf, err := user.BuildUserReport(userId, startDate, endDate)
 
 	if err != nil {
-		log.Println("error occurred with database, Error: ", err.Error())
-		return nil, 500, errors.New("internal server error")
+		return nil, nil, err
+	}
+
+	file, err := user.issuedReports.IssueUserReport(userId, startDate, endDate, userReportPdf)
+
+	if err != nil {
+		return nil, nil, err
 	}
 
-	userReportPdf := models.UserReportPdf{
-		Name:     userName,
-		Position: userCategory,
-		History:  history,
+	return userReportPdf, file, nil
+}
+
+// BuildUserReport collects the work history of the user between the two dates, one entry per
+// worked day and holiday, with the letterhead of the user's admin for the pdf report
+func (user *UserRepo) BuildUserReport(userId string, startDate string, endDate string) (*models.UserReportPdf, error) {
+	userName, userCategory, err := user.dbRepo.GetUserInfoForPdf(userId)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return &userReportPdf, 200, nil
+	sessions, err := user.dbRepo.GetWorkHistoryForPdf(userId, startDate, endDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := utils.GroupWorkHistoryByDay(sessions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	workCalendar, err := user.dbRepo.GetUserWorkCalendar(userId, startDate, endDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	history, err = utils.AddCalendarDays(history, workCalendar, startDate, endDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	branding, err := user.reportBranding.LoadUserReportBranding(userId)
+
+	if err != nil {
+		return nil, err
+	}
 
+	return &models.UserReportPdf{
+		Name:        userName,
+		Position:    userCategory,
+		History:     history,
+		Branding:    branding,
+		GeneratedAt: time.Now().In(user.punchClock.Location),
+	}, nil
 }
@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+const (
+	// attempts of a delivery before it is marked failed, the retry delay doubles every attempt
+	webhookMaxAttempts = 10
+	webhookTimeout     = 10 * time.Second
+	// a claimed delivery is tried again after the lease when its worker died
+	webhookLease = time.Minute
+	// bytes of the response body read so the connection can be reused, the body is not kept
+	webhookResponseDrainSize = 1024
+)
+
+type WebhookRepo struct {
+	dbRepo     models.WebhookInterface
+	httpClient *http.Client
+}
+
+func NewWebhookRepo(dbRepo models.WebhookInterface) *WebhookRepo {
+	return &WebhookRepo{
+		dbRepo: dbRepo,
+		httpClient: &http.Client{
+			Timeout: webhookTimeout,
+			//every connection is checked after the name resolved, so a host that later resolves
+			//to an internal address is still refused. a proxy would hide the address dialed.
+			Transport: &http.Transport{
+				Proxy: nil,
+				DialContext: (&net.Dialer{
+					Timeout: webhookTimeout,
+					Control: webhookDialControl,
+				}).DialContext,
+				TLSHandshakeTimeout: webhookTimeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			//a redirect is reported as a failed attempt instead of posting the event elsewhere
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Enqueue queues the event for every webhook of the admin of the user that subscribed to it. a
+// failure is only logged as the change behind the event is already stored.
+func (repo *WebhookRepo) Enqueue(userId string, eventType string, data interface{}) {
+	eventData, err := json.Marshal(data)
+
+	if err != nil {
+		log.Println("error occurred while encoding the webhook event "+eventType+", Error: ", err.Error())
+		return
+	}
+
+	if _, err := repo.dbRepo.EnqueueWebhookDeliveries(userId, eventType, eventData); err != nil {
+		log.Println("error occurred with database while queueing the webhook event "+eventType+", Error: ", err.Error())
+	}
+}
+
+// StartWorkers starts the given number of delivery workers, each sends the next due delivery as
+// soon as it finished one and checks for due deliveries every poll interval while idle
+func (repo *WebhookRepo) StartWorkers(workers int, pollInterval time.Duration) {
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			for {
+				if !repo.ProcessNextWebhookDelivery() {
+					time.Sleep(pollInterval)
+				}
+			}
+		}()
+	}
+}
+
+// ProcessNextWebhookDelivery claims and sends one delivery, false is returned when no delivery
+// was due
+func (repo *WebhookRepo) ProcessNextWebhookDelivery() bool {
+	delivery, err := repo.dbRepo.ClaimWebhookDelivery(webhookLease)
+
+	if err != nil {
+		log.Println("error occurred with database while claiming a webhook delivery, Error: ", err.Error())
+		return false
+	}
+
+	if delivery == nil {
+		return false
+	}
+
+	attempt := repo.sendWebhookDelivery(delivery)
+
+	status := "succeeded"
+	var nextAttemptAt *time.Time
+
+	if attempt.Error != nil {
+		status = "failed"
+
+		if delivery.Attempts < webhookMaxAttempts {
+			status = "pending"
+			retryAt := time.Now().Add(utils.WebhookRetryDelay(delivery.Attempts))
+			nextAttemptAt = &retryAt
+		}
+	}
+
+	if err := repo.dbRepo.RecordWebhookAttempt(delivery.DeliveryId, attempt, status, nextAttemptAt); err != nil {
+		log.Println("error occurred with database while recording the webhook delivery "+delivery.DeliveryId+", Error: ", err.Error())
+	}
+
+	return true
+}
+
+// sendWebhookDelivery posts the payload to the webhook url, any response other than 2xx counts
+// as a failed attempt
+func (repo *WebhookRepo) sendWebhookDelivery(delivery *models.WebhookDelivery) *models.WebhookDeliveryAttempt {
+	attempt := new(models.WebhookDeliveryAttempt)
+	startedAt := time.Now()
+
+	defer func() {
+		attempt.DurationMs = int32(time.Since(startedAt).Milliseconds())
+	}()
+
+	fail := func(message string) *models.WebhookDeliveryAttempt {
+		attempt.Error = &message
+		return attempt
+	}
+
+	request, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	//webhooks registered before https was required are not posted to
+	if request.URL.Scheme != "https" {
+		return fail("webhook url must use https")
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "ca-project-webhooks/1.0")
+	request.Header.Set("X-Webhook-Id", delivery.DeliveryId)
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", utils.SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := repo.httpClient.Do(request)
+
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	defer response.Body.Close()
+
+	statusCode := int32(response.StatusCode)
+	attempt.StatusCode = &statusCode
+
+	io.Copy(io.Discard, io.LimitReader(response.Body, webhookResponseDrainSize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fail("endpoint responded with status " + strconv.Itoa(response.StatusCode))
+	}
+
+	return attempt
+}
+
+// webhookDialControl refuses connections to loopback, private, link-local and other internal
+// addresses, the webhook urls are chosen by the admins and must only reach public endpoints
+func webhookDialControl(network string, address string, conn syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsPublicAddress(addrPort.Addr()) {
+		return errors.New("webhook address " + addrPort.Addr().String() + " is not a public address")
+	}
+
+	return nil
+}
+
+// checkWebhookUrl refuses urls that are not https or name an internal address directly, names
+// resolving to an internal address are refused when the delivery connects
+func checkWebhookUrl(webhookUrl string) (int32, error) {
+	parsedUrl, err := url.Parse(webhookUrl)
+
+	if err != nil || parsedUrl.Hostname() == "" {
+		return 400, errors.New("request body validation error")
+	}
+
+	if parsedUrl.Scheme != "https" {
+		return 400, errors.New("webhook url must use https")
+	}
+
+	if addr, err := netip.ParseAddr(parsedUrl.Hostname()); err == nil && !utils.IsPublicAddress(addr) {
+		return 400, errors.New("webhook url must point to a public address")
+	}
+
+	if parsedUrl.Hostname() == "localhost" || strings.HasSuffix(parsedUrl.Hostname(), ".localhost") {
+		return 400, errors.New("webhook url must point to a public address")
+	}
+
+	return 200, nil
+}
+
+func (repo *WebhookRepo) CreateWebhook(ctx echo.Context) (string, int32, error) {
+	createWebhookRequest := new(models.CreateWebhookRequest)
+
+	if err := ctx.Bind(createWebhookRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(createWebhookRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	if statusCode, err := checkWebhookUrl(createWebhookRequest.Url); err != nil {
+		return "", statusCode, err
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(createWebhookRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return "", 400, errors.New("admin id not exists")
+	}
+
+	webhook := &models.Webhook{
+		WebhookId:  uuid.NewString(),
+		AdminId:    createWebhookRequest.AdminId,
+		Url:        createWebhookRequest.Url,
+		Secret:     createWebhookRequest.Secret,
+		EventTypes: createWebhookRequest.EventTypes,
+	}
+
+	if err := repo.dbRepo.CreateWebhook(webhook); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	return webhook.WebhookId, 201, nil
+}
+
+func (repo *WebhookRepo) GetWebhooks(ctx echo.Context) ([]*models.WebhookResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	webhooks, err := repo.dbRepo.GetWebhooks(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if webhooks == nil {
+		return nil, 404, errors.New("webhooks was empty")
+	}
+
+	return webhooks, 200, nil
+}
+
+func (repo *WebhookRepo) DeleteWebhook(ctx echo.Context) (int32, error) {
+	webhookId := ctx.Param("webhookId")
+
+	webhookIdExists, err := repo.dbRepo.CheckWebhookIdExists(webhookId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !webhookIdExists {
+		return 400, errors.New("webhook id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteWebhook(webhookId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *WebhookRepo) GetWebhookDeliveries(ctx echo.Context) (int32, []*models.WebhookDeliveryResponse, int32, error) {
+	webhookId := ctx.Param("webhookId")
+	status := ctx.QueryParam("status")
+	page := ctx.QueryParam("page")
+	limit := ctx.QueryParam("limit")
+
+	if status != "" && status != "pending" && status != "succeeded" && status != "failed" {
+		return 0, nil, 400, errors.New("status parameter must be pending, succeeded or failed")
+	}
+
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	pageInt, err := strconv.Atoi(page)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("page paramater must be valid number")
+	}
+
+	if pageInt <= 0 {
+		pageInt = 1 //default page
+	}
+
+	limitInt, err := strconv.Atoi(limit)
+
+	if err != nil {
+		return 0, nil, 400, errors.New("limit parameter must be valid number")
+	}
+
+	if limitInt <= 0 {
+		limitInt = 10 //default limit
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	deliveriesCount, err := repo.dbRepo.GetWebhookDeliveriesCount(webhookId, status)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	deliveries, err := repo.dbRepo.GetWebhookDeliveries(webhookId, status, uint32(limitInt), uint32(offset))
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 0, nil, 500, errors.New("internal server error occurred")
+	}
+
+	if deliveries == nil {
+		return 0, nil, 404, errors.New("webhook deliveries was empty")
+	}
+
+	return deliveriesCount, deliveries, 200, nil
+}
+
+// GetWebhookDelivery returns the delivery with its payload and the log of every attempt
+func (repo *WebhookRepo) GetWebhookDelivery(ctx echo.Context) (*models.WebhookDeliveryResponse, int32, error) {
+	deliveryId := ctx.Param("deliveryId")
+
+	delivery, err := repo.dbRepo.GetWebhookDelivery(deliveryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if delivery == nil {
+		return nil, 404, errors.New("webhook delivery not exists")
+	}
+
+	delivery.AttemptLog, err = repo.dbRepo.GetWebhookDeliveryAttempts(deliveryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	return delivery, 200, nil
+}
+
+// RedeliverWebhookDelivery sends a succeeded or failed delivery again with the same payload and
+// delivery id
+func (repo *WebhookRepo) RedeliverWebhookDelivery(ctx echo.Context) (int32, error) {
+	deliveryId := ctx.Param("deliveryId")
+
+	delivery, err := repo.dbRepo.GetWebhookDelivery(deliveryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if delivery == nil {
+		return 404, errors.New("webhook delivery not exists")
+	}
+
+	queued, err := repo.dbRepo.RedeliverWebhookDelivery(deliveryId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !queued {
+		return 409, errors.New("webhook delivery is already waiting to be sent")
+	}
+
+	return 202, nil
+}
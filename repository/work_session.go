@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+	"github.com/vithsutra/ca_project_http_server/pkg/utils"
+)
+
+const workSessionSweepBatchSize = 500
+
+type WorkSessionRepo struct {
+	dbRepo           models.WorkSessionInterface
+	emailServiceRepo models.UserEmailServiceInterface
+	location         *time.Location
+}
+
+func NewWorkSessionRepo(
+	dbRepo models.WorkSessionInterface,
+	emailServiceRepo models.UserEmailServiceInterface,
+	location *time.Location,
+) *WorkSessionRepo {
+	return &WorkSessionRepo{
+		dbRepo,
+		emailServiceRepo,
+		location,
+	}
+}
+
+// StartSweeper closes forgotten work sessions every interval until the process exits
+func (repo *WorkSessionRepo) StartSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		repo.SweepOpenWorkSessions()
+		<-ticker.C
+	}
+}
+
+func (repo *WorkSessionRepo) SweepOpenWorkSessions() {
+	for {
+		sessions, err := repo.dbRepo.GetOverdueOpenWorkSessions(workSessionSweepBatchSize)
+
+		if err != nil {
+			log.Println("error occurred with database while sweeping work sessions, Error: ", err.Error())
+			return
+		}
+
+		for _, session := range sessions {
+			autoClose := repo.resolveAutoClose(session)
+
+			closed, err := repo.dbRepo.AutoCloseWorkSession(autoClose)
+
+			if err != nil {
+				log.Println("error occurred with database while auto closing work session, Error: ", err.Error())
+				return
+			}
+
+			//closed by the user or by another replica in the meantime
+			if !closed {
+				continue
+			}
+
+			repo.sendAutoClosedEmails(session, autoClose)
+		}
+
+		if len(sessions) < workSessionSweepBatchSize {
+			return
+		}
+	}
+}
+
+// resolveAutoClose picks the logout time for the session's policy, when the policy cannot
+// produce a time after the login the session is closed at the login time for admin review
+func (repo *WorkSessionRepo) resolveAutoClose(session *models.OpenWorkSession) *models.WorkSessionAutoClose {
+	autoClose := &models.WorkSessionAutoClose{
+		SessionId:   session.SessionId,
+		UserId:      session.UserId,
+		LogoutAt:    session.LoginAt,
+		Policy:      session.AutoClosePolicy,
+		NeedsReview: true,
+	}
+
+	switch session.AutoClosePolicy {
+	case "end_of_shift":
+		loginAt := session.LoginAt.In(repo.location)
+
+		shiftEndAt, err := time.ParseInLocation("2006-01-02 15:04", loginAt.Format("2006-01-02")+" "+session.ShiftEndTime, repo.location)
+
+		if err != nil {
+			break
+		}
+
+		//a shift ending before it starts crosses midnight and ends on the next day
+		if !shiftEndAt.After(loginAt) && session.ShiftEndTime < session.ShiftStartTime {
+			shiftEndAt = shiftEndAt.AddDate(0, 0, 1)
+		}
+
+		if shiftEndAt.After(loginAt) {
+			autoClose.LogoutAt = shiftEndAt
+			autoClose.NeedsReview = false
+		}
+	case "last_activity":
+		if session.LastActivityAt != nil && session.LastActivityAt.After(session.LoginAt) {
+			autoClose.LogoutAt = *session.LastActivityAt
+			autoClose.NeedsReview = false
+		}
+	}
+
+	return autoClose
+}
+
+func (repo *WorkSessionRepo) sendAutoClosedEmails(session *models.OpenWorkSession, autoClose *models.WorkSessionAutoClose) {
+	data := map[string]string{
+		"user_name":    session.UserName,
+		"work_date":    session.WorkDate,
+		"login_time":   session.LoginAt.In(repo.location).Format("15:04"),
+		"logout_time":  autoClose.LogoutAt.In(repo.location).Format("2006-01-02 15:04"),
+		"policy":       autoClose.Policy,
+		"needs_review": strconv.FormatBool(autoClose.NeedsReview),
+	}
+
+	emails := []*models.WorkSessionAutoClosedEmailFormat{
+		{
+			To:        session.UserEmail,
+			Subject:   "Your work session was closed automatically",
+			EmailType: "work_session_auto_closed",
+			Data:      data,
+		},
+		{
+			To:        session.AdminEmail,
+			Subject:   "Work session of " + session.UserName + " was closed automatically",
+			EmailType: "work_session_auto_closed_admin",
+			Data:      data,
+		},
+	}
+
+	for _, email := range emails {
+		jsonBytes, err := json.Marshal(email)
+
+		if err != nil {
+			log.Println("error occurred while encoding auto closed email, Error: ", err.Error())
+			continue
+		}
+
+		if err := repo.emailServiceRepo.SendEmail(jsonBytes); err != nil {
+			log.Println("error occurred while sending auto closed email, Error: ", err.Error())
+		}
+	}
+}
+
+func (repo *WorkSessionRepo) UserWorkHeartbeat(ctx echo.Context) (int32, error) {
+	heartbeatRequest := new(models.UserWorkHeartbeatRequest)
+
+	if err := ctx.Bind(heartbeatRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(heartbeatRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	updated, err := repo.dbRepo.UpdateUserWorkActivity(heartbeatRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !updated {
+		return 400, errors.New("no open work session")
+	}
+
+	return 200, nil
+}
+
+func (repo *WorkSessionRepo) GetWorkSessionsForReview(ctx echo.Context) ([]*models.WorkSessionForReviewResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return nil, 400, errors.New("admin id not exists")
+	}
+
+	sessions, err := repo.dbRepo.GetWorkSessionsForReview(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if sessions == nil {
+		return nil, 404, errors.New("work sessions for review was empty")
+	}
+
+	return sessions, 200, nil
+}
+
+func (repo *WorkSessionRepo) ResolveWorkSessionReview(ctx echo.Context) (int32, error) {
+	sessionId := ctx.Param("sessionId")
+
+	resolveRequest := new(models.WorkSessionReviewResolveRequest)
+
+	if err := ctx.Bind(resolveRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.RegisterValidation("date", utils.ValidateDate); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return 500, errors.New("internal server error")
+	}
+
+	if err := validation.RegisterValidation("time", utils.ValidateTime); err != nil {
+		log.Println("Error occurred while registering validation, Error: ", err.Error())
+		return 500, errors.New("internal server error")
+	}
+
+	if err := validation.Struct(resolveRequest); err != nil {
+		return 400, errors.New("request body validation error")
+	}
+
+	logoutAt, err := time.ParseInLocation("2006-01-02 15:04", resolveRequest.LogoutDate+" "+resolveRequest.LogoutTime, repo.location)
+
+	if err != nil {
+		return 400, errors.New("invalid logout date or time")
+	}
+
+	loginAt, err := repo.dbRepo.GetWorkSessionForReviewLoginAt(sessionId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if loginAt == nil {
+		return 400, errors.New("work session not exists or not waiting for review")
+	}
+
+	if !logoutAt.After(*loginAt) {
+		return 400, errors.New("logout time must be after the login time")
+	}
+
+	sessionResolved, err := repo.dbRepo.ResolveWorkSessionReview(sessionId, logoutAt, resolveRequest.Work)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !sessionResolved {
+		return 409, errors.New("logout time overlaps with another work session of the user")
+	}
+
+	return 200, nil
+}
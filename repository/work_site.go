@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vithsutra/ca_project_http_server/internals/models"
+)
+
+type WorkSiteRepo struct {
+	dbRepo models.WorkSiteInterface
+}
+
+func NewWorkSiteRepo(dbRepo models.WorkSiteInterface) *WorkSiteRepo {
+	return &WorkSiteRepo{
+		dbRepo: dbRepo,
+	}
+}
+
+func (repo *WorkSiteRepo) CreateWorkSite(ctx echo.Context) (string, int32, error) {
+	createWorkSiteRequest := new(models.CreateWorkSiteRequest)
+
+	if err := ctx.Bind(createWorkSiteRequest); err != nil {
+		return "", 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(createWorkSiteRequest); err != nil {
+		return "", 400, errors.New("request body validation error")
+	}
+
+	switch createWorkSiteRequest.SiteType {
+	case "circle":
+		if createWorkSiteRequest.CenterLatitude == nil || createWorkSiteRequest.CenterLongitude == nil || createWorkSiteRequest.RadiusMeters == nil {
+			return "", 400, errors.New("circle work site requires center_latitude, center_longitude and radius_meters")
+		}
+		createWorkSiteRequest.Polygon = nil
+	case "polygon":
+		if len(createWorkSiteRequest.Polygon) < 3 {
+			return "", 400, errors.New("polygon work site requires at least 3 points")
+		}
+		createWorkSiteRequest.CenterLatitude = nil
+		createWorkSiteRequest.CenterLongitude = nil
+		createWorkSiteRequest.RadiusMeters = nil
+	}
+
+	if createWorkSiteRequest.Enforcement == "" {
+		createWorkSiteRequest.Enforcement = "flag"
+	}
+
+	adminIdExists, err := repo.dbRepo.CheckAdminIdExists(createWorkSiteRequest.AdminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if !adminIdExists {
+		return "", 400, errors.New("admin id not exists")
+	}
+
+	siteName := strings.TrimSpace(createWorkSiteRequest.SiteName)
+
+	workSiteExists, err := repo.dbRepo.CheckWorkSiteExists(createWorkSiteRequest.AdminId, siteName)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	if workSiteExists {
+		return "", 400, errors.New("work site already exists")
+	}
+
+	workSite := &models.WorkSite{
+		SiteId:          uuid.NewString(),
+		AdminId:         createWorkSiteRequest.AdminId,
+		SiteName:        siteName,
+		SiteType:        createWorkSiteRequest.SiteType,
+		CenterLatitude:  createWorkSiteRequest.CenterLatitude,
+		CenterLongitude: createWorkSiteRequest.CenterLongitude,
+		RadiusMeters:    createWorkSiteRequest.RadiusMeters,
+		Polygon:         createWorkSiteRequest.Polygon,
+		Enforcement:     createWorkSiteRequest.Enforcement,
+	}
+
+	if err := repo.dbRepo.CreateWorkSite(workSite); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return "", 500, errors.New("internal server error occurred")
+	}
+
+	return workSite.SiteId, 201, nil
+}
+
+func (repo *WorkSiteRepo) GetWorkSites(ctx echo.Context) ([]*models.WorkSiteResponse, int32, error) {
+	adminId := ctx.Param("adminId")
+
+	workSites, err := repo.dbRepo.GetWorkSites(adminId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if workSites == nil {
+		return nil, 404, errors.New("work sites was empty")
+	}
+
+	return workSites, 200, nil
+}
+
+func (repo *WorkSiteRepo) DeleteWorkSite(ctx echo.Context) (int32, error) {
+	siteId := ctx.Param("siteId")
+
+	siteIdExists, err := repo.dbRepo.CheckWorkSiteIdExists(siteId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !siteIdExists {
+		return 400, errors.New("work site id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteWorkSite(siteId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}
+
+func (repo *WorkSiteRepo) AssignWorkSite(ctx echo.Context) (int32, error) {
+	assignRequest := new(models.WorkSiteAssignRequest)
+
+	if err := ctx.Bind(assignRequest); err != nil {
+		return 400, errors.New("invalid json request body")
+	}
+
+	validation := validator.New()
+
+	if err := validation.Struct(assignRequest); err != nil {
+		return 400, errors.New("request body validation error, either category_id or user_id is required")
+	}
+
+	siteIdExists, err := repo.dbRepo.CheckWorkSiteIdExists(assignRequest.SiteId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !siteIdExists {
+		return 400, errors.New("work site id not exists")
+	}
+
+	assignment := &models.WorkSiteAssignment{
+		AssignmentId: uuid.NewString(),
+		SiteId:       assignRequest.SiteId,
+	}
+
+	if assignRequest.CategoryId != "" {
+		categoryIdExists, err := repo.dbRepo.CheckEmployeeCategoryIdExists(assignRequest.CategoryId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if !categoryIdExists {
+			return 400, errors.New("employee category id not exists")
+		}
+
+		assignment.CategoryId = &assignRequest.CategoryId
+	} else {
+		userIdExists, err := repo.dbRepo.CheckUserIdExists(assignRequest.UserId)
+
+		if err != nil {
+			log.Println("error occurred with database, Error: ", err.Error())
+			return 500, errors.New("internal server error occurred")
+		}
+
+		if !userIdExists {
+			return 400, errors.New("user id not exists")
+		}
+
+		assignment.UserId = &assignRequest.UserId
+	}
+
+	assignmentExists, err := repo.dbRepo.CheckWorkSiteAssignmentExists(assignRequest.SiteId, assignRequest.CategoryId, assignRequest.UserId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if assignmentExists {
+		return 400, errors.New("work site already assigned")
+	}
+
+	if err := repo.dbRepo.CreateWorkSiteAssignment(assignment); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 201, nil
+}
+
+func (repo *WorkSiteRepo) GetWorkSiteAssignments(ctx echo.Context) ([]*models.WorkSiteAssignmentResponse, int32, error) {
+	siteId := ctx.Param("siteId")
+
+	siteIdExists, err := repo.dbRepo.CheckWorkSiteIdExists(siteId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if !siteIdExists {
+		return nil, 400, errors.New("work site id not exists")
+	}
+
+	assignments, err := repo.dbRepo.GetWorkSiteAssignments(siteId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return nil, 500, errors.New("internal server error occurred")
+	}
+
+	if assignments == nil {
+		return nil, 404, errors.New("work site assignments was empty")
+	}
+
+	return assignments, 200, nil
+}
+
+func (repo *WorkSiteRepo) DeleteWorkSiteAssignment(ctx echo.Context) (int32, error) {
+	assignmentId := ctx.Param("assignmentId")
+
+	assignmentIdExists, err := repo.dbRepo.CheckWorkSiteAssignmentIdExists(assignmentId)
+
+	if err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	if !assignmentIdExists {
+		return 400, errors.New("work site assignment id not exists")
+	}
+
+	if err := repo.dbRepo.DeleteWorkSiteAssignment(assignmentId); err != nil {
+		log.Println("error occurred with database, Error: ", err.Error())
+		return 500, errors.New("internal server error occurred")
+	}
+
+	return 200, nil
+}